@@ -59,6 +59,23 @@ func ParseString(input string) []token.Token {
 	return l.tokens
 }
 
+// Lex lexes the input string into a slice of tokens, terminated by an EOF
+// token. Comments are skipped. The first lexical error of the input, if any, is
+// returned together with its position.
+func Lex(src string) ([]token.Token, error) {
+	var tokens []token.Token
+	for _, tok := range ParseString(src) {
+		switch tok.Kind {
+		case token.Comment:
+			continue
+		case token.Error:
+			return nil, fmt.Errorf("lexical error at offset %d; %s", tok.Pos, tok.Val)
+		}
+		tokens = append(tokens, tok)
+	}
+	return tokens, nil
+}
+
 // A lexer lexes an input string into a slice of tokens.
 type lexer struct {
 	// The input string.
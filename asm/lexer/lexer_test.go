@@ -258,6 +258,67 @@ func TestParseString(t *testing.T) {
 	}
 }
 
+func TestLex(t *testing.T) {
+	golden := []struct {
+		input string
+		want  []token.Token
+		err   string
+	}{
+		// i=0
+		{
+			input: "%1 = add i32 %a, 3 ; 3 + a",
+			want: []token.Token{
+				{Kind: token.LocalID, Val: "1", Pos: 0},
+				{Kind: token.Equal, Val: "=", Pos: 3},
+				{Kind: token.KwAdd, Val: "add", Pos: 5},
+				{Kind: token.Type, Val: "i32", Pos: 9},
+				{Kind: token.LocalVar, Val: "a", Pos: 13},
+				{Kind: token.Comma, Val: ",", Pos: 15},
+				{Kind: token.Int, Val: "3", Pos: 17},
+				{Kind: token.EOF, Pos: 26},
+			},
+		},
+		// i=1
+		{
+			input: "%x = [2 x i8*] {}",
+			want: []token.Token{
+				{Kind: token.LocalVar, Val: "x", Pos: 0},
+				{Kind: token.Equal, Val: "=", Pos: 3},
+				{Kind: token.Lbrack, Val: "[", Pos: 5},
+				{Kind: token.Int, Val: "2", Pos: 6},
+				{Kind: token.KwX, Val: "x", Pos: 8},
+				{Kind: token.Type, Val: "i8", Pos: 10},
+				{Kind: token.Star, Val: "*", Pos: 12},
+				{Kind: token.Rbrack, Val: "]", Pos: 13},
+				{Kind: token.Lbrace, Val: "{", Pos: 15},
+				{Kind: token.Rbrace, Val: "}", Pos: 16},
+				{Kind: token.EOF, Pos: 17},
+			},
+		},
+		// i=2
+		{
+			input: `@"foo`,
+			err:   "lexical error at offset 5; unexpected eof in quoted string",
+		},
+	}
+	for i, g := range golden {
+		got, err := Lex(g.input)
+		if err != nil {
+			if err.Error() != g.err {
+				t.Errorf("i=%d: error mismatch; expected %q, got %q", i, g.err, err)
+			}
+			continue
+		}
+		if len(g.err) > 0 {
+			t.Errorf("i=%d: expected error %q, got nil", i, g.err)
+			continue
+		}
+		if !reflect.DeepEqual(got, g.want) {
+			t.Errorf("i=%d: expected %#v, got %#v", i, g.want, got)
+		}
+	}
+}
+
 func BenchmarkParseString(b *testing.B) {
 	buf, err := ioutil.ReadFile("../testdata/for.ll")
 	if err != nil {
@@ -6,24 +6,39 @@ import (
 	"github.com/llir/llvm/asm/token"
 )
 
-// filter filters out token types which are not yet handled by the parser.
-func filter(tokens []token.Token) []token.Token {
-	subset := make([]token.Token, 0, len(tokens))
-	for _, token := range tokens {
-		if valid[token.Kind] {
-			subset = append(subset, token)
+// filter filters out token types which are not yet handled by the parser. The
+// comments of the input are filtered out as well, but recorded in a map from
+// the index of the preceding token in the subset to the first comment following
+// it.
+func filter(tokens []token.Token) (subset []token.Token, comments map[int]token.Token) {
+	subset = make([]token.Token, 0, len(tokens))
+	comments = make(map[int]token.Token)
+	for _, tok := range tokens {
+		if tok.Kind == token.Comment {
+			// Comments carry no semantic meaning, but are recorded for the side
+			// table of source comments.
+			if i := len(subset) - 1; i >= 0 {
+				if _, ok := comments[i]; !ok {
+					comments[i] = tok
+				}
+			}
+			continue
+		}
+		if valid[tok.Kind] {
+			subset = append(subset, tok)
 		} else {
-			log.Printf("filter: token type %v not yet handled by the parser.\n", token.Kind)
+			log.Printf("filter: token type %v not yet handled by the parser.\n", tok.Kind)
 		}
 	}
-	return subset
+	return subset, comments
 }
 
 // valid specifies the subset of tokens which the parser is currently able to
 // handle.
 var valid = map[token.Kind]bool{
 	// Special tokens.
-	token.EOF: true,
+	token.EOF:   true,
+	token.Error: true,
 
 	// Identifiers.
 	token.Type:        true, // i8, float, label
@@ -51,9 +66,37 @@ var valid = map[token.Kind]bool{
 	token.Greater:  true, // >
 	token.Exclaim:  true, // !
 
+	// Constants.
+	token.Int:    true, // 12345
+	token.Float:  true, // 123.45
+	token.String: true, // "foo"
+
 	// Types.
 	token.KwX: true, // x
 
+	// Constant values.
+	token.KwNull:  true, // null
+	token.KwTrue:  true, // true
+	token.KwFalse: true, // false
+	token.KwUndef: true, // undef
+	token.KwC:     true, // c"foo"
+
+	// Global variables.
+	token.KwGlobal:   true, // global
+	token.KwConstant: true, // constant
+	token.KwExternal: true, // external
+
+	// Instruction flags.
+	token.KwAlign: true, // align
+	token.KwNuw:   true, // nuw
+	token.KwNsw:   true, // nsw
+	token.KwExect: true, // exact
+	token.KwNnan:  true, // nnan
+	token.KwNinf:  true, // ninf
+	token.KwNsz:   true, // nsz
+	token.KwArcp:  true, // arcp
+	token.KwFast:  true, // fast
+
 	// Top-level entities.
 	token.KwDeclare: true, // declare
 	token.KwDefine:  true, // define
@@ -2,52 +2,67 @@
 package parser
 
 import (
+	"fmt"
 	"io"
-	"log"
+	"io/ioutil"
+	"strings"
 
 	"github.com/llir/llvm/asm/lexer"
 	"github.com/llir/llvm/asm/token"
 	"github.com/llir/llvm/ir"
 	"github.com/llir/llvm/types"
+	"github.com/llir/llvm/values"
 )
 
 // Parse parses the input read from r into an in-memory representation of LLVM
 // IR.
 func Parse(r io.Reader) (*ir.Module, error) {
-	input, err := lexer.Parse(r)
+	buf, err := ioutil.ReadAll(r)
 	if err != nil {
 		return nil, err
 	}
-	return ParseTokens(input)
+	return ParseString(string(buf))
 }
 
 // ParseFile parses the input read from path into an in-memory representation of
 // LLVM IR.
 func ParseFile(path string) (*ir.Module, error) {
-	input, err := lexer.ParseFile(path)
+	buf, err := ioutil.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
-	return ParseTokens(input)
+	return ParseString(string(buf))
 }
 
 // ParseString parses the input string into an in-memory representation of LLVM
-// IR.
+// IR. Syntax errors are reported as *SyntaxError values, with line and column
+// positions of the input.
 func ParseString(input string) (*ir.Module, error) {
 	tokens := lexer.ParseString(input)
-	return ParseTokens(tokens)
+	return parse(input, tokens)
 }
 
 // ParseTokens parses the tokenized input into an in-memory representation of
-// LLVM IR.
+// LLVM IR. Syntax errors are reported as *SyntaxError values; as the source is
+// unknown, only the byte offset of the offending token is recorded.
 func ParseTokens(input []token.Token) (*ir.Module, error) {
+	return parse("", input)
+}
+
+// parse parses the tokenized input of the source string src into an in-memory
+// representation of LLVM IR. The source is used to translate token positions
+// into line and column positions, and may be empty if unknown.
+func parse(src string, input []token.Token) (*ir.Module, error) {
 	p := &parser{
-		// filter input to a supported subset of the LLVM IR tokens.
-		input: filter(input),
+		src:     src,
+		globals: make(map[string]values.Value),
 	}
+	// filter input to a supported subset of the LLVM IR tokens.
+	p.input, p.comments = filter(input)
 
 	// Parse the tokenized input by repeatedly parsing top-level entities.
 	module := new(ir.Module)
+	p.module = module
 	for {
 		err := p.parseTopLevelEntity(module)
 		if err != nil {
@@ -55,20 +70,77 @@ func ParseTokens(input []token.Token) (*ir.Module, error) {
 				// Terminate the parser at EOF.
 				return module, nil
 			}
-			// TODO: Remove debug output.
-			log.Printf("error at pos=%d (%q)\n", p.cur, p.input[p.cur:])
-			return module, err
+			return module, p.errorf(err)
 		}
 	}
 }
 
+// A SyntaxError reports a syntax error of the LLVM IR assembly input.
+type SyntaxError struct {
+	// Byte offset of the offending token in the input.
+	Pos int
+	// Line and column number (starting at 1) of the offending token; or 0 if
+	// unknown.
+	Line, Col int
+	// Error message.
+	Msg string
+}
+
+// Error returns the error message of the syntax error, preceded by its
+// position, e.g.
+//
+//    3:14: expected type
+func (e *SyntaxError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("offset %d: %s", e.Pos, e.Msg)
+	}
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Col, e.Msg)
+}
+
+// errorf returns a syntax error based on err, positioned at the current token.
+func (p *parser) errorf(err error) *SyntaxError {
+	e := &SyntaxError{Msg: err.Error()}
+	if len(p.input) == 0 {
+		return e
+	}
+	cur := p.cur
+	if cur >= len(p.input) {
+		cur = len(p.input) - 1
+	}
+	e.Pos = p.input[cur].Pos
+	if len(p.src) > 0 && e.Pos <= len(p.src) {
+		before := p.src[:e.Pos]
+		e.Line = strings.Count(before, "\n") + 1
+		e.Col = e.Pos - strings.LastIndex(before, "\n")
+	}
+	return e
+}
+
 // A parser parses the tokenized input into an in-memory representation of LLVM
 // IR.
 type parser struct {
+	// Source of the tokenized input; or empty if unknown.
+	src string
 	// Tokenized input.
 	input []token.Token
 	// Current position in the input.
 	cur int
+	// Comments of the input, mapping from the index of the preceding token in
+	// the input to the first comment following it.
+	comments map[int]token.Token
+	// Module being parsed.
+	module *ir.Module
+	// Global variables and functions of the module, mapping from global name to
+	// value.
+	globals map[string]values.Value
+	// Function parameters of the current function, mapping from local name to
+	// value.
+	locals map[string]values.Value
+	// Basic blocks of the current function, mapping from label name to basic
+	// block.
+	blocks map[string]*ir.BasicBlock
+	// Next unused numeric name of the unnamed entities of the current function.
+	nextID int
 }
 
 // next consumes and returns the next token of the input.
@@ -81,6 +153,26 @@ func (p *parser) next() token.Token {
 	return tok
 }
 
+// comment records the comment (if any) trailing the last consumed token on the
+// same line in the side table of source comments, as the comment of the given
+// instruction or terminator. If the source is unknown, the first comment
+// following the last consumed token is recorded.
+func (p *parser) comment(inst interface{}) {
+	last := p.cur - 1
+	c, ok := p.comments[last]
+	if !ok {
+		return
+	}
+	if len(p.src) > 0 && strings.Contains(p.src[p.input[last].Pos:c.Pos], "\n") {
+		// Comment on a line of its own.
+		return
+	}
+	if p.module.Comments == nil {
+		p.module.Comments = make(map[interface{}]string)
+	}
+	p.module.Comments[inst] = strings.TrimSpace(c.Val)
+}
+
 // backup backs up one token in the input. It can only be called once per call
 // to next.
 func (p *parser) backup() {
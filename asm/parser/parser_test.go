@@ -0,0 +1,140 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/asm/parser"
+)
+
+func TestParseStringRoundTrip(t *testing.T) {
+	golden := []struct {
+		input string
+	}{
+		// i=0
+		{
+			input: `@x = global i32 42
+@y = external global i32
+@s = constant [3 x i8] c"foo"
+
+declare i32 @printf(i8*, ...)
+`,
+		},
+		// i=1
+		{
+			input: `@x = global i32 42
+
+define i32 @f(i32 %a, i32 %b) {
+entry:
+  %p = alloca i32, align 4
+  store i32 %a, i32* %p, align 4
+  %0 = load i32, i32* %p, align 4
+  %1 = add nsw i32 %0, %b
+  %2 = mul i32 %1, 2
+  %3 = sdiv exact i32 %2, 2
+  %4 = xor i32 %3, -1
+  %5 = load i32, i32* @x
+  %6 = sub nuw nsw i32 %4, %5 ; unused
+  br label %exit ; to exit
+
+exit:                                             ; preds = %entry
+  ret i32 %4
+}
+`,
+		},
+		// i=2
+		{
+			input: `define double @g(double %x, i1 %c) {
+entry:
+  %0 = fadd fast double %x, 1.5
+  %1 = fmul nnan ninf double %0, %x ; x*(x+1.5)
+  br i1 %c, label %a, label %b
+
+a:                                                ; preds = %entry, %a
+  switch i32 7, label %b [ i32 1, label %a i32 2, label %exit ]
+
+b:                                                ; preds = %entry, %a
+  unreachable
+
+exit:                                             ; preds = %a
+  ret double %1
+}
+
+define void @h() {
+0:
+  ret void
+}
+`,
+		},
+	}
+
+	for i, g := range golden {
+		module, err := parser.ParseString(g.input)
+		if err != nil {
+			t.Errorf("i=%d: unexpected error; %v", i, err)
+			continue
+		}
+		if got := module.String(); got != g.input {
+			t.Errorf("i=%d: module mismatch; expected %q, got %q", i, g.input, got)
+		}
+	}
+}
+
+func TestParseStringComments(t *testing.T) {
+	const input = `; ModuleID = 'f.c'
+define i32 @f(i32 %a) {
+entry:
+  ; on a line of its own
+  %x = add i32 %a, 1 ; x = a + 1
+  ret i32 %x
+}
+`
+	module, err := parser.ParseString(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := module.Funcs[0].Blocks[0]
+	if got, want := module.Comments[entry.Insts[0]], "x = a + 1"; got != want {
+		t.Errorf("comment mismatch; expected %q, got %q", want, got)
+	}
+	if got, ok := module.Comments[entry.Term]; ok {
+		t.Errorf("unexpected comment of terminator; got %q", got)
+	}
+	if got := len(module.Comments); got != 1 {
+		t.Errorf("number of comments mismatch; expected 1, got %d", got)
+	}
+}
+
+func TestParseStringError(t *testing.T) {
+	golden := []struct {
+		input     string
+		line, col int
+	}{
+		// i=0
+		{
+			input: "@x = global foo 42\n",
+			line:  1, col: 13,
+		},
+		// i=1
+		{
+			input: "define i32 @f(i32 %a) {\nentry:\n  %x = add i32 %a,\n  ret i32 %x\n}\n",
+			line:  4, col: 3,
+		},
+		// i=2
+		{
+			input: "define void @f() {\nentry:\n  br label %foo\n}\n",
+			line:  4, col: 1,
+		},
+	}
+
+	for i, g := range golden {
+		_, err := parser.ParseString(g.input)
+		e, ok := err.(*parser.SyntaxError)
+		if !ok {
+			t.Errorf("i=%d: error type mismatch; expected *parser.SyntaxError, got %T", i, err)
+			continue
+		}
+		if e.Line != g.line || e.Col != g.col {
+			t.Errorf("i=%d: position mismatch; expected %d:%d, got %d:%d (%v)", i, g.line, g.col, e.Line, e.Col, e)
+		}
+	}
+}
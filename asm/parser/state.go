@@ -7,12 +7,13 @@ package parser
 // Local = LocalID | LocalVar .
 
 import (
-	"errors"
 	"io"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/llir/llvm/asm/token"
+	"github.com/llir/llvm/consts"
 	"github.com/llir/llvm/ir"
 	"github.com/llir/llvm/types"
 	"github.com/llir/llvm/values"
@@ -23,34 +24,77 @@ import (
 
 // parseTopLevelEntity parses a top-level entity and stores it in module.
 //
-//    TopLevelEntity = FuncDecl | FuncDef .
+//    TopLevelEntity = GlobalDecl | FuncDecl | FuncDef .
 func (p *parser) parseTopLevelEntity(module *ir.Module) error {
 	tok := p.next()
 	switch tok.Kind {
 	case token.Error:
+		p.backup()
 		return errutil.New(tok.Val)
 	case token.EOF:
 		// Terminate the parser at EOF.
 		return io.EOF
+	case token.GlobalVar, token.GlobalID:
+		global, err := p.parseGlobalDecl(tok.Val)
+		if err != nil {
+			return err
+		}
+		p.globals[global.Name] = global
+		module.AppendGlobal(global)
+		return nil
 	case token.KwDeclare:
 		f, err := p.parseDeclare()
 		if err != nil {
 			return err
 		}
-		_ = f
-		panic("not yet implemented.")
+		module.AppendFunc(f)
+		return nil
 	case token.KwDefine:
 		f, err := p.parseDefine()
 		if err != nil {
 			return err
 		}
-		_ = f
-		panic("not yet implemented.")
+		module.AppendFunc(f)
+		return nil
 	default:
+		p.backup()
 		return errutil.Newf("invalid token type %v; expected top-level entity", tok.Kind)
 	}
 }
 
+// parseGlobalDecl parses a global variable definition or an external global
+// variable declaration. A global name token has already been consumed.
+//
+//    GlobalDecl = Global "=" [ "external" ] ( "global" | "constant" ) Type [ Init ] .
+//
+//    Init = Value .
+func (p *parser) parseGlobalDecl(name string) (*ir.Global, error) {
+	if !p.accept(token.Equal) {
+		return nil, errutil.New("expected '=' after global variable name")
+	}
+	external := p.accept(token.KwExternal)
+	global := &ir.Global{Name: name}
+	switch {
+	case p.accept(token.KwGlobal):
+	case p.accept(token.KwConstant):
+		global.IsConst = true
+	default:
+		return nil, errutil.New("expected 'global' or 'constant'")
+	}
+	var err error
+	global.Content, err = p.parseType()
+	if err != nil {
+		return nil, err
+	}
+	if !external {
+		global.Init, err = p.parseValue(global.Content)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return global, nil
+}
+
 // parseDeclare parses a function declaration. A "declare" token has already
 // been consumed.
 //
@@ -68,8 +112,10 @@ func (p *parser) parseDefine() (*ir.Function, error) {
 	if err != nil {
 		return nil, err
 	}
-	f.Blocks, err = p.parseFuncBody()
-	return f, err
+	if err := p.parseFuncBody(f); err != nil {
+		return nil, err
+	}
+	return f, nil
 }
 
 // parseFuncHeader parses a function header consisting of a return argument, a
@@ -77,7 +123,9 @@ func (p *parser) parseDefine() (*ir.Function, error) {
 //
 //    FuncHeader = FuncResult FuncName "(" FuncParams ")" .
 //
-//    FuncName = Global .
+//    FuncName   = Global .
+//    FuncParams = [ FuncParam { "," FuncParam } [ "," "..." ] ] | "..." .
+//    FuncParam  = Type [ Local ] .
 func (p *parser) parseFuncHeader() (header *ir.Function, err error) {
 	result, err := p.parseType()
 	if err != nil {
@@ -91,17 +139,58 @@ func (p *parser) parseFuncHeader() (header *ir.Function, err error) {
 		Name: name,
 	}
 	if !p.accept(token.Lparen) {
-		return nil, errors.New("expected '(' in function argument list")
+		return nil, errutil.New("expected '(' in function argument list")
+	}
+
+	// Function parameters.
+	var params []types.Type
+	var names []string
+	named, variadic := false, false
+	for i := 0; !p.accept(token.Rparen); i++ {
+		if i > 0 && !p.accept(token.Comma) {
+			return nil, errutil.New("expected ')' at end of argument list")
+		}
+		if p.accept(token.Ellipsis) {
+			variadic = true
+			if !p.accept(token.Rparen) {
+				return nil, errutil.New("expected ')' at end of argument list")
+			}
+			break
+		}
+		param, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, param)
+		name, ok := p.tryLocal()
+		named = named || ok
+		names = append(names, name)
+	}
+	header.Sig, err = types.NewFunc(result, params, variadic)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unnamed parameters are assigned sequential numeric names, starting at 0.
+	p.nextID = 0
+	if named {
+		for i, param := range params {
+			if len(names[i]) == 0 {
+				names[i] = strconv.Itoa(p.nextID)
+				p.nextID++
+			}
+			header.Params = append(header.Params, values.NewParam(names[i], param))
+		}
 	}
-	// TODO: Don't use parseFuncType as it is specifically for function types
-	// which do not include parameter names. Write a custom implementation for
-	// function declarations and function definitions.
-	_ = result
-	//header.Sig, err = p.parseFunc(result)
-	return header, err
+
+	// Register the function prior to parsing its body, to allow for recursive
+	// function calls.
+	p.globals[header.Name] = header
+	return header, nil
 }
 
-// parseFuncBody parses a function body consisting of one or more basic blocks.
+// parseFuncBody parses a function body consisting of one or more basic blocks
+// and appends them to f.
 //
 //    FuncBody = "{" BasicBlock { BasicBlock } "}" .
 //
@@ -122,8 +211,179 @@ func (p *parser) parseFuncHeader() (header *ir.Function, err error) {
 //                  LandingpadInst .
 //    Terminator  = RetInst | BrInst | SwitchInst | IndirectbrInst |
 //                  InvokeInst | ResumeInst | UnreachableInst .
-func (p *parser) parseFuncBody() (body []*ir.BasicBlock, err error) {
-	panic("not yet implemented.")
+func (p *parser) parseFuncBody(f *ir.Function) error {
+	if !p.accept(token.Lbrace) {
+		return errutil.New("expected '{' at start of function body")
+	}
+
+	// Function scope.
+	p.locals = make(map[string]values.Value)
+	for _, param := range f.Params {
+		p.locals[param.Name] = param
+	}
+	p.blocks = make(map[string]*ir.BasicBlock)
+	defined := make(map[string]bool)
+
+	for !p.accept(token.Rbrace) {
+		// Label declaration; the label of the entry basic block may be omitted.
+		name, ok := p.try(token.Label)
+		if !ok {
+			if len(f.Blocks) > 0 {
+				return errutil.New("expected basic block label")
+			}
+			name = strconv.Itoa(p.nextID)
+			p.nextID++
+		}
+		if defined[name] {
+			return errutil.Newf("invalid basic block label %q; redefinition", name)
+		}
+		defined[name] = true
+		block := p.getBlock(name)
+		f.AppendBlock(block)
+		if err := p.parseBasicBlock(block); err != nil {
+			return err
+		}
+	}
+	if len(f.Blocks) == 0 {
+		return errutil.New("expected one or more basic blocks in function body")
+	}
+
+	// Verify that all referenced basic blocks are defined.
+	var undefined []string
+	for name := range p.blocks {
+		if !defined[name] {
+			undefined = append(undefined, name)
+		}
+	}
+	if len(undefined) > 0 {
+		// Report the error at the end of the function body.
+		p.backup()
+		sort.Strings(undefined)
+		return errutil.Newf("undefined basic block label %q", undefined[0])
+	}
+	return nil
+}
+
+// parseBasicBlock parses the instructions and the terminator of a basic block.
+// The label declaration of the basic block has already been consumed.
+func (p *parser) parseBasicBlock(block *ir.BasicBlock) error {
+	for {
+		tok := p.next()
+		switch tok.Kind {
+		// Value-producing instructions.
+		//    %x = add i32 %a, %b
+		case token.LocalVar, token.LocalID:
+			if !p.accept(token.Equal) {
+				return errutil.New("expected '=' after local variable name")
+			}
+			inst, err := p.parseInst(tok.Val)
+			if err != nil {
+				return err
+			}
+			p.comment(inst)
+			block.AppendInst(inst)
+		case token.KwStore:
+			inst, err := p.parseStoreInst()
+			if err != nil {
+				return err
+			}
+			p.comment(inst)
+			block.AppendInst(inst)
+		default:
+			p.backup()
+			term, err := p.parseTerm()
+			if err != nil {
+				return err
+			}
+			p.comment(term)
+			block.SetTerm(term)
+			return nil
+		}
+	}
+}
+
+// parseInst parses a value-producing instruction, the result of which is
+// assigned to the local variable name.
+func (p *parser) parseInst(name string) (ir.Instruction, error) {
+	switch tok := p.next(); tok.Kind {
+	// Binary operations.
+	case token.KwAdd:
+		return p.parseAddInst(name)
+	case token.KwFadd:
+		return p.parseFaddInst(name)
+	case token.KwSub:
+		return p.parseSubInst(name)
+	case token.KwFsub:
+		return p.parseFsubInst(name)
+	case token.KwMul:
+		return p.parseMulInst(name)
+	case token.KwFmul:
+		return p.parseFmulInst(name)
+	case token.KwUdiv:
+		return p.parseUdivInst(name)
+	case token.KwSdiv:
+		return p.parseSdivInst(name)
+	case token.KwFdiv:
+		return p.parseFdivInst(name)
+	case token.KwUrem:
+		return p.parseUremInst(name)
+	case token.KwSrem:
+		return p.parseSremInst(name)
+	case token.KwFrem:
+		return p.parseFremInst(name)
+
+	// Bitwise binary operations.
+	case token.KwShl:
+		return p.parseShlInst(name)
+	case token.KwLshr:
+		return p.parseLshrInst(name)
+	case token.KwAshr:
+		return p.parseAshrInst(name)
+	case token.KwAnd:
+		return p.parseAndInst(name)
+	case token.KwOr:
+		return p.parseOrInst(name)
+	case token.KwXor:
+		return p.parseXorInst(name)
+
+	// Memory access and addressing operations.
+	case token.KwAlloca:
+		return p.parseAllocaInst(name)
+	case token.KwLoad:
+		return p.parseLoadInst(name)
+	default:
+		p.backup()
+		return nil, errutil.Newf("invalid token type %v; expected instruction", tok.Kind)
+	}
+}
+
+// parseTerm parses a terminator instruction.
+func (p *parser) parseTerm() (ir.Terminator, error) {
+	switch tok := p.next(); tok.Kind {
+	case token.KwRet:
+		return p.parseRetInst()
+	case token.KwBr:
+		return p.parseBrInst()
+	case token.KwSwitch:
+		return p.parseSwitchInst()
+	case token.KwUnreachable:
+		return p.parseUnreachableInst()
+	default:
+		p.backup()
+		return nil, errutil.Newf("invalid token type %v; expected instruction or terminator", tok.Kind)
+	}
+}
+
+// getBlock returns the basic block of the current function with the given
+// label name. A new basic block is created if the label has not yet been
+// referenced, to allow for forward references.
+func (p *parser) getBlock(name string) *ir.BasicBlock {
+	block, ok := p.blocks[name]
+	if !ok {
+		block = &ir.BasicBlock{Name: name}
+		p.blocks[name] = block
+	}
+	return block
 }
 
 // parseType parses a type.
@@ -195,6 +455,7 @@ func (p *parser) parseType() (typ types.Type, err error) {
 		}
 
 	default:
+		p.backup()
 		return nil, errutil.New("expected type")
 	}
 
@@ -437,13 +698,140 @@ func basicTypeFromString(s string) (types.Type, error) {
 	return types.NewInt(n)
 }
 
-// TODO: Complete Value EBNF definition.
+// parseValue parses a value of the given type.
+//
+//    Value = Global | Local | int_lit | float_lit | "true" | "false" | "null" |
+//            "undef" | CharArray .
+//
+//    CharArray = "c" string_lit .
+func (p *parser) parseValue(typ types.Type) (values.Value, error) {
+	switch tok := p.next(); tok.Kind {
+	// Local variable or function parameter.
+	//    %x
+	case token.LocalVar, token.LocalID:
+		if v, ok := p.locals[tok.Val]; ok {
+			return v, nil
+		}
+		return values.NewLocal(tok.Val, typ), nil
+
+	// Global variable or function.
+	//    @x
+	case token.GlobalVar, token.GlobalID:
+		if v, ok := p.globals[tok.Val]; ok {
+			return v, nil
+		}
+		p.backup()
+		return nil, errutil.Newf("undefined global %q", tok.Val)
+
+	// Integer constant.
+	//    42
+	//    true
+	case token.Int, token.KwTrue, token.KwFalse:
+		v, err := consts.NewInt(typ, tok.Val)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+
+	// Floating point constant.
+	//    3.0
+	case token.Float:
+		v, err := consts.NewFloat(typ, tok.Val)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+
+	// Null pointer constant.
+	//    null
+	case token.KwNull:
+		v, err := consts.NewNull(typ)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
 
-// parseValue parses a value.
+	// Undefined value.
+	//    undef
+	case token.KwUndef:
+		v, err := consts.NewUndef(typ)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+
+	// Character array constant.
+	//    c"foo"
+	case token.KwC:
+		s, ok := p.try(token.String)
+		if !ok {
+			return nil, errutil.New("expected string after 'c'")
+		}
+		v := consts.NewCharArray(s)
+		if !v.Type().Equal(typ) {
+			return nil, errutil.Newf("invalid character array type; expected %q, got %q", typ, v.Type())
+		}
+		return v, nil
+
+	default:
+		p.backup()
+		return nil, errutil.Newf("invalid token type %v; expected value", tok.Kind)
+	}
+}
+
+// parseTypeValue parses a type followed by a value of that type.
 //
-//    Value = TODO .
-func (p *parser) parseValue() (values.Value, error) {
-	panic("not yet implemented.")
+//    TypeValue = Type Value .
+func (p *parser) parseTypeValue() (values.Value, error) {
+	typ, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+	return p.parseValue(typ)
+}
+
+// parseLabel parses a basic block label reference.
+//
+//    Label = LabelType Local .
+func (p *parser) parseLabel() (*ir.BasicBlock, error) {
+	if s, ok := p.try(token.Type); !ok || s != "label" {
+		if ok {
+			p.backup()
+		}
+		return nil, errutil.New("expected 'label'")
+	}
+	name, ok := p.tryLocal()
+	if !ok {
+		return nil, errutil.New("expected basic block label name")
+	}
+	return p.getBlock(name), nil
+}
+
+// parseInt parses an integer literal.
+func (p *parser) parseInt() (int, error) {
+	s, ok := p.try(token.Int)
+	if !ok {
+		return 0, errutil.New("expected integer literal")
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, errutil.Newf("invalid integer literal (%v); %v", s, err)
+	}
+	return n, nil
+}
+
+// parseAlign parses an optional trailing memory alignment, and returns 0 if
+// none is present.
+//
+//    [ "," "align" Align ]
+func (p *parser) parseAlign() (int, error) {
+	if !p.accept(token.Comma) {
+		return 0, nil
+	}
+	if !p.accept(token.KwAlign) {
+		return 0, errutil.New("expected 'align'")
+	}
+	return p.parseInt()
 }
 
 // =============================================================================
@@ -458,7 +846,18 @@ func (p *parser) parseValue() (values.Value, error) {
 //    RetInst = "ret" VoidType |
 //              "ret" Type Value .
 func (p *parser) parseRetInst() (*ir.ReturnInst, error) {
-	panic("not yet implemented.")
+	typ, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := typ.(*types.Void); ok {
+		return ir.NewRet(nil), nil
+	}
+	val, err := p.parseValue(typ)
+	if err != nil {
+		return nil, err
+	}
+	return ir.NewRet(val), nil
 }
 
 // parseBrInst parses a branch instruction. A "br" token has already been
@@ -471,8 +870,43 @@ func (p *parser) parseRetInst() (*ir.ReturnInst, error) {
 //    Cond        = Value .
 //    TargetTrue  = Local .
 //    TargetFalse = Local .
-func (p *parser) parseBrInst() (*ir.BranchInst, error) {
-	panic("not yet implemented.")
+func (p *parser) parseBrInst() (ir.Terminator, error) {
+	typ, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+
+	// Unconditional branch.
+	//    br label %foo
+	if _, ok := typ.(*types.Label); ok {
+		name, ok := p.tryLocal()
+		if !ok {
+			return nil, errutil.New("expected basic block label name")
+		}
+		return ir.NewBr(p.getBlock(name)), nil
+	}
+
+	// Conditional branch.
+	//    br i1 %cond, label %true, label %false
+	cond, err := p.parseValue(typ)
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept(token.Comma) {
+		return nil, errutil.New("expected ',' after branching condition")
+	}
+	trueBranch, err := p.parseLabel()
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept(token.Comma) {
+		return nil, errutil.New("expected ',' after true branch target")
+	}
+	falseBranch, err := p.parseLabel()
+	if err != nil {
+		return nil, err
+	}
+	return ir.NewCondBr(cond, trueBranch, falseBranch)
 }
 
 // parseSwitchInst parses a switch instruction. A "switch" token has already
@@ -483,7 +917,40 @@ func (p *parser) parseBrInst() (*ir.BranchInst, error) {
 //    TargetDefault = Local .
 //    TargetCase    = Local .
 func (p *parser) parseSwitchInst() (*ir.SwitchInst, error) {
-	panic("not yet implemented.")
+	val, err := p.parseTypeValue()
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept(token.Comma) {
+		return nil, errutil.New("expected ',' after switch comparison value")
+	}
+	def, err := p.parseLabel()
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept(token.Lbrack) {
+		return nil, errutil.New("expected '[' at start of switch cases")
+	}
+	var cases []ir.SwitchCase
+	for !p.accept(token.Rbrack) {
+		v, err := p.parseTypeValue()
+		if err != nil {
+			return nil, err
+		}
+		c, ok := v.(consts.Constant)
+		if !ok {
+			return nil, errutil.Newf("invalid switch case %q; expected constant", v)
+		}
+		if !p.accept(token.Comma) {
+			return nil, errutil.New("expected ',' after switch case value")
+		}
+		target, err := p.parseLabel()
+		if err != nil {
+			return nil, err
+		}
+		cases = append(cases, ir.SwitchCase{Val: c, Target: target})
+	}
+	return ir.NewSwitch(val, def, cases)
 }
 
 // TODO: Add parsing of IndirectbrInst, InvokeInst, ResumeInst.
@@ -493,7 +960,7 @@ func (p *parser) parseSwitchInst() (*ir.SwitchInst, error) {
 //
 //    UnreachableInst = "unreachable" .
 func (p *parser) parseUnreachableInst() (*ir.UnreachableInst, error) {
-	panic("not yet implemented.")
+	return &ir.UnreachableInst{}, nil
 }
 
 // =============================================================================
@@ -502,112 +969,229 @@ func (p *parser) parseUnreachableInst() (*ir.UnreachableInst, error) {
 //    ref: http://llvm.org/docs/LangRef.html#binaryops
 // =============================================================================
 
+// parseBinaryOperands parses the operand type and the two operands of a binary
+// instruction.
+//
+//    BinaryOperands = Type Op1 "," Op2 .
+//
+//    Op1 = Value
+//    Op2 = Value
+func (p *parser) parseBinaryOperands() (typ types.Type, op1, op2 values.Value, err error) {
+	typ, err = p.parseType()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	op1, err = p.parseValue(typ)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if !p.accept(token.Comma) {
+		return nil, nil, nil, errutil.New("expected ',' after first operand")
+	}
+	op2, err = p.parseValue(typ)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return typ, op1, op2, nil
+}
+
+// parseFastMathFlags parses the optional fast-math flags of a floating point
+// instruction.
+//
+//    FastMathFlags = { "nnan" | "ninf" | "nsz" | "arcp" | "fast" } .
+func (p *parser) parseFastMathFlags() ir.FastMathFlags {
+	var flags ir.FastMathFlags
+	for {
+		switch tok := p.next(); tok.Kind {
+		case token.KwNnan:
+			flags.Set(ir.FastNoNaNs)
+		case token.KwNinf:
+			flags.Set(ir.FastNoInfs)
+		case token.KwNsz:
+			flags.Set(ir.FastNoSignedZeros)
+		case token.KwArcp:
+			flags.Set(ir.FastAllowRecip)
+		case token.KwFast:
+			flags.Set(ir.Fast)
+		default:
+			p.backup()
+			return flags
+		}
+	}
+}
+
 // parseAddInst parses an addition instruction. An "add" token has already been
 // comsumed.
 //
-//    AddInst = Result "=" "add" IntsType Op1 "," Op2 .
+//    AddInst = Result "=" "add" [ "nuw" ] [ "nsw" ] IntsType Op1 "," Op2 .
 //
 //    Result = Local
 //    Op1    = Value
 //    Op2    = Value
-func (p *parser) parseAddInst() (*ir.AddInst, error) {
-	panic("not yet implemented.")
+func (p *parser) parseAddInst(name string) (*ir.AddInst, error) {
+	nuw := p.accept(token.KwNuw)
+	nsw := p.accept(token.KwNsw)
+	typ, op1, op2, err := p.parseBinaryOperands()
+	if err != nil {
+		return nil, err
+	}
+	inst := &ir.AddInst{Type: typ, Op1: op1, Op2: op2, NUW: nuw, NSW: nsw}
+	inst.Name = name
+	return inst, nil
 }
 
 // parseFaddInst parses a floating-point addition instruction. A "fadd" token
 // has already been comsumed.
 //
-//    FaddInst = Result "=" "fadd" FloatsType Op1 "," Op2 .
+//    FaddInst = Result "=" "fadd" FastMathFlags FloatsType Op1 "," Op2 .
 //
 //    Result = Local
 //    Op1    = Value
 //    Op2    = Value
-func (p *parser) parseFaddInst() (*ir.FaddInst, error) {
-	panic("not yet implemented.")
+func (p *parser) parseFaddInst(name string) (*ir.FaddInst, error) {
+	flags := p.parseFastMathFlags()
+	typ, op1, op2, err := p.parseBinaryOperands()
+	if err != nil {
+		return nil, err
+	}
+	inst := &ir.FaddInst{Type: typ, Op1: op1, Op2: op2, FastMath: flags}
+	inst.Name = name
+	return inst, nil
 }
 
 // parseSubInst parses a subtraction instruction. A "sub" token has already been
 // comsumed.
 //
-//    SubInst = Result "=" "sub" IntsType Op1 "," Op2 .
+//    SubInst = Result "=" "sub" [ "nuw" ] [ "nsw" ] IntsType Op1 "," Op2 .
 //
 //    Result = Local
 //    Op1    = Value
 //    Op2    = Value
-func (p *parser) parseSubInst() (*ir.SubInst, error) {
-	panic("not yet implemented.")
+func (p *parser) parseSubInst(name string) (*ir.SubInst, error) {
+	nuw := p.accept(token.KwNuw)
+	nsw := p.accept(token.KwNsw)
+	typ, op1, op2, err := p.parseBinaryOperands()
+	if err != nil {
+		return nil, err
+	}
+	inst := &ir.SubInst{Type: typ, Op1: op1, Op2: op2, NUW: nuw, NSW: nsw}
+	inst.Name = name
+	return inst, nil
 }
 
 // parseFsubInst parses a floating-point subtraction instruction. A "fsub" token
 // has already been comsumed.
 //
-//    FsubInst = Result "=" "fsub" FloatsType Op1 "," Op2 .
+//    FsubInst = Result "=" "fsub" FastMathFlags FloatsType Op1 "," Op2 .
 //
 //    Result = Local
 //    Op1    = Value
 //    Op2    = Value
-func (p *parser) parseFsubInst() (*ir.FsubInst, error) {
-	panic("not yet implemented.")
+func (p *parser) parseFsubInst(name string) (*ir.FsubInst, error) {
+	flags := p.parseFastMathFlags()
+	typ, op1, op2, err := p.parseBinaryOperands()
+	if err != nil {
+		return nil, err
+	}
+	inst := &ir.FsubInst{Type: typ, Op1: op1, Op2: op2, FastMath: flags}
+	inst.Name = name
+	return inst, nil
 }
 
 // parseMulInst parses a multiplication instruction. A "mul" token has already
 // been comsumed.
 //
-//    MulInst = Result "=" "mul" IntsType Op1 "," Op2 .
+//    MulInst = Result "=" "mul" [ "nuw" ] [ "nsw" ] IntsType Op1 "," Op2 .
 //
 //    Result = Local
 //    Op1    = Value
 //    Op2    = Value
-func (p *parser) parseMulInst() (*ir.MulInst, error) {
-	panic("not yet implemented.")
+func (p *parser) parseMulInst(name string) (*ir.MulInst, error) {
+	nuw := p.accept(token.KwNuw)
+	nsw := p.accept(token.KwNsw)
+	typ, op1, op2, err := p.parseBinaryOperands()
+	if err != nil {
+		return nil, err
+	}
+	inst := &ir.MulInst{Type: typ, Op1: op1, Op2: op2, NUW: nuw, NSW: nsw}
+	inst.Name = name
+	return inst, nil
 }
 
 // parseFmulInst parses a floating-point multiplication instruction. A "fmul"
 // token has already been comsumed.
 //
-//    FmulInst = Result "=" "fmul" FloatsType Op1 "," Op2 .
+//    FmulInst = Result "=" "fmul" FastMathFlags FloatsType Op1 "," Op2 .
 //
 //    Result = Local
 //    Op1    = Value
 //    Op2    = Value
-func (p *parser) parseFmulInst() (*ir.FmulInst, error) {
-	panic("not yet implemented.")
+func (p *parser) parseFmulInst(name string) (*ir.FmulInst, error) {
+	flags := p.parseFastMathFlags()
+	typ, op1, op2, err := p.parseBinaryOperands()
+	if err != nil {
+		return nil, err
+	}
+	inst := &ir.FmulInst{Type: typ, Op1: op1, Op2: op2, FastMath: flags}
+	inst.Name = name
+	return inst, nil
 }
 
 // parseUdivInst parses a unsigned division instruction. An "udiv" token has
 // already been comsumed.
 //
-//    UdivInst = Result "=" "udiv" IntsType Op1 "," Op2 .
+//    UdivInst = Result "=" "udiv" [ "exact" ] IntsType Op1 "," Op2 .
 //
 //    Result = Local
 //    Op1    = Value
 //    Op2    = Value
-func (p *parser) parseUdivInst() (*ir.UdivInst, error) {
-	panic("not yet implemented.")
+func (p *parser) parseUdivInst(name string) (*ir.UdivInst, error) {
+	exact := p.accept(token.KwExect)
+	typ, op1, op2, err := p.parseBinaryOperands()
+	if err != nil {
+		return nil, err
+	}
+	inst := &ir.UdivInst{Type: typ, Op1: op1, Op2: op2, Exact: exact}
+	inst.Name = name
+	return inst, nil
 }
 
 // parseSdivInst parses a signed division instruction. A "sdiv" token has
 // already been comsumed.
 //
-//    SdivInst = Result "=" "sdiv" IntsType Op1 "," Op2 .
+//    SdivInst = Result "=" "sdiv" [ "exact" ] IntsType Op1 "," Op2 .
 //
 //    Result = Local
 //    Op1    = Value
 //    Op2    = Value
-func (p *parser) parseSdivInst() (*ir.SdivInst, error) {
-	panic("not yet implemented.")
+func (p *parser) parseSdivInst(name string) (*ir.SdivInst, error) {
+	exact := p.accept(token.KwExect)
+	typ, op1, op2, err := p.parseBinaryOperands()
+	if err != nil {
+		return nil, err
+	}
+	inst := &ir.SdivInst{Type: typ, Op1: op1, Op2: op2, Exact: exact}
+	inst.Name = name
+	return inst, nil
 }
 
 // parseFdivInst parses a floating-point division instruction. A "fdiv" token
 // has already been comsumed.
 //
-//    FdivInst = Result "=" "fdiv" FloatsType Op1 "," Op2 .
+//    FdivInst = Result "=" "fdiv" FastMathFlags FloatsType Op1 "," Op2 .
 //
 //    Result = Local
 //    Op1    = Value
 //    Op2    = Value
-func (p *parser) parseFdivInst() (*ir.FdivInst, error) {
-	panic("not yet implemented.")
+func (p *parser) parseFdivInst(name string) (*ir.FdivInst, error) {
+	flags := p.parseFastMathFlags()
+	typ, op1, op2, err := p.parseBinaryOperands()
+	if err != nil {
+		return nil, err
+	}
+	inst := &ir.FdivInst{Type: typ, Op1: op1, Op2: op2, FastMath: flags}
+	inst.Name = name
+	return inst, nil
 }
 
 // parseUremInst parses a unsigned modulo instruction. An "urem" token has
@@ -618,8 +1202,14 @@ func (p *parser) parseFdivInst() (*ir.FdivInst, error) {
 //    Result = Local
 //    Op1    = Value
 //    Op2    = Value
-func (p *parser) parseUremInst() (*ir.UremInst, error) {
-	panic("not yet implemented.")
+func (p *parser) parseUremInst(name string) (*ir.UremInst, error) {
+	typ, op1, op2, err := p.parseBinaryOperands()
+	if err != nil {
+		return nil, err
+	}
+	inst := &ir.UremInst{Type: typ, Op1: op1, Op2: op2}
+	inst.Name = name
+	return inst, nil
 }
 
 // parseSremInst parses a signed modulo instruction. An "srem" token has already
@@ -630,20 +1220,33 @@ func (p *parser) parseUremInst() (*ir.UremInst, error) {
 //    Result = Local
 //    Op1    = Value
 //    Op2    = Value
-func (p *parser) parseSremInst() (*ir.SremInst, error) {
-	panic("not yet implemented.")
+func (p *parser) parseSremInst(name string) (*ir.SremInst, error) {
+	typ, op1, op2, err := p.parseBinaryOperands()
+	if err != nil {
+		return nil, err
+	}
+	inst := &ir.SremInst{Type: typ, Op1: op1, Op2: op2}
+	inst.Name = name
+	return inst, nil
 }
 
 // parseFremInst parses a floating-point modulo instruction. A "frem" token
 // has already been comsumed.
 //
-//    FremInst = Result "=" "frem" FloatsType Op1 "," Op2 .
+//    FremInst = Result "=" "frem" FastMathFlags FloatsType Op1 "," Op2 .
 //
 //    Result = Local
 //    Op1    = Value
 //    Op2    = Value
-func (p *parser) parseFremInst() (*ir.FremInst, error) {
-	panic("not yet implemented.")
+func (p *parser) parseFremInst(name string) (*ir.FremInst, error) {
+	flags := p.parseFastMathFlags()
+	typ, op1, op2, err := p.parseBinaryOperands()
+	if err != nil {
+		return nil, err
+	}
+	inst := &ir.FremInst{Type: typ, Op1: op1, Op2: op2, FastMath: flags}
+	inst.Name = name
+	return inst, nil
 }
 
 // =============================================================================
@@ -655,37 +1258,59 @@ func (p *parser) parseFremInst() (*ir.FremInst, error) {
 // parseShlInst parses a shift left instruction. A "shl" token has already been
 // comsumed.
 //
-//    ShlInst = Result "=" "shl" IntsType Op1 "," Op2 .
+//    ShlInst = Result "=" "shl" [ "nuw" ] [ "nsw" ] IntsType Op1 "," Op2 .
 //
 //    Result = Local
 //    Op1    = Value
 //    Op2    = Value
-func (p *parser) parseShlInst() (*ir.ShlInst, error) {
-	panic("not yet implemented.")
+func (p *parser) parseShlInst(name string) (*ir.ShlInst, error) {
+	nuw := p.accept(token.KwNuw)
+	nsw := p.accept(token.KwNsw)
+	typ, op1, op2, err := p.parseBinaryOperands()
+	if err != nil {
+		return nil, err
+	}
+	inst := &ir.ShlInst{Type: typ, Op1: op1, Op2: op2, NUW: nuw, NSW: nsw}
+	inst.Name = name
+	return inst, nil
 }
 
 // parseLshrInst parses a logical shift right instruction. A "lshr" token has
 // already been comsumed.
 //
-//    LshrInst = Result "=" "lshr" IntsType Op1 "," Op2 .
+//    LshrInst = Result "=" "lshr" [ "exact" ] IntsType Op1 "," Op2 .
 //
 //    Result = Local
 //    Op1    = Value
 //    Op2    = Value
-func (p *parser) parseLshrInst() (*ir.LshrInst, error) {
-	panic("not yet implemented.")
+func (p *parser) parseLshrInst(name string) (*ir.LshrInst, error) {
+	exact := p.accept(token.KwExect)
+	typ, op1, op2, err := p.parseBinaryOperands()
+	if err != nil {
+		return nil, err
+	}
+	inst := &ir.LshrInst{Type: typ, Op1: op1, Op2: op2, Exact: exact}
+	inst.Name = name
+	return inst, nil
 }
 
 // parseAshrInst parses an arithmetic shift right instruction. An "ashr" token
 // has already been comsumed.
 //
-//    AshrInst = Result "=" "ashr" IntsType Op1 "," Op2 .
+//    AshrInst = Result "=" "ashr" [ "exact" ] IntsType Op1 "," Op2 .
 //
 //    Result = Local
 //    Op1    = Value
 //    Op2    = Value
-func (p *parser) parseAshrInst() (*ir.AshrInst, error) {
-	panic("not yet implemented.")
+func (p *parser) parseAshrInst(name string) (*ir.AshrInst, error) {
+	exact := p.accept(token.KwExect)
+	typ, op1, op2, err := p.parseBinaryOperands()
+	if err != nil {
+		return nil, err
+	}
+	inst := &ir.AshrInst{Type: typ, Op1: op1, Op2: op2, Exact: exact}
+	inst.Name = name
+	return inst, nil
 }
 
 // parseAndInst parses a bitwise logical AND instruction. An "and" token has
@@ -696,8 +1321,14 @@ func (p *parser) parseAshrInst() (*ir.AshrInst, error) {
 //    Result = Local
 //    Op1    = Value
 //    Op2    = Value
-func (p *parser) parseAndInst() (*ir.AndInst, error) {
-	panic("not yet implemented.")
+func (p *parser) parseAndInst(name string) (*ir.AndInst, error) {
+	typ, op1, op2, err := p.parseBinaryOperands()
+	if err != nil {
+		return nil, err
+	}
+	inst := &ir.AndInst{Type: typ, Op1: op1, Op2: op2}
+	inst.Name = name
+	return inst, nil
 }
 
 // parseOrInst parses a bitwise logical OR instruction. A "or" token has already
@@ -708,8 +1339,14 @@ func (p *parser) parseAndInst() (*ir.AndInst, error) {
 //    Result = Local
 //    Op1    = Value
 //    Op2    = Value
-func (p *parser) parseOrInst() (*ir.OrInst, error) {
-	panic("not yet implemented.")
+func (p *parser) parseOrInst(name string) (*ir.OrInst, error) {
+	typ, op1, op2, err := p.parseBinaryOperands()
+	if err != nil {
+		return nil, err
+	}
+	inst := &ir.OrInst{Type: typ, Op1: op1, Op2: op2}
+	inst.Name = name
+	return inst, nil
 }
 
 // parseXorInst parses a bitwise logical XOR instruction. A "xor" token has
@@ -720,8 +1357,14 @@ func (p *parser) parseOrInst() (*ir.OrInst, error) {
 //    Result = Local
 //    Op1    = Value
 //    Op2    = Value
-func (p *parser) parseXorInst() (*ir.XorInst, error) {
-	panic("not yet implemented.")
+func (p *parser) parseXorInst(name string) (*ir.XorInst, error) {
+	typ, op1, op2, err := p.parseBinaryOperands()
+	if err != nil {
+		return nil, err
+	}
+	inst := &ir.XorInst{Type: typ, Op1: op1, Op2: op2}
+	inst.Name = name
+	return inst, nil
 }
 
 // =============================================================================
@@ -755,20 +1398,76 @@ func (p *parser) parseXorInst() (*ir.XorInst, error) {
 //    Result   = Local
 //    NumElems = Value
 //    Align    = int_lit
-func (p *parser) parseAllocaInst() (*ir.AllocaInst, error) {
-	panic("not yet implemented.")
+func (p *parser) parseAllocaInst(name string) (*ir.AllocaInst, error) {
+	typ, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+	// Optional element count.
+	//    , i32 4
+	numElems := 1
+	cur := p.cur
+	if p.accept(token.Comma) && !p.accept(token.KwAlign) {
+		if _, err := p.parseType(); err != nil {
+			return nil, err
+		}
+		if numElems, err = p.parseInt(); err != nil {
+			return nil, err
+		}
+	} else {
+		p.cur = cur
+	}
+	inst, err := ir.NewAlloca(typ, numElems)
+	if err != nil {
+		return nil, err
+	}
+	align, err := p.parseAlign()
+	if err != nil {
+		return nil, err
+	}
+	// Only record alignments present in the input.
+	inst.Align = 0
+	if align != 0 {
+		if err := inst.SetAlign(align); err != nil {
+			return nil, err
+		}
+	}
+	inst.Name = name
+	return inst, nil
 }
 
 // parseLoadInst parses a memory load instruction. A "load" token has already
 // been comsumed.
 //
-//    LoadInst = Result "=" "load" Type "*" Addr [ "," "align" Align ] .
+//    LoadInst = Result "=" "load" Type "," Type "*" Addr [ "," "align" Align ] .
 //
 //    Result = Local
 //    Addr   = Global | Local
 //    Align  = int_lit
-func (p *parser) parseLoadInst() (*ir.LoadInst, error) {
-	panic("not yet implemented.")
+func (p *parser) parseLoadInst(name string) (*ir.LoadInst, error) {
+	typ, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept(token.Comma) {
+		return nil, errutil.New("expected ',' after loaded type")
+	}
+	addr, err := p.parseTypeValue()
+	if err != nil {
+		return nil, err
+	}
+	inst, err := ir.NewLoad(addr)
+	if err != nil {
+		return nil, err
+	}
+	if !inst.Type.Equal(typ) {
+		return nil, errutil.Newf("invalid load address type; expected pointer to %q, got %q", typ, addr.Type())
+	}
+	if inst.Align, err = p.parseAlign(); err != nil {
+		return nil, err
+	}
+	inst.Name = name
+	return inst, nil
 }
 
 // parseStoreInst parses a memory store instruction. A "store" token has already
@@ -779,7 +1478,25 @@ func (p *parser) parseLoadInst() (*ir.LoadInst, error) {
 //    Addr   = Global | Local
 //    Align  = int_lit
 func (p *parser) parseStoreInst() (*ir.StoreInst, error) {
-	panic("not yet implemented.")
+	val, err := p.parseTypeValue()
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept(token.Comma) {
+		return nil, errutil.New("expected ',' after stored value")
+	}
+	addr, err := p.parseTypeValue()
+	if err != nil {
+		return nil, err
+	}
+	inst, err := ir.NewStore(val, addr)
+	if err != nil {
+		return nil, err
+	}
+	if inst.Align, err = p.parseAlign(); err != nil {
+		return nil, err
+	}
+	return inst, nil
 }
 
 // TODO: Add parsing of FenceInst, CmpxchgInst, AtomicrmwInst.
@@ -787,7 +1504,7 @@ func (p *parser) parseStoreInst() (*ir.StoreInst, error) {
 // parseGetelementptrInst parses a memory address calculation instruction. A
 // "getelementptr" token has already been comsumed.
 //
-//    GetelementptrInst = Result "=" "getelementptr" Type "*" Addr { "," IntType Idx } .
+//    GetelementptrInst = Result "=" "getelementptr" [ "inbounds" ] Type "," Type "*" Addr { "," IntType Idx } .
 //
 //    Result = Local
 //    Addr   = Global | Local
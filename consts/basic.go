@@ -2,10 +2,13 @@ package consts
 
 import (
 	"fmt"
+	"math"
+	"math/big"
 	"strconv"
 	"strings"
 
 	"github.com/llir/llvm/types"
+	"github.com/llir/llvm/values"
 )
 
 // TODO: Track the upstream removal of HexIntConstant (ref: discussion with
@@ -20,37 +23,32 @@ import (
 //    http://llvm.org/docs/LangRef.html#simple-constants
 type Int struct {
 	typ *types.Int
-	x   int64
+	x   *big.Int
 }
 
 // NewInt returns an integer constant based on the given integer type and string
-// representation.
+// representation. Integers within the range [-2^(n-1), 2^n) of an n-bit type
+// are accepted, and wrapped to their signed representation, e.g. i8 200 is
+// represented as i8 -56.
 func NewInt(typ types.Type, s string) (*Int, error) {
 	// Verify integer type.
-	v := new(Int)
-	var ok bool
-	v.typ, ok = typ.(*types.Int)
+	t, ok := typ.(*types.Int)
 	if !ok {
 		return nil, fmt.Errorf("invalid type %q for integer constant", typ)
 	}
-	size := v.typ.Size()
-	if size > 64 {
-		// TODO: Add support for large integer constants (e.g. above 64-bits).
-		err := fmt.Sprintf("not yet implemented; support for %q integer constants (e.g. above 64-bits)", typ)
-		panic(err)
-	}
 
 	// Parse boolean constant.
-	if size == 1 {
+	x := new(big.Int)
+	if t.Size() == 1 {
 		switch s {
 		case "1", "true":
-			v.x = 1
+			x.SetInt64(1)
 		case "0", "false":
-			v.x = 0
+			x.SetInt64(0)
 		default:
 			return nil, fmt.Errorf("invalid integer constant %q for boolean type", s)
 		}
-		return v, nil
+		return &Int{typ: t, x: x}, nil
 	} else if s == "true" || s == "false" {
 		return nil, fmt.Errorf("integer constant %q type mismatch; expected i1, got %v", s, typ)
 	}
@@ -59,13 +57,51 @@ func NewInt(typ types.Type, s string) (*Int, error) {
 	//    [us]0x[0-9A-Fa-f]+
 
 	// Parse integer constant.
-	var err error
-	v.x, err = strconv.ParseInt(s, 10, size)
-	if err != nil {
-		return nil, fmt.Errorf("unable to parse integer constant %q; %v", s, err)
+	if _, ok := x.SetString(s, 10); !ok {
+		return nil, fmt.Errorf("unable to parse integer constant %q; invalid syntax", s)
 	}
 
-	return v, nil
+	return newInt(t, x)
+}
+
+// NewIntFromInt64 returns an integer constant based on the given integer type
+// and value. The value is wrapped as by NewInt.
+func NewIntFromInt64(typ types.Type, x int64) (*Int, error) {
+	// Verify integer type.
+	t, ok := typ.(*types.Int)
+	if !ok {
+		return nil, fmt.Errorf("invalid type %q for integer constant", typ)
+	}
+	return newInt(t, big.NewInt(x))
+}
+
+// newInt returns an integer constant of the given type and value, after
+// verifying that x fits within the size of the integer type; either as a signed
+// or as an unsigned integer. Booleans (i1) are represented as 0 or 1, and
+// integers of other sizes are represented as signed, e.g. i8 200 is wrapped to
+// i8 -56.
+func newInt(typ *types.Int, x *big.Int) (*Int, error) {
+	size := uint(typ.Size())
+	if size == 1 {
+		if x.Cmp(big.NewInt(-1)) < 0 || x.Cmp(big.NewInt(1)) > 0 {
+			return nil, fmt.Errorf("invalid integer constant %q for boolean type", x.String())
+		}
+		return &Int{typ: typ, x: new(big.Int).Abs(x)}, nil
+	}
+
+	// Valid range: -2^(size-1) <= x < 2^size
+	half := new(big.Int).Lsh(big.NewInt(1), size-1)
+	min := new(big.Int).Neg(half)
+	max := new(big.Int).Lsh(half, 1)
+	if x.Cmp(min) < 0 || x.Cmp(max) >= 0 {
+		return nil, fmt.Errorf("integer constant %q out of range for type %q", x.String(), typ)
+	}
+	if x.Cmp(half) >= 0 {
+		// Wrap unsigned integers to their signed representation.
+		x = new(big.Int).Sub(x, max)
+	}
+
+	return &Int{typ: typ, x: x}, nil
 }
 
 // Type returns the type of the value.
@@ -73,6 +109,19 @@ func (v *Int) Type() types.Type {
 	return v.typ
 }
 
+// Ident returns the identifier associated with the integer, either as a signed
+// integer (e.g. 42, -13) or as a boolean (e.g. true, false) depending on the
+// type.
+func (v *Int) Ident() string {
+	if v.typ.Size() == 1 {
+		if v.x.Sign() != 0 {
+			return "true"
+		}
+		return "false"
+	}
+	return v.x.String()
+}
+
 // String returns a string representation of the integer, either as a signed
 // integer (e.g. 42, -13) or as a boolean (e.g. true, false) depending on the
 // type. The integer string representation is preceded by the type of the
@@ -82,19 +131,22 @@ func (v *Int) Type() types.Type {
 //    i32 -13
 //    i64 42
 func (v *Int) String() string {
-	s := ""
-	if v.typ.Size() == 1 {
-		switch v.x {
-		case 1:
-			s = "true"
-		default:
-			s = "false"
-		}
-	} else {
-		s = strconv.FormatInt(v.x, 10)
-	}
+	return fmt.Sprintf("%s %s", v.Type(), v.Ident())
+}
 
-	return fmt.Sprintf("%s %s", v.Type(), s)
+// BigInt returns a copy of the value of the integer constant. Booleans (i1) are
+// represented as 0 or 1, and integers of other sizes as signed integers.
+func (v *Int) BigInt() *big.Int {
+	return new(big.Int).Set(v.x)
+}
+
+// Equal returns true if u is an integer constant of the same type and value,
+// and false otherwise.
+func (v *Int) Equal(u values.Value) bool {
+	if u, ok := u.(*Int); ok {
+		return v.typ.Equal(u.typ) && v.x.Cmp(u.x) == 0
+	}
+	return false
 }
 
 // Float represents a floating point constant.
@@ -130,7 +182,17 @@ func NewFloat(typ types.Type, s string) (*Float, error) {
 	}
 
 	// TODO: Implement support for the following representation:
-	//    0x[KLMH]?[0-9A-Fa-f]+
+	//    0x[KLMH][0-9A-Fa-f]+
+
+	// Parse hexadecimal floating point constant (IEEE double representation).
+	//    0x7FF8000000000000
+	if strings.HasPrefix(s, "0x") {
+		bits, err := strconv.ParseUint(s[2:], 16, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse floating point constant %q; %v", s, err)
+		}
+		return NewFloatFromFloat64(typ, math.Float64frombits(bits))
+	}
 
 	// Parse floating point constant.
 	var err error
@@ -147,20 +209,44 @@ func NewFloat(typ types.Type, s string) (*Float, error) {
 	return v, nil
 }
 
+// NewFloatFromFloat64 returns a floating point constant based on the given
+// floating point type and value.
+func NewFloatFromFloat64(typ types.Type, x float64) (*Float, error) {
+	// Verify floating point type.
+	t, ok := typ.(*types.Float)
+	if !ok {
+		return nil, fmt.Errorf("invalid type %q for floating point constant", typ)
+	}
+	size := t.Size()
+	switch size {
+	case 32, 64:
+		// supported size
+	default:
+		// TODO: Add support for half, fp128, x86_fp80 and ppc_fp128.
+		err := fmt.Sprintf("not yet implemented; support for %q floating point constants", t)
+		panic(err)
+	}
+
+	// Verify that there was no precision loss.
+	if size == 32 && !math.IsNaN(x) && float64(float32(x)) != x {
+		s := strconv.FormatFloat(x, 'g', -1, 64)
+		return nil, fmt.Errorf("invalid floating point constant %q for type %q; precision loss", s, t)
+	}
+
+	return &Float{typ: t, x: x}, nil
+}
+
 // Type returns the type of the value.
 func (v *Float) Type() types.Type {
 	return v.typ
 }
 
-// String returns a string representation of the floating point constant using
-// scientific notation (e.g. -2.5e10) for large exponents and regular floating
-// point representation otherwise (e.g. 3.14). The floating point string
-// representation is preceded by the type of the constant, e.g.
-//
-//    float 2.0
-//    double 3.14
-//    double -2.5e10
-func (v *Float) String() string {
+// Ident returns the identifier associated with the floating point constant
+// using scientific notation (e.g. -2.5e10) for large exponents and regular
+// floating point representation otherwise (e.g. 3.14). Special values and values
+// without an exact decimal representation use the hexadecimal representation
+// (e.g. 0x7FF8000000000000).
+func (v *Float) Ident() string {
 	size := v.typ.Size()
 	switch size {
 	case 32, 64:
@@ -171,10 +257,18 @@ func (v *Float) String() string {
 		panic(err)
 	}
 
+	// Use the hexadecimal representation for special values (inf and nan) and
+	// for values which cannot be represented exactly in decimal notation.
+	//    3.14 (float) -> 0x40091EB860000000
+	//    +inf         -> 0x7FF0000000000000
+	s := strconv.FormatFloat(v.x, 'g', -1, size)
+	if y, err := strconv.ParseFloat(s, 64); err != nil || y != v.x || math.IsInf(v.x, 0) {
+		return fmt.Sprintf("0x%016X", math.Float64bits(v.x))
+	}
+
 	// Insert decimal point if not present.
 	//    3e4 -> 3.0e4
 	//    42  -> 42.0
-	s := strconv.FormatFloat(v.x, 'g', -1, size)
 	if !strings.ContainsRune(s, '.') {
 		pos := strings.IndexByte(s, 'e')
 		if pos != -1 {
@@ -188,7 +282,34 @@ func (v *Float) String() string {
 	//    3.0e+4 -> 3.0e4
 	s = strings.Replace(s, "e+", "e", -1)
 
-	return fmt.Sprintf("%s %s", v.Type(), s)
+	return s
+}
+
+// String returns a string representation of the floating point constant using
+// scientific notation (e.g. -2.5e10) for large exponents and regular floating
+// point representation otherwise (e.g. 3.14). The floating point string
+// representation is preceded by the type of the constant, e.g.
+//
+//    float 2.0
+//    double 3.14
+//    double -2.5e10
+func (v *Float) String() string {
+	return fmt.Sprintf("%s %s", v.Type(), v.Ident())
+}
+
+// Float64 returns the value of the floating point constant.
+func (v *Float) Float64() float64 {
+	return v.x
+}
+
+// Equal returns true if u is a floating point constant of the same type and
+// bit pattern, and false otherwise. As such, NaN values of the same bit pattern
+// are equal, while positive and negative zero are not.
+func (v *Float) Equal(u values.Value) bool {
+	if u, ok := u.(*Float); ok {
+		return v.typ.Equal(u.typ) && math.Float64bits(v.x) == math.Float64bits(u.x)
+	}
+	return false
 }
 
 // TODO: Check if global names are used for anything except functions and global
@@ -208,14 +329,116 @@ type Pointer struct {
 
 // TODO: Figure out how to represent pointer constants. Add the necessary fields
 // to the Pointer struct and implement the NewPointer constructor afterwards.
+// Null pointer constants are represented by Null.
 
 // Type returns the type of the value.
 func (v *Pointer) Type() types.Type {
 	return v.typ
 }
 
+// Null represents a null pointer constant.
+//
+// Examples:
+//    null
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#simple-constants
+type Null struct {
+	typ *types.Pointer
+}
+
+// NewNull returns a null pointer constant based on the given pointer type.
+func NewNull(typ types.Type) (*Null, error) {
+	// Verify pointer type.
+	t, ok := typ.(*types.Pointer)
+	if !ok {
+		return nil, fmt.Errorf("invalid type %q for null pointer constant", typ)
+	}
+	return &Null{typ: t}, nil
+}
+
+// Type returns the type of the value.
+func (v *Null) Type() types.Type {
+	return v.typ
+}
+
+// Ident returns the identifier associated with the null pointer constant.
+func (v *Null) Ident() string {
+	return "null"
+}
+
+// String returns a string representation of the null pointer constant,
+// preceded by its type, e.g.
+//
+//    i8* null
+func (v *Null) String() string {
+	return fmt.Sprintf("%s %s", v.Type(), v.Ident())
+}
+
+// Equal returns true if u is a null pointer constant of the same type, and false
+// otherwise.
+func (v *Null) Equal(u values.Value) bool {
+	if u, ok := u.(*Null); ok {
+		return v.typ.Equal(u.typ)
+	}
+	return false
+}
+
+// Undef represents an undefined value, which may be used in place of any
+// constant of the same type.
+//
+// Examples:
+//    undef
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#undefined-values
+type Undef struct {
+	typ types.Type
+}
+
+// NewUndef returns an undefined value of the given type.
+func NewUndef(typ types.Type) (*Undef, error) {
+	// Verify type (any type except void, label, metadata and function).
+	switch typ.(type) {
+	case *types.Int, *types.Float, *types.MMX, *types.Pointer, *types.Vector, *types.Array, *types.Struct, *types.IdentifiedStruct:
+		// valid type
+	default:
+		return nil, fmt.Errorf("invalid type %q for undefined value", typ)
+	}
+	return &Undef{typ: typ}, nil
+}
+
+// Type returns the type of the value.
+func (v *Undef) Type() types.Type {
+	return v.typ
+}
+
+// Ident returns the identifier associated with the undefined value.
+func (v *Undef) Ident() string {
+	return "undef"
+}
+
+// String returns a string representation of the undefined value, preceded by
+// its type, e.g.
+//
+//    {i32, i8} undef
+func (v *Undef) String() string {
+	return fmt.Sprintf("%s %s", v.Type(), v.Ident())
+}
+
+// Equal returns true if u is an undefined value of the same type, and false
+// otherwise.
+func (v *Undef) Equal(u values.Value) bool {
+	if u, ok := u.(*Undef); ok {
+		return v.typ.Equal(u.typ)
+	}
+	return false
+}
+
 // isConst ensures that only constant values can be assigned to the Constant
 // interface.
 func (*Int) isConst()     {}
 func (*Float) isConst()   {}
 func (*Pointer) isConst() {}
+func (*Null) isConst()    {}
+func (*Undef) isConst()   {}
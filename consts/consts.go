@@ -20,6 +20,8 @@ import "github.com/llir/llvm/values"
 //    *consts.Int
 //    *consts.Float
 //    *consts.Pointer
+//    *consts.Null
+//    *consts.Undef
 //    *consts.Vector
 //    *consts.Array
 //    *consts.Struct
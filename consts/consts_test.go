@@ -2,11 +2,13 @@ package consts_test
 
 import (
 	"log"
+	"math"
 	"strings"
 	"testing"
 
 	"github.com/llir/llvm/consts"
 	"github.com/llir/llvm/types"
+	"github.com/llir/llvm/values"
 )
 
 var (
@@ -391,6 +393,41 @@ func TestIntString(t *testing.T) {
 			input: "foo", typ: i64Typ,
 			want: "", err: `unable to parse integer constant "foo"`,
 		},
+		// i=11
+		{
+			input: "-128", typ: i8Typ,
+			want: "i8 -128",
+		},
+		// i=12
+		{
+			input: "128", typ: i8Typ,
+			want: "i8 -128",
+		},
+		// i=13
+		{
+			input: "-170141183460469231731687303715884105728", typ: types.I128,
+			want: "i128 -170141183460469231731687303715884105728",
+		},
+		// i=14
+		{
+			input: "255", typ: i8Typ,
+			want: "i8 -1",
+		},
+		// i=15
+		{
+			input: "256", typ: i8Typ,
+			want: "", err: `integer constant "256" out of range for type "i8"`,
+		},
+		// i=16
+		{
+			input: "-129", typ: i8Typ,
+			want: "", err: `integer constant "-129" out of range for type "i8"`,
+		},
+		// i=17
+		{
+			input: "18446744073709551615", typ: i64Typ,
+			want: "i64 -1",
+		},
 	}
 
 	for i, g := range golden {
@@ -409,6 +446,81 @@ func TestIntString(t *testing.T) {
 	}
 }
 
+func TestIntFromInt64String(t *testing.T) {
+	golden := []struct {
+		x    int64
+		typ  types.Type
+		want string
+		err  string
+	}{
+		// i=0
+		{
+			x: 1, typ: i1Typ,
+			want: "i1 true",
+		},
+		// i=1
+		{
+			x: 0, typ: i1Typ,
+			want: "i1 false",
+		},
+		// i=2
+		{
+			x: -1, typ: i1Typ,
+			want: "i1 true",
+		},
+		// i=3
+		{
+			x: 127, typ: i8Typ,
+			want: "i8 127",
+		},
+		// i=4
+		{
+			x: 128, typ: i8Typ,
+			want: "i8 -128",
+		},
+		// i=5
+		{
+			x: -129, typ: i8Typ,
+			want: "", err: `integer constant "-129" out of range for type "i8"`,
+		},
+		// i=6
+		{
+			x: -137438953472, typ: i64Typ,
+			want: "i64 -137438953472",
+		},
+		// i=7
+		{
+			x: 3, typ: f32Typ,
+			want: "", err: `invalid type "float" for integer constant`,
+		},
+		// i=8
+		{
+			x: 255, typ: i8Typ,
+			want: "i8 -1",
+		},
+		// i=9
+		{
+			x: 2, typ: i1Typ,
+			want: "", err: `invalid integer constant "2" for boolean type`,
+		},
+	}
+
+	for i, g := range golden {
+		v, err := consts.NewIntFromInt64(g.typ, g.x)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		got := v.String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %v, got %v", i, g.want, got)
+		}
+	}
+}
+
 func TestFloatString(t *testing.T) {
 	golden := []struct {
 		input string
@@ -451,6 +563,26 @@ func TestFloatString(t *testing.T) {
 			input: "foo", typ: f32Typ,
 			want: "", err: `unable to parse floating point constant "foo"`,
 		},
+		// i=7
+		{
+			input: "0x3FF0000000000000", typ: f64Typ,
+			want: "double 1.0",
+		},
+		// i=8
+		{
+			input: "0x40091EB860000000", typ: f32Typ,
+			want: "float 0x40091EB860000000",
+		},
+		// i=9
+		{
+			input: "0x40091EB851EB851F", typ: f32Typ,
+			want: "", err: `invalid floating point constant "3.14" for type "float"; precision loss`,
+		},
+		// i=10
+		{
+			input: "0xfoo", typ: f64Typ,
+			want: "", err: `unable to parse floating point constant "0xfoo"`,
+		},
 		//{want: "3.14159265358979323846264338327950288419716939937510", input: "3.14159265358979323846264338327950288419716939937510"},
 	}
 
@@ -470,6 +602,160 @@ func TestFloatString(t *testing.T) {
 	}
 }
 
+func TestFloatFromFloat64String(t *testing.T) {
+	golden := []struct {
+		x    float64
+		typ  types.Type
+		want string
+		err  string
+	}{
+		// i=0
+		{
+			x: 1.0, typ: f64Typ,
+			want: "double 1.0",
+		},
+		// i=1
+		{
+			x: 0.5, typ: f32Typ,
+			want: "float 0.5",
+		},
+		// i=2
+		{
+			x: math.Float64frombits(0x7FF8000000000000), typ: f64Typ, // quiet NaN
+			want: "double 0x7FF8000000000000",
+		},
+		// i=3
+		{
+			x: math.Inf(1), typ: f64Typ,
+			want: "double 0x7FF0000000000000",
+		},
+		// i=4
+		{
+			x: math.Inf(-1), typ: f32Typ,
+			want: "float 0xFFF0000000000000",
+		},
+		// i=5
+		{
+			x: float64(float32(3.14)), typ: f32Typ,
+			want: "float 0x40091EB860000000",
+		},
+		// i=6
+		{
+			x: 3.14, typ: f32Typ,
+			want: "", err: `invalid floating point constant "3.14" for type "float"; precision loss`,
+		},
+		// i=7
+		{
+			x: 12, typ: i32Typ,
+			want: "", err: `invalid type "i32" for floating point constant`,
+		},
+	}
+
+	for i, g := range golden {
+		v, err := consts.NewFloatFromFloat64(g.typ, g.x)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		got := v.String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %v, got %v", i, g.want, got)
+		}
+	}
+}
+
+func TestNullString(t *testing.T) {
+	i8PtrTyp, err := types.NewPointer(i8Typ)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden := []struct {
+		typ  types.Type
+		want string
+		err  string
+	}{
+		// i=0
+		{
+			typ:  i8PtrTyp,
+			want: "i8* null",
+		},
+		// i=1
+		{
+			typ:  i32Typ,
+			want: "", err: `invalid type "i32" for null pointer constant`,
+		},
+	}
+
+	for i, g := range golden {
+		v, err := consts.NewNull(g.typ)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		if !v.Type().Equal(g.typ) {
+			t.Errorf("i=%d: type mismatch; expected %v, got %v", i, g.typ, v.Type())
+		}
+		got := v.String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %v, got %v", i, g.want, got)
+		}
+	}
+}
+
+func TestUndefString(t *testing.T) {
+	golden := []struct {
+		typ  types.Type
+		want string
+		err  string
+	}{
+		// i=0
+		{
+			typ:  i32Typ,
+			want: "i32 undef",
+		},
+		// i=1
+		{
+			typ:  i32i8StructTyp,
+			want: "{i32, i8} undef",
+		},
+		// i=2
+		{
+			typ:  i32x2VecTyp,
+			want: "<2 x i32> undef",
+		},
+		// i=3
+		{
+			typ:  types.NewLabel(),
+			want: "", err: `invalid type "label" for undefined value`,
+		},
+	}
+
+	for i, g := range golden {
+		v, err := consts.NewUndef(g.typ)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		if !v.Type().Equal(g.typ) {
+			t.Errorf("i=%d: type mismatch; expected %v, got %v", i, g.typ, v.Type())
+		}
+		got := v.String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %v, got %v", i, g.want, got)
+		}
+	}
+}
+
 func TestVectorString(t *testing.T) {
 	golden := []struct {
 		elems []consts.Constant
@@ -570,6 +856,83 @@ func TestArrayString(t *testing.T) {
 	}
 }
 
+func TestCharArrayString(t *testing.T) {
+	golden := []struct {
+		input string
+		want  string
+	}{
+		// i=0
+		{
+			input: "foo",
+			want:  `[3 x i8] c"foo"`,
+		},
+		// i=1
+		{
+			input: "hello world\n\x00",
+			want:  `[13 x i8] c"hello world\0A\00"`,
+		},
+		// i=2
+		{
+			input: `say "hi" \o/`,
+			want:  `[12 x i8] c"say \22hi\22 \5Co/"`,
+		},
+		// i=3
+		{
+			input: "\xFF",
+			want:  `[1 x i8] c"\FF"`,
+		},
+		// i=4
+		{
+			input: "",
+			want:  `[0 x i8] c""`,
+		},
+	}
+
+	for i, g := range golden {
+		v := consts.NewCharArray(g.input)
+		got := v.String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %v, got %v", i, g.want, got)
+		}
+	}
+}
+
+func TestCStringString(t *testing.T) {
+	golden := []struct {
+		input string
+		want  string
+	}{
+		// i=0
+		{
+			input: "hello",
+			want:  `[6 x i8] c"hello\00"`,
+		},
+		// i=1
+		{
+			input: "line 1\nline 2\n",
+			want:  `[15 x i8] c"line 1\0Aline 2\0A\00"`,
+		},
+		// i=2
+		{
+			input: "foo\x00bar",
+			want:  `[8 x i8] c"foo\00bar\00"`,
+		},
+		// i=3
+		{
+			input: "",
+			want:  `[1 x i8] c"\00"`,
+		},
+	}
+
+	for i, g := range golden {
+		v := consts.NewCString(g.input)
+		got := v.String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %v, got %v", i, g.want, got)
+		}
+	}
+}
+
 func TestStructString(t *testing.T) {
 	golden := []struct {
 		fields []consts.Constant
@@ -1106,6 +1469,110 @@ func TestIntToFloatString(t *testing.T) {
 	}
 }
 
+func TestPtrConversionString(t *testing.T) {
+	i8PtrTyp, err := types.NewPointer(types.I8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i32PtrTyp, err := types.NewPointer(i32Typ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	null, err := consts.NewNull(i8PtrTyp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i64FortyTwo, err := consts.NewInt(i64Typ, "42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ptrToInt, err := consts.NewPtrToInt(null, i64Typ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	intToPtr, err := consts.NewIntToPtr(i64FortyTwo, i8PtrTyp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bitCast, err := consts.NewBitCast(null, i32PtrTyp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := []struct {
+		v    consts.Constant
+		want string
+	}{
+		// i=0
+		{v: ptrToInt, want: "i64 ptrtoint(i8* null to i64)"},
+		// i=1
+		{v: intToPtr, want: "i8* inttoptr(i64 42 to i8*)"},
+		// i=2
+		{v: bitCast, want: "i32* bitcast(i8* null to i32*)"},
+	}
+
+	for i, g := range golden {
+		if got := g.v.String(); got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %v, got %v", i, g.want, got)
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+	i32Five, err := consts.NewInt(i32Typ, "5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	i32FiveCopy, err := consts.NewIntFromInt64(i32Typ, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i64Five, err := consts.NewInt(i64Typ, "5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	i32Trunc, err := consts.NewIntTrunc(i32Fifteen, i3Typ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i32TruncCopy, err := consts.NewIntTrunc(i32Fifteen, i3Typ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := []struct {
+		a, b values.Value
+		want bool
+	}{
+		// i=0
+		{a: i32Five, b: i32FiveCopy, want: true},
+		// i=1
+		{a: i32Five, b: i64Five, want: false},
+		// i=2
+		{a: i32Five, b: i32FortyTwo, want: false},
+		// i=3
+		{a: f32Four, b: f32Four, want: true},
+		// i=4
+		{a: f32Four, b: f64Four, want: false},
+		// i=5
+		{a: i32Four, b: f32Four, want: false},
+		// i=6
+		{a: i32i8FourThree, b: i32i8FourThree, want: true},
+		// i=7
+		{a: i32i8FourThree, b: i32i8ThreeFour, want: false},
+		// i=8
+		{a: f32x2VecThreeFour, b: f32x2VecMinusThreeFour, want: false},
+		// i=9
+		{a: i32Trunc, b: i32TruncCopy, want: true},
+		// i=10
+		{a: i32Trunc, b: i32Fifteen, want: false},
+	}
+
+	for i, g := range golden {
+		if got := values.Equal(g.a, g.b); got != g.want {
+			t.Errorf("i=%d: expected %v, got %v", i, g.want, got)
+		}
+	}
+}
+
 // sameError returns true if err is represented by the string s, and false
 // otherwise. Some error messages contains suffixes from external functions,
 // e.g. the strconv error in:
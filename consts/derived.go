@@ -8,8 +8,10 @@ package consts
 import (
 	"bytes"
 	"fmt"
+	"math/big"
 
 	"github.com/llir/llvm/types"
+	"github.com/llir/llvm/values"
 )
 
 // Vector represents a vector constant which is a vetor containing only
@@ -56,11 +58,10 @@ func (v *Vector) Type() types.Type {
 	return v.typ
 }
 
-// String returns a string representation of the vector. The vector string
-// representation is preceded by the type of the constant, e.g.
+// Ident returns the identifier associated with the vector, e.g.
 //
-//    <2 x i32> <i32 42, i32 -13>
-func (v *Vector) String() string {
+//    <i32 42, i32 -13>
+func (v *Vector) Ident() string {
 	buf := new(bytes.Buffer)
 	for i, elem := range v.elems {
 		if i > 0 {
@@ -69,7 +70,24 @@ func (v *Vector) String() string {
 		buf.WriteString(elem.String())
 	}
 
-	return fmt.Sprintf("%s <%s>", v.Type(), buf)
+	return fmt.Sprintf("<%s>", buf)
+}
+
+// String returns a string representation of the vector. The vector string
+// representation is preceded by the type of the constant, e.g.
+//
+//    <2 x i32> <i32 42, i32 -13>
+func (v *Vector) String() string {
+	return fmt.Sprintf("%s %s", v.Type(), v.Ident())
+}
+
+// Equal returns true if u is a vector constant of the same type and with equal
+// elements, and false otherwise.
+func (v *Vector) Equal(u values.Value) bool {
+	if u, ok := u.(*Vector); ok {
+		return v.typ.Equal(u.typ) && equalConsts(v.elems, u.elems)
+	}
+	return false
 }
 
 // Array represents an array constant which is an array containing only
@@ -91,6 +109,8 @@ type Array struct {
 	// []Constant when applicable). Strive for correctness and simplicity first,
 	// optimize later. The same goes for Vector and maybe Struct.
 	elems []Constant
+	// Specifies if the array is represented as a character array (e.g. c"foo").
+	charArray bool
 }
 
 // NewArray returns an array constant based on the given array type and array
@@ -119,16 +139,43 @@ func NewArray(typ types.Type, elems []Constant) (*Array, error) {
 	return v, nil
 }
 
+// NewCharArray returns a character array constant of type [N x i8] based on the
+// bytes of the given string, where N is the length of s.
+func NewCharArray(s string) *Array {
+	typ, err := types.NewArray(types.I8, len(s))
+	if err != nil {
+		panic(fmt.Sprintf("unable to create character array type; %v", err))
+	}
+	elems := make([]Constant, len(s))
+	for i := 0; i < len(s); i++ {
+		// Bytes are stored as signed 8-bit integers.
+		elems[i] = &Int{typ: types.I8, x: big.NewInt(int64(int8(s[i])))}
+	}
+	return &Array{typ: typ, elems: elems, charArray: true}
+}
+
+// NewCString returns a character array constant of type [N x i8] holding the
+// bytes of the given string followed by a terminating NUL byte, where N is the
+// length of s plus one, e.g.
+//
+//    [6 x i8] c"hello\00"
+func NewCString(s string) *Array {
+	return NewCharArray(s + "\x00")
+}
+
 // Type returns the type of the value.
 func (v *Array) Type() types.Type {
 	return v.typ
 }
 
-// String returns a string representation of the array. The array string
-// representation is preceded by the type of the constant, e.g.
+// Ident returns the identifier associated with the array, e.g.
 //
-//    [2 x i32] [i32 42, i32 -13]
-func (v *Array) String() string {
+//    [i32 42, i32 -13]
+//    c"hello world\0A\00"
+func (v *Array) Ident() string {
+	if v.charArray {
+		return fmt.Sprintf(`c"%s"`, v.escape())
+	}
 	buf := new(bytes.Buffer)
 	for i, elem := range v.elems {
 		if i > 0 {
@@ -137,7 +184,56 @@ func (v *Array) String() string {
 		buf.WriteString(elem.String())
 	}
 
-	return fmt.Sprintf("%s [%s]", v.Type(), buf)
+	return fmt.Sprintf("[%s]", buf)
+}
+
+// String returns a string representation of the array. The array string
+// representation is preceded by the type of the constant, e.g.
+//
+//    [2 x i32] [i32 42, i32 -13]
+//    [13 x i8] c"hello world\0A\00"
+func (v *Array) String() string {
+	return fmt.Sprintf("%s %s", v.Type(), v.Ident())
+}
+
+// Equal returns true if u is an array constant of the same type and with equal
+// elements, and false otherwise.
+func (v *Array) Equal(u values.Value) bool {
+	if u, ok := u.(*Array); ok {
+		return v.typ.Equal(u.typ) && equalConsts(v.elems, u.elems)
+	}
+	return false
+}
+
+// escape returns the contents of the character array with non-printable
+// characters, double quotes and backslashes escaped using the \XX hexadecimal
+// notation.
+func (v *Array) escape() string {
+	buf := make([]byte, len(v.elems))
+	for i, elem := range v.elems {
+		buf[i] = byte(elem.(*Int).x.Int64())
+	}
+	return Escape(string(buf))
+}
+
+// Escape returns s with non-printable characters, double quotes and backslashes
+// escaped using the \XX hexadecimal notation of LLVM string literals, e.g.
+//
+//    hello world\0A\00
+func Escape(s string) string {
+	const hextable = "0123456789ABCDEF"
+	buf := new(bytes.Buffer)
+	for i := 0; i < len(s); i++ {
+		b := s[i]
+		if b < ' ' || b > '~' || b == '"' || b == '\\' {
+			buf.WriteByte('\\')
+			buf.WriteByte(hextable[b>>4])
+			buf.WriteByte(hextable[b&0x0F])
+			continue
+		}
+		buf.WriteByte(b)
+	}
+	return buf.String()
 }
 
 // Struct represents a structure constant which is a structure containing only
@@ -186,11 +282,10 @@ func (v *Struct) Type() types.Type {
 	return v.typ
 }
 
-// String returns a string representation of the structure. The structure string
-// representation is preceded by the type of the constant, e.g.
+// Ident returns the identifier associated with the structure, e.g.
 //
-//    {i32, i8} {i32 -13, i8 3}
-func (v *Struct) String() string {
+//    {i32 -13, i8 3}
+func (v *Struct) Ident() string {
 	buf := new(bytes.Buffer)
 	for i, field := range v.fields {
 		if i > 0 {
@@ -199,7 +294,24 @@ func (v *Struct) String() string {
 		buf.WriteString(field.String())
 	}
 
-	return fmt.Sprintf("%s {%s}", v.Type(), buf)
+	return fmt.Sprintf("{%s}", buf)
+}
+
+// String returns a string representation of the structure. The structure string
+// representation is preceded by the type of the constant, e.g.
+//
+//    {i32, i8} {i32 -13, i8 3}
+func (v *Struct) String() string {
+	return fmt.Sprintf("%s %s", v.Type(), v.Ident())
+}
+
+// Equal returns true if u is a structure constant of the same type and with equal
+// fields, and false otherwise.
+func (v *Struct) Equal(u values.Value) bool {
+	if u, ok := u.(*Struct); ok {
+		return v.typ.Equal(u.typ) && equalConsts(v.fields, u.fields)
+	}
+	return false
 }
 
 // isConst ensures that only constant values can be assigned to the Constant
@@ -207,3 +319,17 @@ func (v *Struct) String() string {
 func (*Vector) isConst() {}
 func (*Array) isConst()  {}
 func (*Struct) isConst() {}
+
+// equalConsts returns true if the given lists of constants are pairwise equal,
+// and false otherwise.
+func equalConsts(a, b []Constant) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !values.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -1,6 +1,8 @@
 package consts
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 
 	"github.com/llir/llvm/types"
@@ -21,6 +23,10 @@ import (
 //    *consts.FloatToInt
 //    *consts.UintToFloat
 //    *consts.IntToFloat
+//    *consts.GetElementPtr
+//    *consts.PtrToInt
+//    *consts.IntToPtr
+//    *consts.BitCast
 //
 // References:
 //    http://llvm.org/docs/LangRef.html#constant-expressions
@@ -81,13 +87,29 @@ func (exp *IntTrunc) Calc() Constant {
 	panic("not yet implemented.")
 }
 
+// Ident returns the identifier associated with the constant expression, e.g.
+//
+//    trunc(i32 15 to i3)
+func (exp *IntTrunc) Ident() string {
+	return fmt.Sprintf("trunc(%s to %s)", exp.orig, exp.to)
+}
+
 // String returns a string representation of the integer truncation expression.
 // The expression string representation is preceded by the type of the constant,
 // e.g.
 //
 //    i3 trunc(i32 15 to i3)
 func (exp *IntTrunc) String() string {
-	return fmt.Sprintf("%s trunc(%s to %s)", exp.Type(), exp.orig, exp.to)
+	return fmt.Sprintf("%s %s", exp.Type(), exp.Ident())
+}
+
+// Equal returns true if u is an integer truncation of an equal constant to the
+// same type, and false otherwise.
+func (exp *IntTrunc) Equal(u values.Value) bool {
+	if u, ok := u.(*IntTrunc); ok {
+		return values.Equal(exp.orig, u.orig) && exp.to.Equal(u.to)
+	}
+	return false
 }
 
 // IntZeroExt is a constant expression which zero extends an integer constant to
@@ -140,13 +162,29 @@ func (exp *IntZeroExt) Calc() Constant {
 	panic("not yet implemented.")
 }
 
+// Ident returns the identifier associated with the constant expression, e.g.
+//
+//    zext(i1 true to i5)
+func (exp *IntZeroExt) Ident() string {
+	return fmt.Sprintf("zext(%s to %s)", exp.orig, exp.to)
+}
+
 // String returns a string representation of the integer zero extension
 // expression. The expression string representation is preceded by the type of
 // the constant, e.g.
 //
 //    i5 zext(i1 true to i5)
 func (exp *IntZeroExt) String() string {
-	return fmt.Sprintf("%s zext(%s to %s)", exp.Type(), exp.orig, exp.to)
+	return fmt.Sprintf("%s %s", exp.Type(), exp.Ident())
+}
+
+// Equal returns true if u is an integer zero extension of an equal constant to
+// the same type, and false otherwise.
+func (exp *IntZeroExt) Equal(u values.Value) bool {
+	if u, ok := u.(*IntZeroExt); ok {
+		return values.Equal(exp.orig, u.orig) && exp.to.Equal(u.to)
+	}
+	return false
 }
 
 // IntSignExt is a constant expression which sign extends an integer constant to
@@ -199,13 +237,29 @@ func (exp *IntSignExt) Calc() Constant {
 	panic("not yet implemented.")
 }
 
+// Ident returns the identifier associated with the constant expression, e.g.
+//
+//    sext(i1 true to i5)
+func (exp *IntSignExt) Ident() string {
+	return fmt.Sprintf("sext(%s to %s)", exp.orig, exp.to)
+}
+
 // String returns a string representation of the integer sign extension
 // expression. The expression string representation is preceded by the type of
 // the constant, e.g.
 //
 //    i5 sext(i1 true to i5)
 func (exp *IntSignExt) String() string {
-	return fmt.Sprintf("%s sext(%s to %s)", exp.Type(), exp.orig, exp.to)
+	return fmt.Sprintf("%s %s", exp.Type(), exp.Ident())
+}
+
+// Equal returns true if u is an integer sign extension of an equal constant to
+// the same type, and false otherwise.
+func (exp *IntSignExt) Equal(u values.Value) bool {
+	if u, ok := u.(*IntSignExt); ok {
+		return values.Equal(exp.orig, u.orig) && exp.to.Equal(u.to)
+	}
+	return false
 }
 
 // FloatTrunc is a constant expression which truncates a floating point constant
@@ -261,13 +315,29 @@ func (exp *FloatTrunc) Calc() Constant {
 	panic("not yet implemented.")
 }
 
+// Ident returns the identifier associated with the constant expression, e.g.
+//
+//    fptrunc(double 4.0 to float)
+func (exp *FloatTrunc) Ident() string {
+	return fmt.Sprintf("fptrunc(%s to %s)", exp.orig, exp.to)
+}
+
 // String returns a string representation of the floating point truncation
 // expression. The expression string representation is preceded by the type of
 // the constant, e.g.
 //
 //    float fptrunc(double 4.0 to float)
 func (exp *FloatTrunc) String() string {
-	return fmt.Sprintf("%s fptrunc(%s to %s)", exp.Type(), exp.orig, exp.to)
+	return fmt.Sprintf("%s %s", exp.Type(), exp.Ident())
+}
+
+// Equal returns true if u is a floating point truncation of an equal constant
+// to the same type, and false otherwise.
+func (exp *FloatTrunc) Equal(u values.Value) bool {
+	if u, ok := u.(*FloatTrunc); ok {
+		return values.Equal(exp.orig, u.orig) && exp.to.Equal(u.to)
+	}
+	return false
 }
 
 // FloatExt is a constant expression which extends a floating point constant to
@@ -323,13 +393,29 @@ func (exp *FloatExt) Calc() Constant {
 	panic("not yet implemented.")
 }
 
+// Ident returns the identifier associated with the constant expression, e.g.
+//
+//    fpext(float 4.0 to double)
+func (exp *FloatExt) Ident() string {
+	return fmt.Sprintf("fpext(%s to %s)", exp.orig, exp.to)
+}
+
 // String returns a string representation of the floating point extension
 // expression. The expression string representation is preceded by the type of
 // the constant, e.g.
 //
 //    double fpext(float 4.0 to double)
 func (exp *FloatExt) String() string {
-	return fmt.Sprintf("%s fpext(%s to %s)", exp.Type(), exp.orig, exp.to)
+	return fmt.Sprintf("%s %s", exp.Type(), exp.Ident())
+}
+
+// Equal returns true if u is a floating point extension of an equal constant to
+// the same type, and false otherwise.
+func (exp *FloatExt) Equal(u values.Value) bool {
+	if u, ok := u.(*FloatExt); ok {
+		return values.Equal(exp.orig, u.orig) && exp.to.Equal(u.to)
+	}
+	return false
 }
 
 // FloatToUint is a constant expression which converts a floating point constant
@@ -382,6 +468,14 @@ func (exp *FloatToUint) Calc() Constant {
 	panic("not yet implemented.")
 }
 
+// Ident returns the identifier associated with the constant expression, e.g.
+//
+//    fptoui(float 4.0 to i32)
+//    fptoui(<2 x float> <float 3.0, float 4.0> to <2 x i32>)
+func (exp *FloatToUint) Ident() string {
+	return fmt.Sprintf("fptoui(%s to %s)", exp.orig, exp.to)
+}
+
 // String returns a string representation of the constant expression which
 // converts a floating point constant (or constant vector) to the corresponding
 // unsigned integer constant (or constant vector). The expression string
@@ -390,7 +484,16 @@ func (exp *FloatToUint) Calc() Constant {
 //    i32 fptoui(float 4.0 to i32)
 //    <2 x i32> fptoui(<2 x float> <float 3.0, float 4.0> to <2 x i32>)
 func (exp *FloatToUint) String() string {
-	return fmt.Sprintf("%s fptoui(%s to %s)", exp.Type(), exp.orig, exp.to)
+	return fmt.Sprintf("%s %s", exp.Type(), exp.Ident())
+}
+
+// Equal returns true if u is a floating point to unsigned integer conversion of
+// an equal constant to the same type, and false otherwise.
+func (exp *FloatToUint) Equal(u values.Value) bool {
+	if u, ok := u.(*FloatToUint); ok {
+		return values.Equal(exp.orig, u.orig) && exp.to.Equal(u.to)
+	}
+	return false
 }
 
 // FloatToInt is a constant expression which converts a floating point constant
@@ -443,6 +546,14 @@ func (exp *FloatToInt) Calc() Constant {
 	panic("not yet implemented.")
 }
 
+// Ident returns the identifier associated with the constant expression, e.g.
+//
+//    fptosi(float -4.0 to i32)
+//    fptosi(<2 x float> <float -3.0, float 4.0> to <2 x i32>)
+func (exp *FloatToInt) Ident() string {
+	return fmt.Sprintf("fptosi(%s to %s)", exp.orig, exp.to)
+}
+
 // String returns a string representation of the constant expression which
 // converts a floating point constant (or constant vector) to the corresponding
 // signed integer constant (or constant vector). The expression string
@@ -451,7 +562,16 @@ func (exp *FloatToInt) Calc() Constant {
 //    i32 fptosi(float -4.0 to i32)
 //    <2 x i32> fptosi(<2 x float> <float -3.0, float 4.0> to <2 x i32>)
 func (exp *FloatToInt) String() string {
-	return fmt.Sprintf("%s fptosi(%s to %s)", exp.Type(), exp.orig, exp.to)
+	return fmt.Sprintf("%s %s", exp.Type(), exp.Ident())
+}
+
+// Equal returns true if u is a floating point to signed integer conversion of
+// an equal constant to the same type, and false otherwise.
+func (exp *FloatToInt) Equal(u values.Value) bool {
+	if u, ok := u.(*FloatToInt); ok {
+		return values.Equal(exp.orig, u.orig) && exp.to.Equal(u.to)
+	}
+	return false
 }
 
 // UintToFloat is a constant expression which converts an unsigned integer
@@ -504,6 +624,14 @@ func (exp *UintToFloat) Calc() Constant {
 	panic("not yet implemented.")
 }
 
+// Ident returns the identifier associated with the constant expression, e.g.
+//
+//    uitofp(i32 4 to float)
+//    uitofp(<2 x i32> <i32 3, i32 42> to <2 x float>)
+func (exp *UintToFloat) Ident() string {
+	return fmt.Sprintf("uitofp(%s to %s)", exp.orig, exp.to)
+}
+
 // String returns a string representation of the constant expression which
 // converts an unsigned integer constant (or constant vector) to the
 // corresponding floating point constant (or constant vector). The expression
@@ -512,7 +640,16 @@ func (exp *UintToFloat) Calc() Constant {
 //    float uitofp(i32 4 to float)
 //    <2 x float> uitofp(<2 x i32> <i32 3, i32 42> to <2 x float>)
 func (exp *UintToFloat) String() string {
-	return fmt.Sprintf("%s uitofp(%s to %s)", exp.Type(), exp.orig, exp.to)
+	return fmt.Sprintf("%s %s", exp.Type(), exp.Ident())
+}
+
+// Equal returns true if u is an unsigned integer to floating point conversion
+// of an equal constant to the same type, and false otherwise.
+func (exp *UintToFloat) Equal(u values.Value) bool {
+	if u, ok := u.(*UintToFloat); ok {
+		return values.Equal(exp.orig, u.orig) && exp.to.Equal(u.to)
+	}
+	return false
 }
 
 // IntToFloat is a constant expression which converts a signed integer constant
@@ -565,6 +702,14 @@ func (exp *IntToFloat) Calc() Constant {
 	panic("not yet implemented.")
 }
 
+// Ident returns the identifier associated with the constant expression, e.g.
+//
+//    sitofp(i32 -4 to float)
+//    sitofp(<2 x i32> <i32 -3, i32 15> to <2 x float>)
+func (exp *IntToFloat) Ident() string {
+	return fmt.Sprintf("sitofp(%s to %s)", exp.orig, exp.to)
+}
+
 // String returns a string representation of the constant expression which
 // converts a signed integer constant (or constant vector) to the corresponding
 // floating point constant (or constant vector). The expression string
@@ -573,15 +718,422 @@ func (exp *IntToFloat) Calc() Constant {
 //    float sitofp(i32 -4 to float)
 //    <2 x float> sitofp(<2 x i32> <i32 -3, i32 15> to <2 x float>)
 func (exp *IntToFloat) String() string {
-	return fmt.Sprintf("%s sitofp(%s to %s)", exp.Type(), exp.orig, exp.to)
+	return fmt.Sprintf("%s %s", exp.Type(), exp.Ident())
+}
+
+// Equal returns true if u is a signed integer to floating point conversion of
+// an equal constant to the same type, and false otherwise.
+func (exp *IntToFloat) Equal(u values.Value) bool {
+	if u, ok := u.(*IntToFloat); ok {
+		return values.Equal(exp.orig, u.orig) && exp.to.Equal(u.to)
+	}
+	return false
+}
+
+// GetElementPtr is a constant expression which computes the address of an
+// element of an aggregate data structure, based on a constant pointer (e.g. a
+// global variable) and a list of constant indices.
+//
+// Examples:
+//    getelementptr inbounds ([6 x i8], [6 x i8]* @str, i32 0, i32 0)   ; yields i8*
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#constant-expressions
+type GetElementPtr struct {
+	// Pointer to the aggregate data structure.
+	base values.Value
+	// Element indices.
+	indices []Constant
+	// Specifies whether the result is a poison value if the address is outside
+	// of the allocated object pointed to by base.
+	inbounds bool
+	// Result pointer type.
+	typ *types.Pointer
+}
+
+// NewGetElementPtr returns a constant expression which computes the address of
+// the element addressed by the given indices of the aggregate data structure
+// pointed to by base. The first index steps through the pointer, while the
+// remaining indices step into the elements of the aggregate.
+func NewGetElementPtr(base values.Value, indices []Constant, inbounds bool) (*GetElementPtr, error) {
+	// Verify type of base pointer.
+	ptr, ok := base.Type().(*types.Pointer)
+	if !ok {
+		return nil, fmt.Errorf("invalid getelementptr; expected pointer for base, got %q", base.Type())
+	}
+	if len(indices) == 0 {
+		return nil, errors.New("invalid getelementptr; expected at least one index")
+	}
+
+	// Compute the type of the addressed element.
+	type Fielder interface {
+		FieldAt(index int) (types.Type, error)
+	}
+	t := ptr.Elem()
+	for i, index := range indices {
+		if !types.IsInt(index.Type()) {
+			return nil, fmt.Errorf("invalid getelementptr index type; expected integer, got %q", index.Type())
+		}
+		if i == 0 {
+			// The first index steps through the pointer and does not change the
+			// type of the addressed element.
+			continue
+		}
+		switch typ := t.(type) {
+		case Fielder:
+			// Structure fields are indexed by constant integers.
+			x, ok := index.(*Int)
+			if !ok {
+				return nil, fmt.Errorf("invalid getelementptr structure index; expected integer constant, got %v", index)
+			}
+			field, err := typ.FieldAt(int(x.x.Int64()))
+			if err != nil {
+				return nil, fmt.Errorf("invalid getelementptr; %v", err)
+			}
+			t = field
+		case *types.Array:
+			t = typ.Elem()
+		case *types.Vector:
+			t = typ.Elem()
+		default:
+			return nil, fmt.Errorf("invalid getelementptr; unable to index into non-aggregate type %q", t)
+		}
+	}
+	typ, err := types.NewPointerInAddrSpace(t, ptr.AddrSpace())
+	if err != nil {
+		return nil, fmt.Errorf("invalid getelementptr; %v", err)
+	}
+
+	return &GetElementPtr{base: base, indices: indices, inbounds: inbounds, typ: typ}, nil
+}
+
+// Type returns the type of the value.
+func (exp *GetElementPtr) Type() types.Type {
+	return exp.typ
+}
+
+// Calc calculates and returns a constant which is equivalent to the constant
+// expression. As the address depends on the memory layout, the expression is
+// its own equivalent constant.
+func (exp *GetElementPtr) Calc() Constant {
+	return exp
+}
+
+// Ident returns the identifier associated with the constant expression, e.g.
+//
+//    getelementptr inbounds ([6 x i8], [6 x i8]* @str, i32 0, i32 0)
+func (exp *GetElementPtr) Ident() string {
+	buf := new(bytes.Buffer)
+	buf.WriteString("getelementptr ")
+	if exp.inbounds {
+		buf.WriteString("inbounds ")
+	}
+	elem := exp.base.Type().(*types.Pointer).Elem()
+	fmt.Fprintf(buf, "(%v, %v %s", elem, exp.base.Type(), exp.base.Ident())
+	for _, index := range exp.indices {
+		fmt.Fprintf(buf, ", %v", index)
+	}
+	buf.WriteString(")")
+	return buf.String()
+}
+
+// String returns a string representation of the getelementptr expression. The
+// expression string representation is preceded by the type of the constant,
+// e.g.
+//
+//    i8* getelementptr inbounds ([6 x i8], [6 x i8]* @str, i32 0, i32 0)
+func (exp *GetElementPtr) String() string {
+	return fmt.Sprintf("%s %s", exp.Type(), exp.Ident())
+}
+
+// Equal returns true if u is a getelementptr expression of an equal base
+// pointer and indices, and false otherwise.
+func (exp *GetElementPtr) Equal(u values.Value) bool {
+	if u, ok := u.(*GetElementPtr); ok {
+		return values.Equal(exp.base, u.base) && equalConsts(exp.indices, u.indices) && exp.inbounds == u.inbounds
+	}
+	return false
+}
+
+// PtrToInt is a constant expression which converts a pointer constant (or
+// constant vector of pointers) to the corresponding integer constant (or
+// constant vector).
+//
+// Examples:
+//    ptrtoint(i8* @g to i64)
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#constant-expressions
+type PtrToInt struct {
+	// Original pointer value (or vector), such as a global variable.
+	orig values.Value
+	// New integer type (or vector).
+	to types.Type
+}
+
+// NewPtrToInt returns a constant expression which converts the pointer constant
+// (or constant vector of pointers) orig to the integer type (or vector of
+// integers) to.
+func NewPtrToInt(orig values.Value, to types.Type) (*PtrToInt, error) {
+	// Verify type of original pointer constant (or constant vector).
+	if !types.IsPointers(orig.Type()) {
+		return nil, fmt.Errorf("invalid pointer to integer conversion; expected pointer constant (or constant vector) for orig, got %q", orig.Type())
+	}
+
+	// Verify target type.
+	if !types.IsInts(to) {
+		return nil, fmt.Errorf("invalid pointer to integer conversion; expected integer (or integer vector) target type, got %q", to)
+	}
+
+	// Verify that both are either basic types or vectors of the same length.
+	if !types.SameLength(orig.Type(), to) {
+		return nil, fmt.Errorf("invalid pointer to integer conversion; cannot convert from %q to %q", orig.Type(), to)
+	}
+
+	return &PtrToInt{orig: orig, to: to}, nil
+}
+
+// Type returns the type of the value.
+func (exp *PtrToInt) Type() types.Type {
+	return exp.to
+}
+
+// Calc calculates and returns a constant which is equivalent to the constant
+// expression. As the address depends on the memory layout, the expression is
+// its own equivalent constant.
+func (exp *PtrToInt) Calc() Constant {
+	return exp
+}
+
+// Ident returns the identifier associated with the constant expression, e.g.
+//
+//    ptrtoint(i8* @g to i64)
+func (exp *PtrToInt) Ident() string {
+	return fmt.Sprintf("ptrtoint(%v %s to %v)", exp.orig.Type(), exp.orig.Ident(), exp.to)
+}
+
+// String returns a string representation of the pointer to integer conversion
+// expression. The expression string representation is preceded by the type of
+// the constant, e.g.
+//
+//    i64 ptrtoint(i8* @g to i64)
+func (exp *PtrToInt) String() string {
+	return fmt.Sprintf("%s %s", exp.Type(), exp.Ident())
+}
+
+// Equal returns true if u is a pointer to integer conversion of an equal
+// constant to the same type, and false otherwise.
+func (exp *PtrToInt) Equal(u values.Value) bool {
+	if u, ok := u.(*PtrToInt); ok {
+		return values.Equal(exp.orig, u.orig) && exp.to.Equal(u.to)
+	}
+	return false
+}
+
+// IntToPtr is a constant expression which converts an integer constant (or
+// constant vector) to the corresponding pointer constant (or constant vector of
+// pointers).
+//
+// Examples:
+//    inttoptr(i64 42 to i8*)
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#constant-expressions
+type IntToPtr struct {
+	// Original integer value (or vector).
+	orig values.Value
+	// New pointer type (or vector).
+	to types.Type
+}
+
+// NewIntToPtr returns a constant expression which converts the integer constant
+// (or constant vector) orig to the pointer type (or vector of pointers) to.
+func NewIntToPtr(orig Constant, to types.Type) (*IntToPtr, error) {
+	// Verify type of original integer constant (or constant vector).
+	if !types.IsInts(orig.Type()) {
+		return nil, fmt.Errorf("invalid integer to pointer conversion; expected integer constant (or constant vector) for orig, got %q", orig.Type())
+	}
+
+	// Verify target type.
+	if !types.IsPointers(to) {
+		return nil, fmt.Errorf("invalid integer to pointer conversion; expected pointer (or pointer vector) target type, got %q", to)
+	}
+
+	// Verify that both are either basic types or vectors of the same length.
+	if !types.SameLength(orig.Type(), to) {
+		return nil, fmt.Errorf("invalid integer to pointer conversion; cannot convert from %q to %q", orig.Type(), to)
+	}
+
+	return &IntToPtr{orig: orig, to: to}, nil
+}
+
+// Type returns the type of the value.
+func (exp *IntToPtr) Type() types.Type {
+	return exp.to
+}
+
+// Calc calculates and returns a constant which is equivalent to the constant
+// expression. As the address depends on the memory layout, the expression is
+// its own equivalent constant.
+func (exp *IntToPtr) Calc() Constant {
+	return exp
+}
+
+// Ident returns the identifier associated with the constant expression, e.g.
+//
+//    inttoptr(i64 42 to i8*)
+func (exp *IntToPtr) Ident() string {
+	return fmt.Sprintf("inttoptr(%v %s to %v)", exp.orig.Type(), exp.orig.Ident(), exp.to)
+}
+
+// String returns a string representation of the integer to pointer conversion
+// expression. The expression string representation is preceded by the type of
+// the constant, e.g.
+//
+//    i8* inttoptr(i64 42 to i8*)
+func (exp *IntToPtr) String() string {
+	return fmt.Sprintf("%s %s", exp.Type(), exp.Ident())
+}
+
+// Equal returns true if u is an integer to pointer conversion of an equal
+// constant to the same type, and false otherwise.
+func (exp *IntToPtr) Equal(u values.Value) bool {
+	if u, ok := u.(*IntToPtr); ok {
+		return values.Equal(exp.orig, u.orig) && exp.to.Equal(u.to)
+	}
+	return false
+}
+
+// BitCast is a constant expression which converts a constant to a
+// non-aggregate type of the same size, without changing any bits.
+//
+// Examples:
+//    bitcast(i32* @x to i8*)
+//    bitcast(<2 x i32> <i32 1, i32 2> to i64)
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#constant-expressions
+type BitCast struct {
+	// Original value, such as a constant or a global variable.
+	orig values.Value
+	// New type.
+	to types.Type
+}
+
+// NewBitCast returns a constant expression which converts the constant orig to
+// the non-aggregate type to of the same size. Pointers may only be converted to
+// pointers of the same address space.
+func NewBitCast(orig values.Value, to types.Type) (*BitCast, error) {
+	origType := orig.Type()
+	switch {
+	case isAggregate(origType):
+		return nil, fmt.Errorf("invalid bitcast; expected non-aggregate constant for orig, got %q", origType)
+	case isAggregate(to):
+		return nil, fmt.Errorf("invalid bitcast; expected non-aggregate target type, got %q", to)
+	}
+
+	// Pointers may only be converted to pointers.
+	if types.IsPointers(origType) || types.IsPointers(to) {
+		if !types.IsPointers(origType) || !types.IsPointers(to) || !types.SameLength(origType, to) {
+			return nil, fmt.Errorf("invalid bitcast; cannot convert from %q to %q", origType, to)
+		}
+		if addrSpace(origType) != addrSpace(to) {
+			return nil, fmt.Errorf("invalid bitcast; cannot convert between address spaces of %q and %q; use addrspacecast instead", origType, to)
+		}
+		return &BitCast{orig: orig, to: to}, nil
+	}
+
+	// Verify that the original constant and the target type are of the same
+	// size.
+	origSize, ok := bitSize(origType)
+	if !ok {
+		return nil, fmt.Errorf("invalid bitcast; unable to convert from %q", origType)
+	}
+	newSize, ok := bitSize(to)
+	if !ok {
+		return nil, fmt.Errorf("invalid bitcast; unable to convert to %q", to)
+	}
+	if newSize != origSize {
+		return nil, fmt.Errorf("invalid bitcast; target size (%d) not equal to original size (%d)", newSize, origSize)
+	}
+
+	return &BitCast{orig: orig, to: to}, nil
+}
+
+// Type returns the type of the value.
+func (exp *BitCast) Type() types.Type {
+	return exp.to
+}
+
+// Calc calculates and returns a constant which is equivalent to the constant
+// expression.
+func (exp *BitCast) Calc() Constant {
+	panic("not yet implemented.")
+}
+
+// Ident returns the identifier associated with the constant expression, e.g.
+//
+//    bitcast(i32* @x to i8*)
+func (exp *BitCast) Ident() string {
+	return fmt.Sprintf("bitcast(%v %s to %v)", exp.orig.Type(), exp.orig.Ident(), exp.to)
+}
+
+// String returns a string representation of the bitcast expression. The
+// expression string representation is preceded by the type of the constant,
+// e.g.
+//
+//    i8* bitcast(i32* @x to i8*)
+func (exp *BitCast) String() string {
+	return fmt.Sprintf("%s %s", exp.Type(), exp.Ident())
+}
+
+// Equal returns true if u is a bitcast of an equal constant to the same type,
+// and false otherwise.
+func (exp *BitCast) Equal(u values.Value) bool {
+	if u, ok := u.(*BitCast); ok {
+		return values.Equal(exp.orig, u.orig) && exp.to.Equal(u.to)
+	}
+	return false
+}
+
+// isAggregate returns true if t is an aggregate type (array or structure), and
+// false otherwise.
+func isAggregate(t types.Type) bool {
+	switch t.(type) {
+	case *types.Array, *types.Struct, *types.IdentifiedStruct:
+		return true
+	}
+	return false
+}
+
+// addrSpace returns the address space of the pointer type (or vector of
+// pointers) t.
+func addrSpace(t types.Type) int {
+	if vec, ok := t.(*types.Vector); ok {
+		t = vec.Elem()
+	}
+	return t.(*types.Pointer).AddrSpace()
+}
+
+// bitSize returns the size in bits of the integer, floating point, x86_mmx or
+// vector type t. The boolean return value is false for types without a target
+// independent size.
+func bitSize(t types.Type) (int, bool) {
+	switch t := t.(type) {
+	case *types.Int:
+		return t.Size(), true
+	case *types.Float:
+		return t.Size(), true
+	case *types.MMX:
+		return 64, true
+	case *types.Vector:
+		return t.Size()
+	}
+	return 0, false
 }
 
 // TODO: Add support for the following constant expressions:
-//    - ptrtoint
-//    - inttoptr
-//    - bitcast
 //    - addrspacecast
-//    - getelementptr
 //    - select
 //    - icmp
 //    - fcmp
@@ -595,12 +1147,16 @@ func (exp *IntToFloat) String() string {
 
 // isConst ensures that only constant values can be assigned to the Constant
 // interface.
-func (*IntTrunc) isConst()    {}
-func (*IntZeroExt) isConst()  {}
-func (*IntSignExt) isConst()  {}
-func (*FloatTrunc) isConst()  {}
-func (*FloatExt) isConst()    {}
-func (*FloatToUint) isConst() {}
-func (*FloatToInt) isConst()  {}
-func (*UintToFloat) isConst() {}
-func (*IntToFloat) isConst()  {}
+func (*IntTrunc) isConst()      {}
+func (*IntZeroExt) isConst()    {}
+func (*IntSignExt) isConst()    {}
+func (*FloatTrunc) isConst()    {}
+func (*FloatExt) isConst()      {}
+func (*FloatToUint) isConst()   {}
+func (*FloatToInt) isConst()    {}
+func (*UintToFloat) isConst()   {}
+func (*IntToFloat) isConst()    {}
+func (*GetElementPtr) isConst() {}
+func (*PtrToInt) isConst()      {}
+func (*IntToPtr) isConst()      {}
+func (*BitCast) isConst()       {}
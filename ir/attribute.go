@@ -0,0 +1,197 @@
+package ir
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/llir/llvm/consts"
+)
+
+// ParamAttrs is a set of parameter attributes, which specify properties of
+// function parameters and return values.
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#parameter-attributes
+type ParamAttrs uint16
+
+// Parameter attributes.
+const (
+	ParamZeroExt   ParamAttrs = 1 << iota // zeroext:   zero-extend to the ABI width
+	ParamSignExt                          // signext:   sign-extend to the ABI width
+	ParamInReg                            // inreg:     pass in a register
+	ParamByVal                            // byval:     pass by value
+	ParamInAlloca                         // inalloca:  pass in an outgoing stack allocation
+	ParamSRet                             // sret:      structure return pointer
+	ParamNoAlias                          // noalias:   no aliasing pointer
+	ParamNoCapture                        // nocapture: not captured by the callee
+	ParamNest                             // nest:      trampoline nest pointer
+	ParamReturned                         // returned:  returned by the callee
+	ParamNonNull                          // nonnull:   non-null pointer
+	ParamReadOnly                         // readonly:  not written through by the callee
+	ParamReadNone                         // readnone:  not dereferenced by the callee
+)
+
+// Has returns true if all of the given attributes are set, and false otherwise.
+func (attrs ParamAttrs) Has(a ParamAttrs) bool {
+	return attrs&a == a
+}
+
+// Set sets the given attributes.
+func (attrs *ParamAttrs) Set(a ParamAttrs) {
+	*attrs |= a
+}
+
+// String returns a string representation of the parameter attributes in
+// canonical order, e.g.
+//
+//    noalias nocapture
+func (attrs ParamAttrs) String() string {
+	names := []struct {
+		attr ParamAttrs
+		name string
+	}{
+		{attr: ParamZeroExt, name: "zeroext"},
+		{attr: ParamSignExt, name: "signext"},
+		{attr: ParamInReg, name: "inreg"},
+		{attr: ParamByVal, name: "byval"},
+		{attr: ParamInAlloca, name: "inalloca"},
+		{attr: ParamSRet, name: "sret"},
+		{attr: ParamNoAlias, name: "noalias"},
+		{attr: ParamNoCapture, name: "nocapture"},
+		{attr: ParamNest, name: "nest"},
+		{attr: ParamReturned, name: "returned"},
+		{attr: ParamNonNull, name: "nonnull"},
+		{attr: ParamReadOnly, name: "readonly"},
+		{attr: ParamReadNone, name: "readnone"},
+	}
+	var s []string
+	for _, n := range names {
+		if attrs.Has(n.attr) {
+			s = append(s, n.name)
+		}
+	}
+	return strings.Join(s, " ")
+}
+
+// FuncAttrs is a set of function attributes, which specify properties of
+// functions.
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#function-attributes
+type FuncAttrs uint32
+
+// Function attributes.
+const (
+	FuncAlwaysInline    FuncAttrs = 1 << iota // alwaysinline
+	FuncBuiltin                               // builtin
+	FuncCold                                  // cold
+	FuncInlineHint                            // inlinehint
+	FuncMinSize                               // minsize
+	FuncNaked                                 // naked
+	FuncNoBuiltin                             // nobuiltin
+	FuncNoDuplicate                           // noduplicate
+	FuncNoImplicitFloat                       // noimplicitfloat
+	FuncNoInline                              // noinline
+	FuncNonLazyBind                           // nonlazybind
+	FuncNoRedZone                             // noredzone
+	FuncNoReturn                              // noreturn
+	FuncNoUnwind                              // nounwind
+	FuncOptNone                               // optnone
+	FuncOptSize                               // optsize
+	FuncReadNone                              // readnone
+	FuncReadOnly                              // readonly
+	FuncReturnsTwice                          // returns_twice
+	FuncSSP                                   // ssp
+	FuncSSPReq                                // sspreq
+	FuncSSPStrong                             // sspstrong
+	FuncUWTable                               // uwtable
+)
+
+// Has returns true if all of the given attributes are set, and false otherwise.
+func (attrs FuncAttrs) Has(a FuncAttrs) bool {
+	return attrs&a == a
+}
+
+// Set sets the given attributes.
+func (attrs *FuncAttrs) Set(a FuncAttrs) {
+	*attrs |= a
+}
+
+// String returns a string representation of the function attributes in
+// canonical order, e.g.
+//
+//    noreturn nounwind
+func (attrs FuncAttrs) String() string {
+	names := []struct {
+		attr FuncAttrs
+		name string
+	}{
+		{attr: FuncAlwaysInline, name: "alwaysinline"},
+		{attr: FuncBuiltin, name: "builtin"},
+		{attr: FuncCold, name: "cold"},
+		{attr: FuncInlineHint, name: "inlinehint"},
+		{attr: FuncMinSize, name: "minsize"},
+		{attr: FuncNaked, name: "naked"},
+		{attr: FuncNoBuiltin, name: "nobuiltin"},
+		{attr: FuncNoDuplicate, name: "noduplicate"},
+		{attr: FuncNoImplicitFloat, name: "noimplicitfloat"},
+		{attr: FuncNoInline, name: "noinline"},
+		{attr: FuncNonLazyBind, name: "nonlazybind"},
+		{attr: FuncNoRedZone, name: "noredzone"},
+		{attr: FuncNoReturn, name: "noreturn"},
+		{attr: FuncNoUnwind, name: "nounwind"},
+		{attr: FuncOptNone, name: "optnone"},
+		{attr: FuncOptSize, name: "optsize"},
+		{attr: FuncReadNone, name: "readnone"},
+		{attr: FuncReadOnly, name: "readonly"},
+		{attr: FuncReturnsTwice, name: "returns_twice"},
+		{attr: FuncSSP, name: "ssp"},
+		{attr: FuncSSPReq, name: "sspreq"},
+		{attr: FuncSSPStrong, name: "sspstrong"},
+		{attr: FuncUWTable, name: "uwtable"},
+	}
+	var s []string
+	for _, n := range names {
+		if attrs.Has(n.attr) {
+			s = append(s, n.name)
+		}
+	}
+	return strings.Join(s, " ")
+}
+
+// StringAttrs is a set of string function attributes, which are arbitrary
+// key-value pairs (e.g. "target-cpu"="x86-64") interpreted by the code
+// generator. It maps from attribute key to value, where an empty value denotes
+// an attribute without value (e.g. "no-builtins").
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#function-attributes
+type StringAttrs map[string]string
+
+// String returns a string representation of the string function attributes,
+// sorted by key, e.g.
+//
+//    "frame-pointer"="all" "target-cpu"="x86-64"
+func (attrs StringAttrs) String() string {
+	var keys []string
+	for key := range attrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var s []string
+	for _, key := range keys {
+		if val := attrs[key]; len(val) > 0 {
+			s = append(s, quote(key)+"="+quote(val))
+		} else {
+			s = append(s, quote(key))
+		}
+	}
+	return strings.Join(s, " ")
+}
+
+// quote returns s as a double-quoted LLVM string literal, e.g.
+//
+//    "foo\0A"
+func quote(s string) string {
+	return `"` + consts.Escape(s) + `"`
+}
@@ -1,5 +1,15 @@
 package ir
 
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/llir/llvm/types"
+	"github.com/llir/llvm/values"
+)
+
 // A BasicBlock is a sequence of non-branching instructions, terminated by a
 // control flow instruction (such as br or ret).
 //
@@ -18,3 +28,179 @@ type BasicBlock struct {
 	// Terminator instruction of the basic block.
 	Term Terminator
 }
+
+// AppendInst appends the given non-terminator instruction to the basic block.
+func (block *BasicBlock) AppendInst(inst Instruction) {
+	block.Insts = append(block.Insts, inst)
+}
+
+// SetTerm sets the terminator instruction of the basic block.
+func (block *BasicBlock) SetTerm(term Terminator) {
+	block.Term = term
+}
+
+// ReplaceUsesOf replaces all uses of the value old with new in the operands of
+// the instructions and the terminator of the basic block. Values are compared by
+// identity. The number of replaced uses is returned.
+func (block *BasicBlock) ReplaceUsesOf(old, new values.Value) int {
+	n := 0
+	replace := func(inst operandSetter) {
+		for i, op := range inst.Operands() {
+			if op == old {
+				// The operand index is known to be in range.
+				_ = inst.SetOperand(i, new)
+				n++
+			}
+		}
+	}
+	for _, inst := range block.Insts {
+		replace(inst)
+	}
+	if block.Term != nil {
+		replace(block.Term)
+	}
+	return n
+}
+
+// An operandSetter is an instruction or terminator with value operands.
+type operandSetter interface {
+	Operands() []values.Value
+	SetOperand(i int, v values.Value) error
+}
+
+// Successors returns the successor basic blocks of the basic block, as
+// targeted by its terminator. The successors of a basic block without a
+// terminator, or terminated by ret, resume or unreachable, are empty.
+func (block *BasicBlock) Successors() []*BasicBlock {
+	switch term := block.Term.(type) {
+	case *BranchInst:
+		return []*BasicBlock{term.Target}
+	case *CondBranchInst:
+		return []*BasicBlock{term.True, term.False}
+	case *SwitchInst:
+		succs := []*BasicBlock{term.Default}
+		for _, c := range term.Cases {
+			succs = append(succs, c.Target)
+		}
+		return succs
+	case *IndirectbrInst:
+		return append([]*BasicBlock(nil), term.Targets...)
+	case *InvokeInst:
+		return []*BasicBlock{term.Normal, term.Unwind}
+	}
+	return nil
+}
+
+// Predecessors returns a map from each basic block of the function f to its
+// predecessor basic blocks, in the order of the basic blocks of f. A basic block
+// without predecessors is not present in the map.
+func Predecessors(f *Function) map[*BasicBlock][]*BasicBlock {
+	preds := make(map[*BasicBlock][]*BasicBlock)
+	for _, block := range f.Blocks {
+		for _, succ := range block.Successors() {
+			preds[succ] = append(preds[succ], block)
+		}
+	}
+	return preds
+}
+
+// AssignIDs assigns sequential numeric names, starting at id, to the basic block
+// (if unnamed) and to the unnamed results of its value-producing instructions.
+// Named basic blocks and instructions are left untouched. The next unused id is
+// returned, so that the basic blocks of a function may be numbered in order.
+func (block *BasicBlock) AssignIDs(id int) int {
+	if len(block.Name) == 0 {
+		block.Name = strconv.Itoa(id)
+		id++
+	}
+	assign := func(inst interface{}) {
+		if r, ok := inst.(result); ok && len(r.name()) == 0 && !isVoid(inst) {
+			r.setName(strconv.Itoa(id))
+			id++
+		}
+	}
+	for _, inst := range block.Insts {
+		assign(inst)
+	}
+	if block.Term != nil {
+		assign(block.Term)
+	}
+	return id
+}
+
+// isVoid returns true if the instruction is a call or invoke instruction of
+// void result type, which produces no value, and false otherwise.
+func isVoid(inst interface{}) bool {
+	var typ types.Type
+	switch inst := inst.(type) {
+	case *CallInst:
+		typ = inst.Type
+	case *InvokeInst:
+		typ = inst.Type
+	default:
+		return false
+	}
+	_, ok := typ.(*types.Void)
+	return ok
+}
+
+// Type returns the type of the basic block, which is label.
+func (block *BasicBlock) Type() types.Type {
+	return types.NewLabel()
+}
+
+// Ident returns the identifier associated with the basic block, e.g.
+//
+//    %entry
+func (block *BasicBlock) Ident() string {
+	return "%" + block.Name
+}
+
+// String returns a string representation of the basic block, which consists of
+// its label followed by its instructions, e.g.
+//
+//    entry:
+//      ret void
+func (block *BasicBlock) String() string {
+	return block.format(nil, nil)
+}
+
+// format returns a string representation of the basic block, in which the label
+// is annotated with the given predecessor basic blocks, and each instruction is
+// followed by its source comment (if any) of the given side table, e.g.
+//
+//    loop:                                             ; preds = %entry, %loop
+//      %i1 = add i32 %i, 1 ; increment
+//      br label %loop
+func (block *BasicBlock) format(preds []*BasicBlock, comments map[interface{}]string) string {
+	buf := new(bytes.Buffer)
+	label := block.Name + ":"
+	buf.WriteString(label)
+	if len(preds) > 0 {
+		// Pad to column 50, as done by llvm-dis.
+		pad := 50 - len(label)
+		if pad < 1 {
+			pad = 1
+		}
+		var idents []string
+		for _, pred := range preds {
+			idents = append(idents, pred.Ident())
+		}
+		fmt.Fprintf(buf, "%s; preds = %s", strings.Repeat(" ", pad), strings.Join(idents, ", "))
+	}
+	buf.WriteString("\n")
+	line := func(inst interface{}) {
+		fmt.Fprintf(buf, "  %v", inst)
+		if comment, ok := comments[inst]; ok {
+			fmt.Fprintf(buf, " ; %s", comment)
+		}
+		buf.WriteString("\n")
+	}
+	for _, inst := range block.Insts {
+		line(inst)
+	}
+	if block.Term != nil {
+		line(block.Term)
+	}
+	return buf.String()
+}
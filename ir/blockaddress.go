@@ -0,0 +1,58 @@
+package ir
+
+import (
+	"fmt"
+
+	"github.com/llir/llvm/types"
+)
+
+// A BlockAddress is a constant which holds the address of a basic block in a
+// function. Block addresses may only be used as the address of indirectbr
+// instructions, or be compared to null.
+//
+// Examples:
+//    blockaddress(@f, %bb)
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#addresses-of-basic-blocks
+type BlockAddress struct {
+	// Parent function of the basic block.
+	Func *Function
+	// Basic block.
+	Block *BasicBlock
+}
+
+// NewBlockAddress returns a constant which holds the address of the given basic
+// block, which must belong to the function f.
+func NewBlockAddress(f *Function, block *BasicBlock) (*BlockAddress, error) {
+	for _, b := range f.Blocks {
+		if b == block {
+			return &BlockAddress{Func: f, Block: block}, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid blockaddress; basic block %s does not belong to function %s", block.Ident(), f.Ident())
+}
+
+// Type returns the type of the block address, which is i8*.
+func (addr *BlockAddress) Type() types.Type {
+	typ, err := types.NewPointer(types.I8)
+	if err != nil {
+		panic(fmt.Sprintf("unable to create pointer type; %v", err))
+	}
+	return typ
+}
+
+// Ident returns the identifier associated with the block address, e.g.
+//
+//    blockaddress(@f, %bb)
+func (addr *BlockAddress) Ident() string {
+	return fmt.Sprintf("blockaddress(%s, %s)", addr.Func.Ident(), addr.Block.Ident())
+}
+
+// String returns a string representation of the block address, preceded by its
+// type, e.g.
+//
+//    i8* blockaddress(@f, %bb)
+func (addr *BlockAddress) String() string {
+	return fmt.Sprintf("%v %s", addr.Type(), addr.Ident())
+}
@@ -0,0 +1,236 @@
+package ir
+
+import (
+	"strconv"
+
+	"github.com/llir/llvm/types"
+	"github.com/llir/llvm/values"
+)
+
+// A Builder constructs instructions and appends them to its current basic
+// block, in the spirit of the IRBuilder of LLVM. The result types of
+// instructions are inferred from their operands.
+//
+// The results of value-producing instructions are assigned sequential numeric
+// names. Unnamed values must be numbered in textual order, so the unnamed
+// function parameters, basic blocks and instruction results of the function are
+// renumbered whenever an instruction is emitted (e.g. %1, %2, … in an unnamed
+// entry basic block %0, preceding its successor %3). The local variables
+// returned by the builder are kept up to date with the names of the results
+// they refer to.
+//
+// The Create methods panic if given operands of invalid types.
+type Builder struct {
+	// Current basic block, to which new instructions are appended.
+	block *BasicBlock
+	// Function parameters, basic blocks and instructions numbered by the
+	// builder.
+	numbered map[interface{}]bool
+	// Local variables returned by the builder, mapping from instruction to the
+	// local variable referring to its result.
+	locals map[Instruction]*values.Local
+}
+
+// NewBuilder returns a new builder which appends instructions to the given
+// basic block. The unnamed function parameters, basic blocks and instruction
+// results of the parent function of the basic block (or of the basic block, if
+// it has no parent) are assigned sequential numeric names, as by AssignIDs.
+func NewBuilder(block *BasicBlock) *Builder {
+	b := &Builder{
+		block:    block,
+		numbered: make(map[interface{}]bool),
+		locals:   make(map[Instruction]*values.Local),
+	}
+	b.number()
+	return b
+}
+
+// Block returns the current basic block of the builder.
+func (b *Builder) Block() *BasicBlock {
+	return b.block
+}
+
+// SetBlock sets the current basic block of the builder, to which subsequent
+// instructions are appended. The unnamed values of its parent function are
+// renumbered, as by NewBuilder.
+func (b *Builder) SetBlock(block *BasicBlock) {
+	b.block = block
+	b.number()
+}
+
+// CreateAdd appends an add instruction of the operands x and y, and returns its
+// result.
+func (b *Builder) CreateAdd(x, y values.Value) values.Value {
+	inst := &AddInst{Op1: x, Op2: y}
+	inst.Type = b.infer(inst)
+	return b.emit(inst)
+}
+
+// CreateSub appends a sub instruction of the operands x and y, and returns its
+// result.
+func (b *Builder) CreateSub(x, y values.Value) values.Value {
+	inst := &SubInst{Op1: x, Op2: y}
+	inst.Type = b.infer(inst)
+	return b.emit(inst)
+}
+
+// CreateMul appends a mul instruction of the operands x and y, and returns its
+// result.
+func (b *Builder) CreateMul(x, y values.Value) values.Value {
+	inst := &MulInst{Op1: x, Op2: y}
+	inst.Type = b.infer(inst)
+	return b.emit(inst)
+}
+
+// CreateIcmp appends an icmp instruction which compares the integer (or pointer)
+// operands x and y using the given predicate, and returns its boolean result of
+// type i1 (or vector of i1).
+func (b *Builder) CreateIcmp(pred IntPredicate, x, y values.Value) values.Value {
+	inst := &IcmpInst{Pred: pred, Op1: x, Op2: y}
+	// The declared type of icmp is the type of its operands.
+	inst.Type = x.Type()
+	return b.emit(inst)
+}
+
+// CreateAlloca appends an alloca instruction which allocates memory for one
+// element of the given type, and returns a pointer to the allocated memory.
+func (b *Builder) CreateAlloca(typ types.Type) values.Value {
+	inst, err := NewAlloca(typ, 1)
+	if err != nil {
+		panic(err)
+	}
+	return b.emit(inst)
+}
+
+// CreateLoad appends a load instruction which reads from the memory address
+// ptr, and returns the loaded value.
+func (b *Builder) CreateLoad(ptr values.Value) values.Value {
+	inst, err := NewLoad(ptr)
+	if err != nil {
+		panic(err)
+	}
+	return b.emit(inst)
+}
+
+// CreateStore appends a store instruction which writes val to the memory
+// address ptr.
+func (b *Builder) CreateStore(val, ptr values.Value) {
+	inst, err := NewStore(val, ptr)
+	if err != nil {
+		panic(err)
+	}
+	b.block.AppendInst(inst)
+}
+
+// CreateRet terminates the current basic block with a ret instruction, which
+// returns the value v (or void if v is nil).
+func (b *Builder) CreateRet(v values.Value) {
+	b.block.SetTerm(NewRet(v))
+}
+
+// CreateBr terminates the current basic block with an unconditional br
+// instruction to the target basic block.
+func (b *Builder) CreateBr(target *BasicBlock) {
+	b.block.SetTerm(NewBr(target))
+}
+
+// CreateCondBr terminates the current basic block with a conditional br
+// instruction, which transfers control flow to trueBranch if cond is true and
+// to falseBranch otherwise.
+func (b *Builder) CreateCondBr(cond values.Value, trueBranch, falseBranch *BasicBlock) {
+	term, err := NewCondBr(cond, trueBranch, falseBranch)
+	if err != nil {
+		panic(err)
+	}
+	b.block.SetTerm(term)
+}
+
+// CreateUnreachableAfterNoReturn terminates the current basic block with an
+// unreachable instruction if the given call instruction invokes a function
+// marked noreturn, as control flow never returns from such a call (e.g. exit or
+// abort). It is a no-op if the callee is not a noreturn function, or if the
+// current basic block is already terminated.
+func (b *Builder) CreateUnreachableAfterNoReturn(call *CallInst) {
+	if b.block.Term != nil {
+		return
+	}
+	if callee, ok := call.Callee.(*Function); ok && callee.FuncAttrs.Has(FuncNoReturn) {
+		b.block.SetTerm(&UnreachableInst{})
+	}
+}
+
+// emit infers the result type of the given value-producing instruction, which
+// panics if its operands are invalid, appends the instruction to the current
+// basic block, renumbers the unnamed values of the function, and returns a reference
+// to the result of the instruction.
+func (b *Builder) emit(inst Instruction) values.Value {
+	typ := b.infer(inst)
+	b.block.AppendInst(inst)
+	b.numbered[inst] = true
+	b.number()
+	v, err := NewLocal(inst, typ)
+	if err != nil {
+		panic(err)
+	}
+	b.locals[inst] = v
+	return v
+}
+
+// number assigns sequential numeric names, in textual order, to the unnamed
+// function parameters, basic blocks and instruction results of the parent
+// function of the current basic block (or of the current basic block, if it has
+// no parent), and to the values previously numbered by the builder. The names of
+// the local variables returned by the builder are updated accordingly.
+func (b *Builder) number() {
+	id := 0
+	// assign assigns the next numeric name to the value v, using the given name
+	// and setName functions, if v is unnamed or was numbered by the builder.
+	assign := func(v interface{}, name string, setName func(name string)) {
+		if len(name) > 0 && !b.numbered[v] {
+			return
+		}
+		b.numbered[v] = true
+		name = strconv.Itoa(id)
+		id++
+		setName(name)
+		if inst, ok := v.(Instruction); ok {
+			if local, ok := b.locals[inst]; ok {
+				local.Name = name
+			}
+		}
+	}
+	numberResult := func(inst interface{}) {
+		if r, ok := inst.(result); ok && !isVoid(inst) {
+			assign(inst, r.name(), r.setName)
+		}
+	}
+
+	blocks := []*BasicBlock{b.block}
+	if f := b.block.Parent; f != nil {
+		for _, param := range f.Params {
+			param := param
+			assign(param, param.Name, func(name string) { param.Name = name })
+		}
+		blocks = f.Blocks
+	}
+	for _, block := range blocks {
+		block := block
+		assign(block, block.Name, func(name string) { block.Name = name })
+		for _, inst := range block.Insts {
+			numberResult(inst)
+		}
+		if block.Term != nil {
+			numberResult(block.Term)
+		}
+	}
+}
+
+// infer returns the result type of the given instruction, as derived from its
+// operands, and panics if the operands are invalid.
+func (b *Builder) infer(inst Instruction) types.Type {
+	typ, err := InferType(inst)
+	if err != nil {
+		panic(err)
+	}
+	return typ
+}
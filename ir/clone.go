@@ -0,0 +1,283 @@
+package ir
+
+import "github.com/llir/llvm/values"
+
+// Clone returns a copy of the instruction. The slices and maps of the
+// instruction (e.g. call arguments and phi predecessors) are copied, so that
+// modifications of the clone do not affect the original instruction. Operand
+// values are shared between the original instruction and its clone.
+func (inst *AddInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *FaddInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *SubInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *FsubInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *MulInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *FmulInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *UdivInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *SdivInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *FdivInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *UremInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *SremInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *FremInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *ShlInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *LshrInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *AshrInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *AndInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *OrInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *XorInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *ExtractvalueInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	c.Indices = append([]int(nil), inst.Indices...)
+	return &c
+}
+
+func (inst *InsertvalueInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	c.Indices = append([]int(nil), inst.Indices...)
+	return &c
+}
+
+func (inst *AllocaInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *LoadInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *StoreInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *FenceInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *GetelementptrInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	c.Indicies = append([]int(nil), inst.Indicies...)
+	return &c
+}
+
+func (inst *TruncInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *ZextInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *SextInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *FptruncInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *FpextInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *FptouiInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *FptosiInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *UitofpInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *SitofpInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *PtrtointInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *InttoptrInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *BitcastInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *AddrspacecastInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *IcmpInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *FcmpInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *PhiInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	if inst.Preds != nil {
+		c.Preds = make(map[string]values.Value, len(inst.Preds))
+		for pred, val := range inst.Preds {
+			c.Preds[pred] = val
+		}
+	}
+	c.order = append([]string(nil), inst.order...)
+	return &c
+}
+
+func (inst *SelectInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	return &c
+}
+
+func (inst *CallInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	c.Args = append([]values.Value(nil), inst.Args...)
+	return &c
+}
+
+func (inst *LandingpadInst) Clone() Instruction {
+	c := *inst
+	c.Metadata = inst.Metadata.clone()
+	c.Clauses = append([]LandingpadClause(nil), inst.Clauses...)
+	return &c
+}
@@ -0,0 +1,46 @@
+package ir
+
+// DeadCodeElim removes the instructions of the function f whose results are
+// unused and which have no side effects. Instructions are removed until a fixed
+// point is reached, as removing an instruction may render the instructions
+// defining its operands unused. Terminators are never removed. The number of
+// removed instructions is returned.
+//
+// Liveness is decided by the use graph of the function, taking uses by
+// terminators into account.
+func DeadCodeElim(f *Function) int {
+	n := 0
+	for {
+		g := BuildUseGraph(f)
+		removed := 0
+		for _, block := range f.Blocks {
+			insts := block.Insts[:0]
+			for _, inst := range block.Insts {
+				if isPure(inst) && len(g.ResultUsers(inst)) == 0 && g.termUses[inst] == 0 {
+					removed++
+					continue
+				}
+				insts = append(insts, inst)
+			}
+			block.Insts = insts
+		}
+		if removed == 0 {
+			return n
+		}
+		n += removed
+	}
+}
+
+// isPure returns true if the instruction has no side effects, and may therefore
+// be removed if its result is unused. Instructions which access memory (such as
+// load, store and call) are considered to have side effects.
+func isPure(inst Instruction) bool {
+	if IsBinaryOp(inst) || IsBitwiseOp(inst) || IsConversion(inst) {
+		return true
+	}
+	switch inst.(type) {
+	case *ExtractvalueInst, *InsertvalueInst, *GetelementptrInst, *IcmpInst, *FcmpInst, *PhiInst, *SelectInst:
+		return true
+	}
+	return false
+}
@@ -6,5 +6,10 @@
 // function. Each basic block consists of a sequence of non-branching
 // instructions, terminated by a control flow instruction (such as br or ret).
 //
+// The String methods print the assembly syntax of LLVM 15, using typed
+// pointers; e.g. load and getelementptr instructions and constant expressions
+// state their element type explicitly (load i32, i32* %p), and atomic
+// instructions specify their synchronization scope using syncscope.
+//
 //    [1]: http://llvm.org/docs/LangRef.html
 package ir
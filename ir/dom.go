@@ -0,0 +1,142 @@
+package ir
+
+import "fmt"
+
+// A DominatorTree records the dominance relation between the basic blocks of a
+// function. A basic block a dominates a basic block b if every path from the
+// entry basic block to b passes through a.
+type DominatorTree struct {
+	// Entry basic block of the function; the root of the tree.
+	root *BasicBlock
+	// Immediate dominator of each reachable basic block. The root is its own
+	// immediate dominator.
+	idom map[*BasicBlock]*BasicBlock
+	// Post-order number of each reachable basic block.
+	post map[*BasicBlock]int
+}
+
+// ComputeDominators computes the dominator tree of the function f, rooted at
+// its entry basic block. Basic blocks unreachable from the entry basic block
+// are not part of the tree.
+//
+// References:
+//    Cooper, K. D., Harvey, T. J. and Kennedy, K. A Simple, Fast Dominance
+//    Algorithm.
+func ComputeDominators(f *Function) *DominatorTree {
+	dt := &DominatorTree{
+		idom: make(map[*BasicBlock]*BasicBlock),
+		post: make(map[*BasicBlock]int),
+	}
+	dt.root = f.Entry()
+	if dt.root == nil {
+		return dt
+	}
+
+	// Number the reachable basic blocks in post-order.
+	var order []*BasicBlock
+	var visit func(block *BasicBlock)
+	visit = func(block *BasicBlock) {
+		dt.post[block] = -1
+		for _, succ := range block.Successors() {
+			if _, ok := dt.post[succ]; !ok {
+				visit(succ)
+			}
+		}
+		dt.post[block] = len(order)
+		order = append(order, block)
+	}
+	visit(dt.root)
+
+	// Iterate in reverse post-order until a fixed point is reached.
+	preds := Predecessors(f)
+	dt.idom[dt.root] = dt.root
+	for changed := true; changed; {
+		changed = false
+		for i := len(order) - 2; i >= 0; i-- {
+			block := order[i]
+			var idom *BasicBlock
+			for _, pred := range preds[block] {
+				if _, ok := dt.idom[pred]; !ok {
+					// Skip unprocessed and unreachable predecessors.
+					continue
+				}
+				if idom == nil {
+					idom = pred
+					continue
+				}
+				idom = dt.intersect(pred, idom)
+			}
+			if idom != nil && dt.idom[block] != idom {
+				dt.idom[block] = idom
+				changed = true
+			}
+		}
+	}
+	return dt
+}
+
+// intersect returns the nearest common dominator of the basic blocks a and b.
+func (dt *DominatorTree) intersect(a, b *BasicBlock) *BasicBlock {
+	for a != b {
+		for dt.post[a] < dt.post[b] {
+			a = dt.idom[a]
+		}
+		for dt.post[b] < dt.post[a] {
+			b = dt.idom[b]
+		}
+	}
+	return a
+}
+
+// IDom returns the immediate dominator of the basic block b, or nil if b is the
+// entry basic block or unreachable.
+func (dt *DominatorTree) IDom(b *BasicBlock) *BasicBlock {
+	if b == dt.root {
+		return nil
+	}
+	return dt.idom[b]
+}
+
+// Dominates reports whether the basic block a dominates the basic block b. Every
+// reachable basic block dominates itself. Unreachable basic blocks neither
+// dominate nor are dominated by any basic block.
+func (dt *DominatorTree) Dominates(a, b *BasicBlock) bool {
+	if _, ok := dt.idom[a]; !ok {
+		return false
+	}
+	if _, ok := dt.idom[b]; !ok {
+		return false
+	}
+	for b != dt.root {
+		if a == b {
+			return true
+		}
+		b = dt.idom[b]
+	}
+	return a == dt.root
+}
+
+// LoopLatch returns the latch of the natural loop with the given header basic
+// block; i.e. the basic block of the loop with a back edge to the header. The
+// header must belong to a function, and the loop must have exactly one latch.
+func LoopLatch(header *BasicBlock) (*BasicBlock, error) {
+	f := header.Parent
+	if f == nil {
+		return nil, fmt.Errorf("invalid loop header %s; not part of a function", header.Ident())
+	}
+	dt := ComputeDominators(f)
+	var latches []*BasicBlock
+	for _, pred := range Predecessors(f)[header] {
+		// A back edge targets a basic block which dominates its source.
+		if dt.Dominates(header, pred) {
+			latches = append(latches, pred)
+		}
+	}
+	switch len(latches) {
+	case 0:
+		return nil, fmt.Errorf("invalid loop header %s; no back edge", header.Ident())
+	case 1:
+		return latches[0], nil
+	}
+	return nil, fmt.Errorf("invalid loop header %s; expected one latch, got %d", header.Ident(), len(latches))
+}
@@ -0,0 +1,293 @@
+package ir
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/llir/llvm/consts"
+	"github.com/llir/llvm/types"
+	"github.com/llir/llvm/values"
+)
+
+// Fold tries to fold the given binary, bitwise binary or icmp instruction with
+// constant integer (or floating point) operands into a constant. The boolean
+// return value is true if the instruction was folded, and false otherwise.
+//
+// Results wrap around at the bit width of the operand type. Instructions which
+// would produce undefined behaviour or a poison value, such as integer division
+// by zero, oversized shifts or overflow of nuw and nsw instructions, are not
+// folded.
+func Fold(inst Instruction) (values.Value, bool) {
+	switch inst := inst.(type) {
+	// Binary instructions.
+	case *AddInst:
+		return foldInt(inst.Op1, inst.Op2, inst.NUW, inst.NSW, func(x, y *big.Int, size uint) (*big.Int, bool) {
+			return new(big.Int).Add(x, y), true
+		})
+	case *SubInst:
+		return foldInt(inst.Op1, inst.Op2, inst.NUW, inst.NSW, func(x, y *big.Int, size uint) (*big.Int, bool) {
+			return new(big.Int).Sub(x, y), true
+		})
+	case *MulInst:
+		return foldInt(inst.Op1, inst.Op2, inst.NUW, inst.NSW, func(x, y *big.Int, size uint) (*big.Int, bool) {
+			return new(big.Int).Mul(x, y), true
+		})
+	case *UdivInst:
+		return foldInt(inst.Op1, inst.Op2, false, false, func(x, y *big.Int, size uint) (*big.Int, bool) {
+			x, y = unsigned(x, size), unsigned(y, size)
+			if y.Sign() == 0 {
+				return nil, false
+			}
+			q, r := new(big.Int).QuoRem(x, y, new(big.Int))
+			return q, !inst.Exact || r.Sign() == 0
+		})
+	case *SdivInst:
+		return foldInt(inst.Op1, inst.Op2, false, false, func(x, y *big.Int, size uint) (*big.Int, bool) {
+			if y.Sign() == 0 {
+				return nil, false
+			}
+			q, r := new(big.Int).QuoRem(x, y, new(big.Int))
+			if !fitsSigned(q, size) {
+				// Overflow (e.g. sdiv i8 -128, -1).
+				return nil, false
+			}
+			return q, !inst.Exact || r.Sign() == 0
+		})
+	case *UremInst:
+		return foldInt(inst.Op1, inst.Op2, false, false, func(x, y *big.Int, size uint) (*big.Int, bool) {
+			x, y = unsigned(x, size), unsigned(y, size)
+			if y.Sign() == 0 {
+				return nil, false
+			}
+			return new(big.Int).Rem(x, y), true
+		})
+	case *SremInst:
+		return foldInt(inst.Op1, inst.Op2, false, false, func(x, y *big.Int, size uint) (*big.Int, bool) {
+			if y.Sign() == 0 {
+				return nil, false
+			}
+			if q := new(big.Int).Quo(x, y); !fitsSigned(q, size) {
+				// Overflow (e.g. srem i8 -128, -1).
+				return nil, false
+			}
+			return new(big.Int).Rem(x, y), true
+		})
+	case *FaddInst:
+		return foldFloat(inst.Op1, inst.Op2, func(x, y float64) float64 {
+			return x + y
+		})
+	case *FsubInst:
+		return foldFloat(inst.Op1, inst.Op2, func(x, y float64) float64 {
+			return x - y
+		})
+	case *FmulInst:
+		return foldFloat(inst.Op1, inst.Op2, func(x, y float64) float64 {
+			return x * y
+		})
+	case *FdivInst:
+		return foldFloat(inst.Op1, inst.Op2, func(x, y float64) float64 {
+			return x / y
+		})
+	case *FremInst:
+		return foldFloat(inst.Op1, inst.Op2, math.Mod)
+
+	// Bitwise binary instructions.
+	case *ShlInst:
+		return foldInt(inst.Op1, inst.Op2, inst.NUW, inst.NSW, func(x, y *big.Int, size uint) (*big.Int, bool) {
+			n, ok := shiftAmount(y, size)
+			if !ok {
+				return nil, false
+			}
+			return new(big.Int).Lsh(x, n), true
+		})
+	case *LshrInst:
+		return foldInt(inst.Op1, inst.Op2, false, false, func(x, y *big.Int, size uint) (*big.Int, bool) {
+			n, ok := shiftAmount(y, size)
+			if !ok {
+				return nil, false
+			}
+			x = unsigned(x, size)
+			z := new(big.Int).Rsh(x, n)
+			return z, !inst.Exact || new(big.Int).Lsh(z, n).Cmp(x) == 0
+		})
+	case *AshrInst:
+		return foldInt(inst.Op1, inst.Op2, false, false, func(x, y *big.Int, size uint) (*big.Int, bool) {
+			n, ok := shiftAmount(y, size)
+			if !ok {
+				return nil, false
+			}
+			z := new(big.Int).Rsh(x, n)
+			return z, !inst.Exact || new(big.Int).Lsh(z, n).Cmp(x) == 0
+		})
+	case *AndInst:
+		return foldInt(inst.Op1, inst.Op2, false, false, func(x, y *big.Int, size uint) (*big.Int, bool) {
+			return new(big.Int).And(x, y), true
+		})
+	case *OrInst:
+		return foldInt(inst.Op1, inst.Op2, false, false, func(x, y *big.Int, size uint) (*big.Int, bool) {
+			return new(big.Int).Or(x, y), true
+		})
+	case *XorInst:
+		return foldInt(inst.Op1, inst.Op2, false, false, func(x, y *big.Int, size uint) (*big.Int, bool) {
+			return new(big.Int).Xor(x, y), true
+		})
+
+	// Other instructions.
+	case *IcmpInst:
+		return foldIcmp(inst.Pred, inst.Op1, inst.Op2)
+	}
+	return nil, false
+}
+
+// foldInt folds the integer constants a and b using the operation f, which
+// receives the signed values of the operands and the size of their type, and
+// reports whether the result is defined. The result is wrapped around at the
+// size of the type, unless nuw or nsw is set and the result overflows.
+func foldInt(a, b values.Value, nuw, nsw bool, f func(x, y *big.Int, size uint) (*big.Int, bool)) (values.Value, bool) {
+	x, ok := a.(*consts.Int)
+	if !ok {
+		return nil, false
+	}
+	y, ok := b.(*consts.Int)
+	if !ok || !x.Type().Equal(y.Type()) {
+		return nil, false
+	}
+	typ := x.Type().(*types.Int)
+	size := uint(typ.Size())
+	z, ok := f(signed(x.BigInt(), size), signed(y.BigInt(), size), size)
+	if !ok {
+		return nil, false
+	}
+	if nsw && !fitsSigned(z, size) {
+		return nil, false
+	}
+	if nuw {
+		// Recompute the result using unsigned operands to detect unsigned
+		// overflow.
+		uz, _ := f(unsigned(x.BigInt(), size), unsigned(y.BigInt(), size), size)
+		if uz.Sign() < 0 || uz.BitLen() > int(size) {
+			return nil, false
+		}
+	}
+	return newIntConst(typ, z), true
+}
+
+// foldFloat folds the floating point constants a and b using the operation f.
+func foldFloat(a, b values.Value, f func(x, y float64) float64) (values.Value, bool) {
+	x, ok := a.(*consts.Float)
+	if !ok {
+		return nil, false
+	}
+	y, ok := b.(*consts.Float)
+	if !ok || !x.Type().Equal(y.Type()) {
+		return nil, false
+	}
+	typ := x.Type().(*types.Float)
+	z := f(x.Float64(), y.Float64())
+	if typ.Size() == 32 {
+		// Round the result to single precision.
+		z = float64(float32(z))
+	}
+	c, err := consts.NewFloatFromFloat64(typ, z)
+	if err != nil {
+		return nil, false
+	}
+	return c, true
+}
+
+// foldIcmp folds the comparison of the integer constants a and b using the
+// predicate pred into a boolean constant.
+func foldIcmp(pred IntPredicate, a, b values.Value) (values.Value, bool) {
+	x, ok := a.(*consts.Int)
+	if !ok {
+		return nil, false
+	}
+	y, ok := b.(*consts.Int)
+	if !ok || !x.Type().Equal(y.Type()) {
+		return nil, false
+	}
+	size := uint(x.Type().(*types.Int).Size())
+	s := signed(x.BigInt(), size).Cmp(signed(y.BigInt(), size))
+	u := unsigned(x.BigInt(), size).Cmp(unsigned(y.BigInt(), size))
+	var cond bool
+	switch pred {
+	case IntEq:
+		cond = s == 0
+	case IntNe:
+		cond = s != 0
+	case IntUgt:
+		cond = u > 0
+	case IntUge:
+		cond = u >= 0
+	case IntUlt:
+		cond = u < 0
+	case IntUle:
+		cond = u <= 0
+	case IntSgt:
+		cond = s > 0
+	case IntSge:
+		cond = s >= 0
+	case IntSlt:
+		cond = s < 0
+	case IntSle:
+		cond = s <= 0
+	default:
+		return nil, false
+	}
+	if cond {
+		return newIntConst(types.I1, big.NewInt(1)), true
+	}
+	return newIntConst(types.I1, big.NewInt(0)), true
+}
+
+// newIntConst returns an integer constant of the given type, with the value x
+// wrapped around at the size of the type.
+func newIntConst(typ *types.Int, x *big.Int) *consts.Int {
+	size := uint(typ.Size())
+	if size == 1 {
+		// Booleans are represented as 0 or 1.
+		x = unsigned(x, size)
+	} else {
+		x = signed(x, size)
+	}
+	c, err := consts.NewInt(typ, x.String())
+	if err != nil {
+		// The value is within the range of the type after wrapping.
+		panic(err)
+	}
+	return c
+}
+
+// unsigned returns the value of x interpreted as an unsigned integer of the
+// given size, i.e. x modulo 2^size.
+func unsigned(x *big.Int, size uint) *big.Int {
+	m := new(big.Int).Lsh(big.NewInt(1), size)
+	return new(big.Int).Mod(x, m)
+}
+
+// signed returns the value of x interpreted as a signed integer of the given
+// size in two's complement representation (e.g. i1 true is -1).
+func signed(x *big.Int, size uint) *big.Int {
+	z := unsigned(x, size)
+	if z.Bit(int(size-1)) == 1 {
+		z.Sub(z, new(big.Int).Lsh(big.NewInt(1), size))
+	}
+	return z
+}
+
+// fitsSigned returns true if x is within the range of signed integers of the
+// given size, and false otherwise.
+func fitsSigned(x *big.Int, size uint) bool {
+	return signed(x, size).Cmp(x) == 0
+}
+
+// shiftAmount returns the shift amount y of a shift instruction on integers of
+// the given size. The boolean return value is false if the shift amount is
+// larger than or equal to the size, in which case the result is a poison value.
+func shiftAmount(y *big.Int, size uint) (uint, bool) {
+	y = unsigned(y, size)
+	if y.Cmp(big.NewInt(int64(size))) >= 0 {
+		return 0, false
+	}
+	return uint(y.Uint64()), true
+}
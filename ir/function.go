@@ -1,6 +1,12 @@
 package ir
 
-import "github.com/llir/llvm/types"
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/llir/llvm/types"
+	"github.com/llir/llvm/values"
+)
 
 // A Function declaration specifies the name and type of a function. A function
 // definition contains a set of basic blocks, interconnected by control flow
@@ -14,6 +20,254 @@ type Function struct {
 	Name string
 	// Function signature.
 	Sig *types.Func
+	// Function parameters.
+	Params []*values.Param
 	// Basic blocks of the function (or nil if function declaration).
 	Blocks []*BasicBlock
+	// Personality function used for exception handling (or nil if none), e.g.
+	//
+	//    i8* bitcast(i32 (...)* @__gxx_personality_v0 to i8*)
+	//
+	// References:
+	//    http://llvm.org/docs/LangRef.html#personalityfn
+	Personality values.Value
+	// Linkage type of the function.
+	Linkage Linkage
+	// Visibility style of the function.
+	Visibility Visibility
+	// DLL storage class of the function.
+	DLLStorageClass DLLStorageClass
+	// Calling convention of the function.
+	CallConv CallConv
+	// Attributes of the return value.
+	ResultAttrs ParamAttrs
+	// Attributes of the function parameters, indexed by parameter; or nil if
+	// none.
+	ParamAttrs []ParamAttrs
+	// Function attributes.
+	FuncAttrs FuncAttrs
+	// String function attributes (e.g. "target-cpu"="x86-64"); or nil if none.
+	StringAttrs StringAttrs
+	// Unwind table kind of the uwtable function attribute; only used if
+	// FuncAttrs has FuncUWTable set.
+	UWTableKind UWTableKind
+}
+
+// UWTableKind specifies the kind of unwind table required by the uwtable
+// function attribute.
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#function-attributes
+type UWTableKind int
+
+// Unwind table kinds.
+const (
+	UWTableDefault UWTableKind = iota // uwtable
+	UWTableSync                       // uwtable(sync)
+	UWTableAsync                      // uwtable(async)
+)
+
+// String returns the keyword of the unwind table kind (e.g. "sync"), or the
+// empty string for the default kind.
+func (kind UWTableKind) String() string {
+	switch kind {
+	case UWTableSync:
+		return "sync"
+	case UWTableAsync:
+		return "async"
+	}
+	return ""
+}
+
+// CallConv specifies the calling convention of a function or a function call.
+// The numeric values correspond to the calling convention IDs of LLVM.
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#callingconv
+type CallConv int
+
+// Calling conventions.
+const (
+	CallConvC             CallConv = 0  // ccc (default)
+	CallConvFast          CallConv = 8  // fastcc
+	CallConvCold          CallConv = 9  // coldcc
+	CallConvGHC           CallConv = 10 // ghccc
+	CallConvWebKitJS      CallConv = 12 // webkit_jscc
+	CallConvAnyReg        CallConv = 13 // anyregcc
+	CallConvPreserveMost  CallConv = 14 // preserve_mostcc
+	CallConvPreserveAll   CallConv = 15 // preserve_allcc
+	CallConvX86StdCall    CallConv = 64 // x86_stdcallcc
+	CallConvX86FastCall   CallConv = 65 // x86_fastcallcc
+	CallConvARMAPCS       CallConv = 66 // arm_apcscc
+	CallConvARMAAPCS      CallConv = 67 // arm_aapcscc
+	CallConvARMAAPCSVFP   CallConv = 68 // arm_aapcs_vfpcc
+	CallConvMSP430Intr    CallConv = 69 // msp430_intrcc
+	CallConvX86ThisCall   CallConv = 70 // x86_thiscallcc
+	CallConvPTXKernel     CallConv = 71 // ptx_kernel
+	CallConvPTXDevice     CallConv = 72 // ptx_device
+	CallConvSPIRFunc      CallConv = 75 // spir_func
+	CallConvSPIRKernel    CallConv = 76 // spir_kernel
+	CallConvIntelOCLBI    CallConv = 77 // intel_ocl_bicc
+	CallConvX86_64SysV    CallConv = 78 // x86_64_sysvcc
+	CallConvX86_64Win64   CallConv = 79 // x86_64_win64cc
+	CallConvX86VectorCall CallConv = 80 // x86_vectorcallcc
+)
+
+// callConvNames maps from calling convention to keyword.
+var callConvNames = map[CallConv]string{
+	CallConvC:             "ccc",
+	CallConvFast:          "fastcc",
+	CallConvCold:          "coldcc",
+	CallConvGHC:           "ghccc",
+	CallConvWebKitJS:      "webkit_jscc",
+	CallConvAnyReg:        "anyregcc",
+	CallConvPreserveMost:  "preserve_mostcc",
+	CallConvPreserveAll:   "preserve_allcc",
+	CallConvX86StdCall:    "x86_stdcallcc",
+	CallConvX86FastCall:   "x86_fastcallcc",
+	CallConvARMAPCS:       "arm_apcscc",
+	CallConvARMAAPCS:      "arm_aapcscc",
+	CallConvARMAAPCSVFP:   "arm_aapcs_vfpcc",
+	CallConvMSP430Intr:    "msp430_intrcc",
+	CallConvX86ThisCall:   "x86_thiscallcc",
+	CallConvPTXKernel:     "ptx_kernel",
+	CallConvPTXDevice:     "ptx_device",
+	CallConvSPIRFunc:      "spir_func",
+	CallConvSPIRKernel:    "spir_kernel",
+	CallConvIntelOCLBI:    "intel_ocl_bicc",
+	CallConvX86_64SysV:    "x86_64_sysvcc",
+	CallConvX86_64Win64:   "x86_64_win64cc",
+	CallConvX86VectorCall: "x86_vectorcallcc",
+}
+
+// String returns the keyword of the calling convention (e.g. "fastcc"), or its
+// numeric form (e.g. "cc 11") if it has no keyword.
+func (cc CallConv) String() string {
+	if s, ok := callConvNames[cc]; ok {
+		return s
+	}
+	return fmt.Sprintf("cc %d", int(cc))
+}
+
+// AppendBlock appends the given basic block to the function.
+func (f *Function) AppendBlock(block *BasicBlock) {
+	block.Parent = f
+	f.Blocks = append(f.Blocks, block)
+}
+
+// Entry returns the entry basic block of the function, or nil if the function
+// is a declaration.
+func (f *Function) Entry() *BasicBlock {
+	if len(f.Blocks) == 0 {
+		return nil
+	}
+	return f.Blocks[0]
+}
+
+// Type returns the type of the function, which is a pointer to its function
+// signature.
+func (f *Function) Type() types.Type {
+	typ, err := types.NewPointer(f.Sig)
+	if err != nil {
+		// Function types are always valid pointer element types.
+		panic(err)
+	}
+	return typ
+}
+
+// Ident returns the identifier associated with the function, e.g.
+//
+//    @printf
+func (f *Function) Ident() string {
+	return "@" + f.Name
+}
+
+// String returns a string representation of the function definition or
+// declaration, e.g.
+//
+//    declare i32 @printf(i8*, ...)
+//
+//    define i32 @f(i32 %x) {
+//    entry:
+//      ret i32 %x
+//    }
+func (f *Function) String() string {
+	return f.format(false, nil)
+}
+
+// format returns a string representation of the function definition or
+// declaration, in which the instructions are followed by their source comments
+// (if any) of the given side table. The labels of basic blocks are annotated
+// with their predecessors if annotate is true.
+func (f *Function) format(annotate bool, comments map[interface{}]string) string {
+	buf := new(bytes.Buffer)
+	if len(f.Blocks) == 0 {
+		buf.WriteString("declare ")
+	} else {
+		buf.WriteString("define ")
+	}
+	buf.WriteString(symbolPrefix(f.Linkage, f.Visibility, f.DLLStorageClass, false))
+	if f.CallConv != CallConvC {
+		fmt.Fprintf(buf, "%v ", f.CallConv)
+	}
+	if f.ResultAttrs != 0 {
+		fmt.Fprintf(buf, "%v ", f.ResultAttrs)
+	}
+	fmt.Fprintf(buf, "%v %s(", f.Sig.Result(), f.Ident())
+	params := f.Sig.Params()
+	for i, param := range params {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		// i8* noalias %p
+		buf.WriteString(param.String())
+		if i < len(f.ParamAttrs) && f.ParamAttrs[i] != 0 {
+			fmt.Fprintf(buf, " %v", f.ParamAttrs[i])
+		}
+		if i < len(f.Params) {
+			fmt.Fprintf(buf, " %s", f.Params[i].Ident())
+		}
+	}
+	if f.Sig.IsVariadic() {
+		if len(params) > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString("...")
+	}
+	buf.WriteString(")")
+	attrs := f.FuncAttrs
+	var uwtable string
+	if attrs.Has(FuncUWTable) && f.UWTableKind != UWTableDefault {
+		// uwtable is last in the canonical order of function attributes.
+		attrs &^= FuncUWTable
+		uwtable = fmt.Sprintf("uwtable(%v)", f.UWTableKind)
+	}
+	if attrs != 0 {
+		fmt.Fprintf(buf, " %v", attrs)
+	}
+	if len(uwtable) > 0 {
+		fmt.Fprintf(buf, " %s", uwtable)
+	}
+	if len(f.StringAttrs) > 0 {
+		fmt.Fprintf(buf, " %v", f.StringAttrs)
+	}
+	if f.Personality != nil {
+		fmt.Fprintf(buf, " personality %v", f.Personality)
+	}
+	if len(f.Blocks) == 0 {
+		return buf.String()
+	}
+	buf.WriteString(" {\n")
+	var preds map[*BasicBlock][]*BasicBlock
+	if annotate {
+		preds = Predecessors(f)
+	}
+	for i, block := range f.Blocks {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		buf.WriteString(block.format(preds[block], comments))
+	}
+	buf.WriteString("}")
+	return buf.String()
 }
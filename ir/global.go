@@ -0,0 +1,194 @@
+package ir
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/llir/llvm/types"
+	"github.com/llir/llvm/values"
+)
+
+// A Global represents a global variable definition or an external global
+// variable declaration.
+//
+// Examples:
+//    @x = global i32 42
+//    @y = external global i32
+//    @s = constant [3 x i8] c"foo"
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#global-variables
+type Global struct {
+	// Global variable name.
+	Name string
+	// Content type.
+	Content types.Type
+	// Initial value (or nil if external declaration).
+	Init values.Value
+	// Specifies if the content of the global variable is immutable.
+	IsConst bool
+	// Linkage type of the global variable.
+	Linkage Linkage
+	// Visibility style of the global variable.
+	Visibility Visibility
+	// DLL storage class of the global variable.
+	DLLStorageClass DLLStorageClass
+}
+
+// Linkage specifies the linkage type of a global variable or function, which
+// controls the visibility of its symbol to other modules.
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#linkage-types
+type Linkage int
+
+// Linkage types.
+const (
+	LinkageExternal            Linkage = iota // external (default)
+	LinkagePrivate                            // private
+	LinkageInternal                           // internal
+	LinkageAvailableExternally                // available_externally
+	LinkageLinkOnce                           // linkonce
+	LinkageWeak                               // weak
+	LinkageCommon                             // common
+	LinkageAppending                          // appending
+	LinkageExternWeak                         // extern_weak
+	LinkageLinkOnceODR                        // linkonce_odr
+	LinkageWeakODR                            // weak_odr
+)
+
+// linkageNames maps from linkage type to keyword.
+var linkageNames = map[Linkage]string{
+	LinkageExternal:            "external",
+	LinkagePrivate:             "private",
+	LinkageInternal:            "internal",
+	LinkageAvailableExternally: "available_externally",
+	LinkageLinkOnce:            "linkonce",
+	LinkageWeak:                "weak",
+	LinkageCommon:              "common",
+	LinkageAppending:           "appending",
+	LinkageExternWeak:          "extern_weak",
+	LinkageLinkOnceODR:         "linkonce_odr",
+	LinkageWeakODR:             "weak_odr",
+}
+
+// String returns the keyword of the linkage type (e.g. "internal").
+func (linkage Linkage) String() string {
+	if s, ok := linkageNames[linkage]; ok {
+		return s
+	}
+	return fmt.Sprintf("Linkage(%d)", int(linkage))
+}
+
+// Visibility specifies the visibility style of a global variable or function.
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#visibility-styles
+type Visibility int
+
+// Visibility styles.
+const (
+	VisibilityDefault   Visibility = iota // default
+	VisibilityHidden                      // hidden
+	VisibilityProtected                   // protected
+)
+
+// String returns the keyword of the visibility style (e.g. "hidden").
+func (visibility Visibility) String() string {
+	switch visibility {
+	case VisibilityDefault:
+		return "default"
+	case VisibilityHidden:
+		return "hidden"
+	case VisibilityProtected:
+		return "protected"
+	}
+	return fmt.Sprintf("Visibility(%d)", int(visibility))
+}
+
+// DLLStorageClass specifies the DLL storage class of a global variable or
+// function.
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#dll-storage-classes
+type DLLStorageClass int
+
+// DLL storage classes.
+const (
+	DLLStorageNone   DLLStorageClass = iota // none (default)
+	DLLStorageImport                        // dllimport
+	DLLStorageExport                        // dllexport
+)
+
+// String returns the keyword of the DLL storage class (e.g. "dllexport").
+func (class DLLStorageClass) String() string {
+	switch class {
+	case DLLStorageNone:
+		return "none"
+	case DLLStorageImport:
+		return "dllimport"
+	case DLLStorageExport:
+		return "dllexport"
+	}
+	return fmt.Sprintf("DLLStorageClass(%d)", int(class))
+}
+
+// symbolPrefix returns the linkage type, visibility style and DLL storage class
+// keywords of a global variable or function, each followed by a space, e.g.
+//
+//    "internal hidden dllexport "
+//
+// Default values are omitted, except for the external linkage of declarations
+// if explicit is true.
+func symbolPrefix(linkage Linkage, visibility Visibility, class DLLStorageClass, explicit bool) string {
+	buf := new(bytes.Buffer)
+	if linkage != LinkageExternal || explicit {
+		fmt.Fprintf(buf, "%v ", linkage)
+	}
+	if visibility != VisibilityDefault {
+		fmt.Fprintf(buf, "%v ", visibility)
+	}
+	if class != DLLStorageNone {
+		fmt.Fprintf(buf, "%v ", class)
+	}
+	return buf.String()
+}
+
+// Type returns the type of the global variable, which is a pointer to its
+// content type.
+func (global *Global) Type() types.Type {
+	typ, err := types.NewPointer(global.Content)
+	if err != nil {
+		// Invalid content type (e.g. void or label).
+		panic(err)
+	}
+	return typ
+}
+
+// Ident returns the identifier associated with the global variable, e.g.
+//
+//    @x
+func (global *Global) Ident() string {
+	return "@" + global.Name
+}
+
+// String returns a string representation of the global variable definition or
+// declaration, e.g.
+//
+//    @x = global i32 42
+//    @y = external global i32
+//    @s = constant [3 x i8] c"foo"
+//    @g = private global i32 0
+func (global *Global) String() string {
+	kind := "global"
+	if global.IsConst {
+		kind = "constant"
+	}
+	// External linkage is implied by definitions, and explicit for external
+	// declarations.
+	kind = symbolPrefix(global.Linkage, global.Visibility, global.DLLStorageClass, global.Init == nil) + kind
+	if global.Init == nil {
+		return fmt.Sprintf("%s = %s %v", global.Ident(), kind, global.Content)
+	}
+	return fmt.Sprintf("%s = %s %v", global.Ident(), kind, global.Init)
+}
@@ -0,0 +1,221 @@
+package ir
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/llir/llvm/types"
+	"github.com/llir/llvm/values"
+)
+
+// InferType returns the result type of the given value-producing instruction,
+// as derived from its operands. An error is returned if the operands are
+// invalid, or if the result type cannot be derived unambiguously.
+func InferType(inst Instruction) (types.Type, error) {
+	switch inst := inst.(type) {
+	// Binary operations.
+	case *AddInst:
+		return binaryType("add", inst.Op1, inst.Op2)
+	case *FaddInst:
+		return binaryType("fadd", inst.Op1, inst.Op2)
+	case *SubInst:
+		return binaryType("sub", inst.Op1, inst.Op2)
+	case *FsubInst:
+		return binaryType("fsub", inst.Op1, inst.Op2)
+	case *MulInst:
+		return binaryType("mul", inst.Op1, inst.Op2)
+	case *FmulInst:
+		return binaryType("fmul", inst.Op1, inst.Op2)
+	case *UdivInst:
+		return binaryType("udiv", inst.Op1, inst.Op2)
+	case *SdivInst:
+		return binaryType("sdiv", inst.Op1, inst.Op2)
+	case *FdivInst:
+		return binaryType("fdiv", inst.Op1, inst.Op2)
+	case *UremInst:
+		return binaryType("urem", inst.Op1, inst.Op2)
+	case *SremInst:
+		return binaryType("srem", inst.Op1, inst.Op2)
+	case *FremInst:
+		return binaryType("frem", inst.Op1, inst.Op2)
+
+	// Bitwise binary operations.
+	case *ShlInst:
+		return binaryType("shl", inst.Op1, inst.Op2)
+	case *LshrInst:
+		return binaryType("lshr", inst.Op1, inst.Op2)
+	case *AshrInst:
+		return binaryType("ashr", inst.Op1, inst.Op2)
+	case *AndInst:
+		return binaryType("and", inst.Op1, inst.Op2)
+	case *OrInst:
+		return binaryType("or", inst.Op1, inst.Op2)
+	case *XorInst:
+		return binaryType("xor", inst.Op1, inst.Op2)
+
+	// Aggregate operations.
+	case *ExtractvalueInst:
+		if inst.Aggregate == nil {
+			return nil, errors.New("unable to infer result type of extractvalue; missing aggregate")
+		}
+		typ, err := aggregateElem(inst.Aggregate.Type(), inst.Indices)
+		if err != nil {
+			return nil, fmt.Errorf("invalid extractvalue; %v", err)
+		}
+		return typ, nil
+	case *InsertvalueInst:
+		if inst.Aggregate == nil {
+			return nil, errors.New("unable to infer result type of insertvalue; missing aggregate")
+		}
+		return inst.Aggregate.Type(), nil
+
+	// Memory access and addressing operations.
+	case *AllocaInst:
+		return types.NewPointer(inst.Type)
+	case *LoadInst:
+		if inst.Addr == nil {
+			return nil, errors.New("unable to infer result type of load; missing address")
+		}
+		typ, ok := inst.Addr.Type().(*types.Pointer)
+		if !ok {
+			return nil, fmt.Errorf("invalid load address type; expected pointer, got %q", inst.Addr.Type())
+		}
+		return typ.Elem(), nil
+	case *GetelementptrInst:
+		if inst.Type == nil {
+			if inst.Ptr == nil {
+				return nil, errors.New("unable to infer result type of getelementptr; missing pointer")
+			}
+			typ, ok := inst.Ptr.Type().(*types.Pointer)
+			if !ok {
+				return nil, fmt.Errorf("invalid getelementptr pointer type; expected pointer, got %q", inst.Ptr.Type())
+			}
+			c := *inst
+			c.Type = typ.Elem()
+			return c.ResultType()
+		}
+		return inst.ResultType()
+
+	// Conversion operations.
+	case *TruncInst:
+		return inst.To, nil
+	case *ZextInst:
+		return inst.To, nil
+	case *SextInst:
+		return inst.To, nil
+	case *FptruncInst:
+		return inst.To, nil
+	case *FpextInst:
+		return inst.To, nil
+	case *FptouiInst:
+		return inst.To, nil
+	case *FptosiInst:
+		return inst.To, nil
+	case *UitofpInst:
+		return inst.To, nil
+	case *SitofpInst:
+		return inst.To, nil
+	case *PtrtointInst:
+		return inst.To, nil
+	case *InttoptrInst:
+		return inst.To, nil
+	case *BitcastInst:
+		return inst.To, nil
+	case *AddrspacecastInst:
+		return inst.To, nil
+
+	// Other operations.
+	case *IcmpInst:
+		return cmpType("icmp", inst.Op1, inst.Op2)
+	case *FcmpInst:
+		return cmpType("fcmp", inst.Op1, inst.Op2)
+	case *PhiInst:
+		var typ types.Type
+		for _, pred := range inst.predNames() {
+			v := inst.Preds[pred]
+			if v == nil {
+				return nil, fmt.Errorf("unable to infer result type of phi; missing incoming value for %q", pred)
+			}
+			if typ == nil {
+				typ = v.Type()
+			} else if !typ.Equal(v.Type()) {
+				return nil, fmt.Errorf("invalid phi incoming values; type mismatch between %q and %q", typ, v.Type())
+			}
+		}
+		if typ == nil {
+			return nil, errors.New("unable to infer result type of phi; missing incoming values")
+		}
+		return typ, nil
+	case *SelectInst:
+		return binaryType("select", inst.TrueValue, inst.FalseValue)
+	case *CallInst:
+		if inst.Callee == nil {
+			return nil, errors.New("unable to infer result type of call; missing callee")
+		}
+		sig, ok := calleeSig(inst.Callee.Type())
+		if !ok {
+			return nil, fmt.Errorf("invalid callee type; expected function (or pointer to function), got %q", inst.Callee.Type())
+		}
+		return sig.Result(), nil
+	case *LandingpadInst:
+		// The result type of landingpad is declared rather than derived.
+		if inst.Type == nil {
+			return nil, errors.New("unable to infer result type of landingpad; missing type")
+		}
+		return inst.Type, nil
+	}
+	return nil, fmt.Errorf("unable to infer result type of instruction %T", inst)
+}
+
+// binaryType returns the result type of the given binary instruction with the
+// operands x and y, which is the type of its operands. The operands must be of
+// the kind accepted by the instruction.
+func binaryType(mnem string, x, y values.Value) (types.Type, error) {
+	if x == nil || y == nil {
+		return nil, fmt.Errorf("unable to infer result type of %s; missing operand", mnem)
+	}
+	if !x.Type().Equal(y.Type()) {
+		return nil, fmt.Errorf("invalid %s operands; type mismatch between %q and %q", mnem, x.Type(), y.Type())
+	}
+	if err := checkOperandKind(mnem, x.Type()); err != nil {
+		return nil, err
+	}
+	return x.Type(), nil
+}
+
+// checkOperandKind verifies that the operand type of the given binary or
+// comparison instruction is of the kind accepted by the instruction; integers
+// (or vectors of integers) for integer operations, floating points (or vectors
+// of floating points) for floating point operations, and integers or pointers
+// (or vectors thereof) for icmp.
+func checkOperandKind(mnem string, typ types.Type) error {
+	switch mnem {
+	case "add", "sub", "mul", "udiv", "sdiv", "urem", "srem", "shl", "lshr", "ashr", "and", "or", "xor":
+		if !types.IsInts(typ) {
+			return fmt.Errorf("invalid %s operand type; expected integer (or vector of integers), got %q", mnem, typ)
+		}
+	case "fadd", "fsub", "fmul", "fdiv", "frem", "fcmp":
+		if !types.IsFloats(typ) {
+			return fmt.Errorf("invalid %s operand type; expected floating point (or vector of floating points), got %q", mnem, typ)
+		}
+	case "icmp":
+		if !types.IsInts(typ) && !types.IsPointers(typ) {
+			return fmt.Errorf("invalid icmp operand type; expected integer or pointer (or vector thereof), got %q", typ)
+		}
+	}
+	return nil
+}
+
+// cmpType returns the result type of the given comparison instruction with the
+// operands x and y, which is i1 for scalar operands and a vector of i1 for
+// vector operands.
+func cmpType(mnem string, x, y values.Value) (types.Type, error) {
+	typ, err := binaryType(mnem, x, y)
+	if err != nil {
+		return nil, err
+	}
+	if typ, ok := typ.(*types.Vector); ok {
+		return types.NewVector(types.I1, typ.Len())
+	}
+	return types.I1, nil
+}
@@ -1,6 +1,12 @@
 package ir
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
 	"github.com/llir/llvm/types"
 	"github.com/llir/llvm/values"
 )
@@ -11,18 +17,79 @@ import (
 //    * binary instructions [1]
 //    * bitwise binary instructions [2]
 //    * memory instructions [3]
-//    * other instructions [4]
+//    * conversion instructions [4]
+//    * other instructions [5]
 //
 //    [1]: http://llvm.org/docs/LangRef.html#binaryops
 //    [2]: http://llvm.org/docs/LangRef.html#bitwiseops
 //    [3]: http://llvm.org/docs/LangRef.html#memoryops
-//    [4]: http://llvm.org/docs/LangRef.html#otherops
+//    [4]: http://llvm.org/docs/LangRef.html#conversion-operations
+//    [5]: http://llvm.org/docs/LangRef.html#otherops
 type Instruction interface {
+	// Clone returns a copy of the instruction.
+	Clone() Instruction
+	// Operands returns the value operands of the instruction.
+	Operands() []values.Value
+	// SetOperand sets the i:th value operand of the instruction to v.
+	SetOperand(i int, v values.Value) error
 	// isInst ensures that only non-terminator instructions can be assigned to
 	// the Instruction interface.
 	isInst()
 }
 
+// LocalIdent is embedded in value-producing instructions, and specifies the name
+// of the local variable holding the result (e.g. "x" for %x and "1" for %1).
+// Unnamed results may be assigned sequential numeric names using the AssignIDs
+// method of basic blocks.
+type LocalIdent struct {
+	// Local variable name; or empty if unnamed.
+	Name string
+}
+
+// name returns the name of the local variable holding the result.
+func (ident *LocalIdent) name() string {
+	return ident.Name
+}
+
+// setName sets the name of the local variable holding the result.
+func (ident *LocalIdent) setName(name string) {
+	ident.Name = name
+}
+
+// assign returns the string representation s of an instruction, preceded by
+// the name of its result if named, e.g.
+//
+//    %x = add i32 %a, %b
+func (ident *LocalIdent) assign(s string) string {
+	if len(ident.Name) == 0 {
+		return s
+	}
+	return fmt.Sprintf("%%%s = %s", ident.Name, s)
+}
+
+// A result is a value-producing instruction.
+type result interface {
+	// name returns the name of the local variable holding the result.
+	name() string
+	// setName sets the name of the local variable holding the result.
+	setName(name string)
+}
+
+// NewLocal returns a reference to the result of the given value-producing
+// instruction, which has the specified result type. The result must be named,
+// either explicitly or using the AssignIDs method of basic blocks.
+func NewLocal(inst Instruction, typ types.Type) (*values.Local, error) {
+	res, ok := inst.(result)
+	if !ok || isVoid(inst) {
+		return nil, fmt.Errorf("invalid local reference; instruction %T does not produce a value", inst)
+	}
+	name := res.name()
+	if len(name) == 0 {
+		return nil, fmt.Errorf("invalid local reference; unnamed result of instruction %T", inst)
+	}
+	return values.NewLocal(name, typ), nil
+}
+
 // =============================================================================
 // Binary Operations
 //
@@ -33,7 +100,7 @@ type Instruction interface {
 // vectors of integer values.
 //
 // Syntax:
-//    <Result> = add <Type> <Op1>, <Op2>
+//    <Result> = add [nuw] [nsw] <Type> <Op1>, <Op2>
 //
 // Semantics:
 //    Result = Op1 + Op2;
@@ -41,17 +108,33 @@ type Instruction interface {
 // References:
 //    http://llvm.org/docs/LangRef.html#i-add
 type AddInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
 	// Operand type.
 	Type types.Type
 	// Operands.
 	Op1, Op2 values.Value
+	// Produce a poison value on unsigned overflow.
+	NUW bool
+	// Produce a poison value on signed overflow.
+	NSW bool
+	// Metadata attachments.
+	Metadata
+}
+
+// String returns a string representation of the add instruction, e.g.
+//
+//    %x = add i32 %a, %b
+//    %x = add nuw nsw i32 %a, %b
+func (inst *AddInst) String() string {
+	return inst.attach(inst.assign(binaryString(overflowFlags("add", inst.NUW, inst.NSW), inst.Type, inst.Op1, inst.Op2)))
 }
 
 // The FaddInst returns the sum of its two operands, which may be floating point
 // values or vectors of floating point values.
 //
 // Syntax:
-//    <Result> = fadd <Type> <Op1>, <Op2>
+//    <Result> = fadd [FastMathFlags] <Type> <Op1>, <Op2>
 //
 // Semantics:
 //    Result = Op1 + Op2;
@@ -59,17 +142,31 @@ type AddInst struct {
 // References:
 //    http://llvm.org/docs/LangRef.html#i-fadd
 type FaddInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
 	// Operand type.
 	Type types.Type
 	// Operands.
 	Op1, Op2 values.Value
+	// Fast-math flags.
+	FastMath FastMathFlags
+	// Metadata attachments.
+	Metadata
+}
+
+// String returns a string representation of the fadd instruction, e.g.
+//
+//    %x = fadd float %a, %b
+//    %x = fadd fast float %a, %b
+func (inst *FaddInst) String() string {
+	return inst.attach(inst.assign(binaryString(fastMath("fadd", inst.FastMath), inst.Type, inst.Op1, inst.Op2)))
 }
 
 // The SubInst returns the difference of its two operands, which may be integers
 // or vectors of integer values.
 //
 // Syntax:
-//    <Result> = sub <Type> <Op1>, <Op2>
+//    <Result> = sub [nuw] [nsw] <Type> <Op1>, <Op2>
 //
 // Semantics:
 //    Result = Op1 - Op2;
@@ -77,17 +174,33 @@ type FaddInst struct {
 // References:
 //    http://llvm.org/docs/LangRef.html#sub-instruction
 type SubInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
 	// Operand type.
 	Type types.Type
 	// Operands.
 	Op1, Op2 values.Value
+	// Produce a poison value on unsigned overflow.
+	NUW bool
+	// Produce a poison value on signed overflow.
+	NSW bool
+	// Metadata attachments.
+	Metadata
+}
+
+// String returns a string representation of the sub instruction, e.g.
+//
+//    %x = sub i32 %a, %b
+//    %x = sub nuw nsw i32 %a, %b
+func (inst *SubInst) String() string {
+	return inst.attach(inst.assign(binaryString(overflowFlags("sub", inst.NUW, inst.NSW), inst.Type, inst.Op1, inst.Op2)))
 }
 
 // The FsubInst returns the difference of its two operands, which may be
 // floating point values or vectors of floating point values.
 //
 // Syntax:
-//    <Result> = fsub <Type> <Op1>, <Op2>
+//    <Result> = fsub [FastMathFlags] <Type> <Op1>, <Op2>
 //
 // Semantics:
 //    Result = Op1 - Op2;
@@ -95,17 +208,31 @@ type SubInst struct {
 // References:
 //    http://llvm.org/docs/LangRef.html#i-fsub
 type FsubInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
 	// Operand type.
 	Type types.Type
 	// Operands.
 	Op1, Op2 values.Value
+	// Fast-math flags.
+	FastMath FastMathFlags
+	// Metadata attachments.
+	Metadata
+}
+
+// String returns a string representation of the fsub instruction, e.g.
+//
+//    %x = fsub float %a, %b
+//    %x = fsub fast float %a, %b
+func (inst *FsubInst) String() string {
+	return inst.attach(inst.assign(binaryString(fastMath("fsub", inst.FastMath), inst.Type, inst.Op1, inst.Op2)))
 }
 
 // The MulInst returns the product of its two operands, which may be integers or
 // vectors of integer values.
 //
 // Syntax:
-//    <Result> = mul <Type> <Op1>, <Op2>
+//    <Result> = mul [nuw] [nsw] <Type> <Op1>, <Op2>
 //
 // Semantics:
 //    Result = Op1 * Op2;
@@ -113,17 +240,33 @@ type FsubInst struct {
 // References:
 //    http://llvm.org/docs/LangRef.html#mul-instruction
 type MulInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
 	// Operand type.
 	Type types.Type
 	// Operands.
 	Op1, Op2 values.Value
+	// Produce a poison value on unsigned overflow.
+	NUW bool
+	// Produce a poison value on signed overflow.
+	NSW bool
+	// Metadata attachments.
+	Metadata
+}
+
+// String returns a string representation of the mul instruction, e.g.
+//
+//    %x = mul i32 %a, %b
+//    %x = mul nuw nsw i32 %a, %b
+func (inst *MulInst) String() string {
+	return inst.attach(inst.assign(binaryString(overflowFlags("mul", inst.NUW, inst.NSW), inst.Type, inst.Op1, inst.Op2)))
 }
 
 // The FmulInst returns the product of its two operands, which may be floating
 // point values or vectors of floating point values.
 //
 // Syntax:
-//    <Result> = fmul <Type> <Op1>, <Op2>
+//    <Result> = fmul [FastMathFlags] <Type> <Op1>, <Op2>
 //
 // Semantics:
 //    Result = Op1 * Op2;
@@ -131,17 +274,31 @@ type MulInst struct {
 // References:
 //    http://llvm.org/docs/LangRef.html#fmul-instruction
 type FmulInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
 	// Operand type.
 	Type types.Type
 	// Operands.
 	Op1, Op2 values.Value
+	// Fast-math flags.
+	FastMath FastMathFlags
+	// Metadata attachments.
+	Metadata
+}
+
+// String returns a string representation of the fmul instruction, e.g.
+//
+//    %x = fmul float %a, %b
+//    %x = fmul fast float %a, %b
+func (inst *FmulInst) String() string {
+	return inst.attach(inst.assign(binaryString(fastMath("fmul", inst.FastMath), inst.Type, inst.Op1, inst.Op2)))
 }
 
 // The UdivInst returns the unsigned integer quotient of its two operands, which
 // may be integers or vectors of integer values.
 //
 // Syntax:
-//    <Result> = udiv <Type> <Op1>, <Op2>
+//    <Result> = udiv [exact] <Type> <Op1>, <Op2>
 //
 // Semantics:
 //    Result = Op1 / Op2;
@@ -149,17 +306,31 @@ type FmulInst struct {
 // References:
 //    http://llvm.org/docs/LangRef.html#udiv-instruction
 type UdivInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
 	// Operand type.
 	Type types.Type
 	// Operands.
 	Op1, Op2 values.Value
+	// Produce a poison value if Op1 is not a multiple of Op2.
+	Exact bool
+	// Metadata attachments.
+	Metadata
+}
+
+// String returns a string representation of the udiv instruction, e.g.
+//
+//    %x = udiv i32 %a, %b
+//    %x = udiv exact i32 %a, %b
+func (inst *UdivInst) String() string {
+	return inst.attach(inst.assign(binaryString(exactFlag("udiv", inst.Exact), inst.Type, inst.Op1, inst.Op2)))
 }
 
 // The SdivInst returns the signed integer quotient of its two operands, which
 // may be integers or vectors of integer values.
 //
 // Syntax:
-//    <Result> = sdiv <Type> <Op1>, <Op2>
+//    <Result> = sdiv [exact] <Type> <Op1>, <Op2>
 //
 // Semantics:
 //    Result = Op1 / Op2;
@@ -167,17 +338,31 @@ type UdivInst struct {
 // References:
 //    http://llvm.org/docs/LangRef.html#sdiv-instruction
 type SdivInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
 	// Operand type.
 	Type types.Type
 	// Operands.
 	Op1, Op2 values.Value
+	// Produce a poison value if Op1 is not a multiple of Op2.
+	Exact bool
+	// Metadata attachments.
+	Metadata
+}
+
+// String returns a string representation of the sdiv instruction, e.g.
+//
+//    %x = sdiv i32 %a, %b
+//    %x = sdiv exact i32 %a, %b
+func (inst *SdivInst) String() string {
+	return inst.attach(inst.assign(binaryString(exactFlag("sdiv", inst.Exact), inst.Type, inst.Op1, inst.Op2)))
 }
 
 // The FdivInst returns the quotient of its two operands, which may be floating
 // point values or vectors of floating point values.
 //
 // Syntax:
-//    <Result> = fdiv <Type> <Op1>, <Op2>
+//    <Result> = fdiv [FastMathFlags] <Type> <Op1>, <Op2>
 //
 // Semantics:
 //    Result = Op1 / Op2;
@@ -185,10 +370,24 @@ type SdivInst struct {
 // References:
 //    http://llvm.org/docs/LangRef.html#fdiv-instruction
 type FdivInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
 	// Operand type.
 	Type types.Type
 	// Operands.
 	Op1, Op2 values.Value
+	// Fast-math flags.
+	FastMath FastMathFlags
+	// Metadata attachments.
+	Metadata
+}
+
+// String returns a string representation of the fdiv instruction, e.g.
+//
+//    %x = fdiv float %a, %b
+//    %x = fdiv fast float %a, %b
+func (inst *FdivInst) String() string {
+	return inst.attach(inst.assign(binaryString(fastMath("fdiv", inst.FastMath), inst.Type, inst.Op1, inst.Op2)))
 }
 
 // The UremInst returns the unsigned integer remainder of a division between its
@@ -203,10 +402,21 @@ type FdivInst struct {
 // References:
 //    http://llvm.org/docs/LangRef.html#urem-instruction
 type UremInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
 	// Operand type.
 	Type types.Type
 	// Operands.
 	Op1, Op2 values.Value
+	// Metadata attachments.
+	Metadata
+}
+
+// String returns a string representation of the urem instruction, e.g.
+//
+//    %x = urem i32 %a, %b
+func (inst *UremInst) String() string {
+	return inst.attach(inst.assign(binaryString("urem", inst.Type, inst.Op1, inst.Op2)))
 }
 
 // The SremInst returns the signed integer remainder of a division between its
@@ -221,17 +431,28 @@ type UremInst struct {
 // References:
 //    http://llvm.org/docs/LangRef.html#srem-instruction
 type SremInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
 	// Operand type.
 	Type types.Type
 	// Operands.
 	Op1, Op2 values.Value
+	// Metadata attachments.
+	Metadata
+}
+
+// String returns a string representation of the srem instruction, e.g.
+//
+//    %x = srem i32 %a, %b
+func (inst *SremInst) String() string {
+	return inst.attach(inst.assign(binaryString("srem", inst.Type, inst.Op1, inst.Op2)))
 }
 
 // The FremInst returns the remainder of a division between its two operands,
 // which may be floating point values or vectors of floating point values.
 //
 // Syntax:
-//    <Result> = frem <Type> <Op1>, <Op2>
+//    <Result> = frem [FastMathFlags] <Type> <Op1>, <Op2>
 //
 // Semantics:
 //    Result = Op1 % Op2;
@@ -239,10 +460,96 @@ type SremInst struct {
 // References:
 //    http://llvm.org/docs/LangRef.html#frem-instruction
 type FremInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
 	// Operand type.
 	Type types.Type
 	// Operands.
 	Op1, Op2 values.Value
+	// Fast-math flags.
+	FastMath FastMathFlags
+	// Metadata attachments.
+	Metadata
+}
+
+// String returns a string representation of the frem instruction, e.g.
+//
+//    %x = frem float %a, %b
+//    %x = frem fast float %a, %b
+func (inst *FremInst) String() string {
+	return inst.attach(inst.assign(binaryString(fastMath("frem", inst.FastMath), inst.Type, inst.Op1, inst.Op2)))
+}
+
+// FastMathFlags is a set of fast-math flags, which enable otherwise unsafe
+// floating point optimizations.
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#fast-math-flags
+type FastMathFlags uint8
+
+// Fast-math flags.
+const (
+	FastReassoc       FastMathFlags = 1 << iota // reassoc:  allow reassociation
+	FastNoNaNs                                  // nnan:     assume no NaNs
+	FastNoInfs                                  // ninf:     assume no infinities
+	FastNoSignedZeros                           // nsz:      ignore the sign of zero
+	FastAllowRecip                              // arcp:     allow reciprocal
+	FastContract                                // contract: allow floating point contraction
+	FastApproxFunc                              // afn:      allow approximate functions
+
+	// fast: all fast-math flags.
+	Fast = FastReassoc | FastNoNaNs | FastNoInfs | FastNoSignedZeros | FastAllowRecip | FastContract | FastApproxFunc
+)
+
+// Has returns true if all of the given flags are set, and false otherwise.
+func (flags FastMathFlags) Has(f FastMathFlags) bool {
+	return flags&f == f
+}
+
+// Set sets the given flags.
+func (flags *FastMathFlags) Set(f FastMathFlags) {
+	*flags |= f
+}
+
+// String returns a string representation of the fast-math flags in canonical
+// order, e.g.
+//
+//    fast
+//    nnan ninf
+func (flags FastMathFlags) String() string {
+	if flags.Has(Fast) {
+		return "fast"
+	}
+	names := []struct {
+		flag FastMathFlags
+		name string
+	}{
+		{flag: FastReassoc, name: "reassoc"},
+		{flag: FastNoNaNs, name: "nnan"},
+		{flag: FastNoInfs, name: "ninf"},
+		{flag: FastNoSignedZeros, name: "nsz"},
+		{flag: FastAllowRecip, name: "arcp"},
+		{flag: FastContract, name: "contract"},
+		{flag: FastApproxFunc, name: "afn"},
+	}
+	var s []string
+	for _, n := range names {
+		if flags.Has(n.flag) {
+			s = append(s, n.name)
+		}
+	}
+	return strings.Join(s, " ")
+}
+
+// fastMath returns the given mnemonic followed by the fast-math flags, if any,
+// e.g.
+//
+//    fadd nnan ninf
+func fastMath(mnem string, flags FastMathFlags) string {
+	if flags == 0 {
+		return mnem
+	}
+	return mnem + " " + flags.String()
 }
 
 // =============================================================================
@@ -255,7 +562,7 @@ type FremInst struct {
 // of bits. The arguments may be integers or vectors of integer values.
 //
 // Syntax:
-//    <Result> = shl <Type> <Op1>, <Op2>
+//    <Result> = shl [nuw] [nsw] <Type> <Op1>, <Op2>
 //
 // Semantics:
 //    Result = Op1 << Op2;
@@ -263,10 +570,26 @@ type FremInst struct {
 // References:
 //    http://llvm.org/docs/LangRef.html#shl-instruction
 type ShlInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
 	// Operand type.
 	Type types.Type
 	// Operands.
 	Op1, Op2 values.Value
+	// Produce a poison value on unsigned overflow.
+	NUW bool
+	// Produce a poison value on signed overflow.
+	NSW bool
+	// Metadata attachments.
+	Metadata
+}
+
+// String returns a string representation of the shl instruction, e.g.
+//
+//    %x = shl i32 %a, %b
+//    %x = shl nuw nsw i32 %a, %b
+func (inst *ShlInst) String() string {
+	return inst.attach(inst.assign(binaryString(overflowFlags("shl", inst.NUW, inst.NSW), inst.Type, inst.Op1, inst.Op2)))
 }
 
 // The LshrInst (logical shift right) returns the first operand shifted to the
@@ -274,7 +597,7 @@ type ShlInst struct {
 // integers or vectors of integer values.
 //
 // Syntax:
-//    <Result> = lshr <Type> <Op1>, <Op2>
+//    <Result> = lshr [exact] <Type> <Op1>, <Op2>
 //
 // Semantics:
 //    Result = Op1 >> Op2;
@@ -282,10 +605,24 @@ type ShlInst struct {
 // References:
 //    http://llvm.org/docs/LangRef.html#lshr-instruction
 type LshrInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
 	// Operand type.
 	Type types.Type
 	// Operands.
 	Op1, Op2 values.Value
+	// Produce a poison value if any non-zero bits are shifted out.
+	Exact bool
+	// Metadata attachments.
+	Metadata
+}
+
+// String returns a string representation of the lshr instruction, e.g.
+//
+//    %x = lshr i32 %a, %b
+//    %x = lshr exact i32 %a, %b
+func (inst *LshrInst) String() string {
+	return inst.attach(inst.assign(binaryString(exactFlag("lshr", inst.Exact), inst.Type, inst.Op1, inst.Op2)))
 }
 
 // The AshrInst (arithmetic shift right) returns the first operand shifted to
@@ -293,7 +630,7 @@ type LshrInst struct {
 // be integers or vectors of integer values.
 //
 // Syntax:
-//    <Result> = ashr <Type> <Op1>, <Op2>
+//    <Result> = ashr [exact] <Type> <Op1>, <Op2>
 //
 // Semantics:
 //    Result = Op1 >> Op2; // right shift with sign extension.
@@ -301,10 +638,24 @@ type LshrInst struct {
 // References:
 //    http://llvm.org/docs/LangRef.html#ashr-instruction
 type AshrInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
 	// Operand type.
 	Type types.Type
 	// Operands.
 	Op1, Op2 values.Value
+	// Produce a poison value if any non-zero bits are shifted out.
+	Exact bool
+	// Metadata attachments.
+	Metadata
+}
+
+// String returns a string representation of the ashr instruction, e.g.
+//
+//    %x = ashr i32 %a, %b
+//    %x = ashr exact i32 %a, %b
+func (inst *AshrInst) String() string {
+	return inst.attach(inst.assign(binaryString(exactFlag("ashr", inst.Exact), inst.Type, inst.Op1, inst.Op2)))
 }
 
 // The AndInst returns the bitwise logical and of its two operands, which may be
@@ -319,10 +670,21 @@ type AshrInst struct {
 // References:
 //    http://llvm.org/docs/LangRef.html#and-instruction
 type AndInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
 	// Operand type.
 	Type types.Type
 	// Operands.
 	Op1, Op2 values.Value
+	// Metadata attachments.
+	Metadata
+}
+
+// String returns a string representation of the and instruction, e.g.
+//
+//    %x = and i32 %a, %b
+func (inst *AndInst) String() string {
+	return inst.attach(inst.assign(binaryString("and", inst.Type, inst.Op1, inst.Op2)))
 }
 
 // The OrInst returns the bitwise logical inclusive or of its two operands,
@@ -337,10 +699,21 @@ type AndInst struct {
 // References:
 //    http://llvm.org/docs/LangRef.html#or-instruction
 type OrInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
 	// Operand type.
 	Type types.Type
 	// Operands.
 	Op1, Op2 values.Value
+	// Metadata attachments.
+	Metadata
+}
+
+// String returns a string representation of the or instruction, e.g.
+//
+//    %x = or i32 %a, %b
+func (inst *OrInst) String() string {
+	return inst.attach(inst.assign(binaryString("or", inst.Type, inst.Op1, inst.Op2)))
 }
 
 // The XorInst returns the bitwise logical exclusive or of its two operands,
@@ -355,10 +728,21 @@ type OrInst struct {
 // References:
 //    http://llvm.org/docs/LangRef.html#xor-instruction
 type XorInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
 	// Operand type.
 	Type types.Type
 	// Operands.
 	Op1, Op2 values.Value
+	// Metadata attachments.
+	Metadata
+}
+
+// String returns a string representation of the xor instruction, e.g.
+//
+//    %x = xor i32 %a, %b
+func (inst *XorInst) String() string {
+	return inst.attach(inst.assign(binaryString("xor", inst.Type, inst.Op1, inst.Op2)))
 }
 
 // =============================================================================
@@ -378,9 +762,135 @@ type XorInst struct {
 //    ref: http://llvm.org/docs/LangRef.html#aggregate-operations
 // =============================================================================
 
-// TODO: Add the following instructions:
-//    - extractvalue
-//    - insertvalue
+// The ExtractvalueInst extracts the value of a member field from an aggregate
+// value.
+//
+// Syntax:
+//    <Result> = extractvalue <AggregateType> <Aggregate>, <Idx>{, <Idx>}*
+//
+// Semantics:
+//    Result = Aggregate[Idx0][Idx1]...;
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#extractvalue-instruction
+type ExtractvalueInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
+	// Result type.
+	Type types.Type
+	// Aggregate value.
+	Aggregate values.Value
+	// Element indices.
+	Indices []int
+	// Metadata attachments.
+	Metadata
+}
+
+// NewExtractvalue returns a new extractvalue instruction which extracts the
+// element at the given index path of the aggregate value.
+func NewExtractvalue(aggregate values.Value, indices []int) (*ExtractvalueInst, error) {
+	typ, err := aggregateElem(aggregate.Type(), indices)
+	if err != nil {
+		return nil, fmt.Errorf("invalid extractvalue; %v", err)
+	}
+	return &ExtractvalueInst{Type: typ, Aggregate: aggregate, Indices: indices}, nil
+}
+
+// String returns a string representation of the extractvalue instruction, e.g.
+//
+//    %y = extractvalue {i32, float} %s, 0
+func (inst *ExtractvalueInst) String() string {
+	return inst.attach(inst.assign(fmt.Sprintf("extractvalue %v%s", inst.Aggregate, indicesString(inst.Indices))))
+}
+
+// The InsertvalueInst inserts a value into a member field of an aggregate
+// value.
+//
+// Syntax:
+//    <Result> = insertvalue <AggregateType> <Aggregate>, <Type> <Element>, <Idx>{, <Idx>}*
+//
+// Semantics:
+//    Result = Aggregate; Result[Idx0][Idx1]... = Element;
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#insertvalue-instruction
+type InsertvalueInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
+	// Aggregate value.
+	Aggregate values.Value
+	// Element to insert.
+	Element values.Value
+	// Element indices.
+	Indices []int
+	// Metadata attachments.
+	Metadata
+}
+
+// NewInsertvalue returns a new insertvalue instruction which inserts elem at the
+// given index path of the aggregate value.
+func NewInsertvalue(aggregate, elem values.Value, indices []int) (*InsertvalueInst, error) {
+	typ, err := aggregateElem(aggregate.Type(), indices)
+	if err != nil {
+		return nil, fmt.Errorf("invalid insertvalue; %v", err)
+	}
+	if !typ.Equal(elem.Type()) {
+		return nil, fmt.Errorf("invalid insertvalue; element type mismatch; expected %q, got %q", typ, elem.Type())
+	}
+	return &InsertvalueInst{Aggregate: aggregate, Element: elem, Indices: indices}, nil
+}
+
+// String returns a string representation of the insertvalue instruction, e.g.
+//
+//    %y = insertvalue {i32, float} %s, i32 42, 0
+func (inst *InsertvalueInst) String() string {
+	return inst.attach(inst.assign(fmt.Sprintf("insertvalue %v, %v%s", inst.Aggregate, inst.Element, indicesString(inst.Indices))))
+}
+
+// indicesString returns the string representation of the index path of an
+// extractvalue or insertvalue instruction, each index preceded by a comma, e.g.
+//
+//    , 0, 1
+func indicesString(indices []int) string {
+	buf := new(bytes.Buffer)
+	for _, index := range indices {
+		fmt.Fprintf(buf, ", %d", index)
+	}
+	return buf.String()
+}
+
+// aggregateElem returns the type of the element at the given index path of the
+// aggregate type t.
+func aggregateElem(t types.Type, indices []int) (types.Type, error) {
+	if len(indices) == 0 {
+		return nil, errors.New("expected at least one index")
+	}
+	for _, index := range indices {
+		switch typ := t.(type) {
+		case *types.Struct:
+			field, err := typ.FieldAt(index)
+			if err != nil {
+				return nil, err
+			}
+			t = field
+		case *types.IdentifiedStruct:
+			field, err := typ.FieldAt(index)
+			if err != nil {
+				return nil, err
+			}
+			t = field
+		case *types.Array:
+			elem, err := typ.ElemAt(index)
+			if err != nil {
+				return nil, err
+			}
+			t = elem
+		default:
+			return nil, fmt.Errorf("unable to index into non-aggregate type %q", t)
+		}
+	}
+	return t, nil
+}
 
 // =============================================================================
 // Memory Access and Addressing Operations
@@ -404,18 +914,63 @@ type XorInst struct {
 // References:
 //    http://llvm.org/docs/LangRef.html#alloca-instruction
 type AllocaInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
 	// Underlying type of the pointer.
 	Type types.Type
 	// Number of elements to allocate; defaults to 1.
 	NumElems int
 	// Memory alignment.
 	Align int
+	// Metadata attachments.
+	Metadata
+}
+
+// NewAlloca returns a new alloca instruction which allocates memory for
+// numElems elements of the given type on the stack, aligned to the natural
+// alignment of the type.
+func NewAlloca(typ types.Type, numElems int) (*AllocaInst, error) {
+	align, ok := types.NaturalAlign(typ)
+	if !ok {
+		return nil, fmt.Errorf("invalid alloca type %q; unable to allocate unsized type", typ)
+	}
+	if numElems < 1 {
+		return nil, fmt.Errorf("invalid alloca element count (%d)", numElems)
+	}
+	return &AllocaInst{Type: typ, NumElems: numElems, Align: align}, nil
+}
+
+// SetAlign sets the memory alignment of the alloca instruction, which must be a
+// power of two.
+func (inst *AllocaInst) SetAlign(align int) error {
+	if align <= 0 || align&(align-1) != 0 {
+		return fmt.Errorf("invalid alignment (%d); not a power of two", align)
+	}
+	inst.Align = align
+	return nil
+}
+
+// String returns a string representation of the alloca instruction, e.g.
+//
+//    %x = alloca i32, align 4
+//    %x = alloca i32, i32 4, align 8
+func (inst *AllocaInst) String() string {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "alloca %v", inst.Type)
+	if inst.NumElems > 1 {
+		fmt.Fprintf(buf, ", i32 %d", inst.NumElems)
+	}
+	if inst.Align != 0 {
+		fmt.Fprintf(buf, ", align %d", inst.Align)
+	}
+	return inst.attach(inst.assign(buf.String()))
 }
 
 // The LoadInst reads from memory.
 //
 // Syntax:
-//    <Result> = load <Type>* <Addr> [, align <Align> ]
+//    <Result> = load <Type>, <Type>* <Addr> [, align <Align> ]
+//    <Result> = load atomic <Type>, <Type>* <Addr> [syncscope("<SyncScope>")] <Ordering>, align <Align>
 //
 // Semantics:
 //    Result = *(Type *)Addr;
@@ -423,18 +978,85 @@ type AllocaInst struct {
 // References:
 //    http://llvm.org/docs/LangRef.html#load-instruction
 type LoadInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
 	// Underlying type of the pointer.
 	Type types.Type
 	// Memory address to load.
 	Addr values.Value
 	// Memory alignment.
 	Align int
+	// Specifies if the load is atomic.
+	Atomic bool
+	// Memory ordering constraint of atomic loads; one of unordered, monotonic,
+	// acquire or seq_cst.
+	Ordering AtomicOrdering
+	// Synchronization scope of atomic loads (e.g. "singlethread"), or the empty
+	// string for the default system scope.
+	SyncScope string
+	// Metadata attachments.
+	Metadata
+}
+
+// NewLoad returns a new load instruction which reads from the memory address
+// addr.
+func NewLoad(addr values.Value) (*LoadInst, error) {
+	typ, ok := addr.Type().(*types.Pointer)
+	if !ok {
+		return nil, fmt.Errorf("invalid load address type; expected pointer, got %q", addr.Type())
+	}
+	return &LoadInst{Type: typ.Elem(), Addr: addr}, nil
+}
+
+// NewAtomicLoad returns a new atomic load instruction which reads from the
+// memory address addr, with the given memory ordering constraint,
+// synchronization scope and alignment.
+func NewAtomicLoad(addr values.Value, ordering AtomicOrdering, syncScope string, align int) (*LoadInst, error) {
+	inst, err := NewLoad(addr)
+	if err != nil {
+		return nil, err
+	}
+	switch ordering {
+	case AtomicUnordered, AtomicMonotonic, AtomicAcquire, AtomicSeqCst:
+		// valid ordering
+	default:
+		return nil, fmt.Errorf("invalid atomic load ordering (%v); expected unordered, monotonic, acquire or seq_cst", ordering)
+	}
+	if align <= 0 {
+		return nil, fmt.Errorf("invalid atomic load alignment (%d); atomic loads require an explicit alignment", align)
+	}
+	inst.Align = align
+	inst.Atomic = true
+	inst.Ordering = ordering
+	inst.SyncScope = syncScope
+	return inst, nil
+}
+
+// String returns a string representation of the load instruction, e.g.
+//
+//    %x = load i32, i32* %p, align 4
+//    %x = load atomic i32, i32* %p acquire, align 4
+func (inst *LoadInst) String() string {
+	buf := new(bytes.Buffer)
+	buf.WriteString("load ")
+	if inst.Atomic {
+		buf.WriteString("atomic ")
+	}
+	fmt.Fprintf(buf, "%v, %v %s", inst.Type, inst.Addr.Type(), inst.Addr.Ident())
+	if inst.Atomic {
+		buf.WriteString(atomicString(inst.Ordering, inst.SyncScope))
+	}
+	if inst.Align != 0 {
+		fmt.Fprintf(buf, ", align %d", inst.Align)
+	}
+	return inst.attach(inst.assign(buf.String()))
 }
 
 // The StoreInst writes to memory.
 //
 // Syntax:
 //    store <Type> <Val>, <Type>* <Addr> [, align <Align> ]
+//    store atomic <Type> <Val>, <Type>* <Addr> [syncscope("<SyncScope>")] <Ordering>, align <Align>
 //
 // Semantics:
 //    *(Type *)Addr = Val;
@@ -450,10 +1072,178 @@ type StoreInst struct {
 	Addr values.Value
 	// Memory alignment.
 	Align int
+	// Specifies if the store is atomic.
+	Atomic bool
+	// Memory ordering constraint of atomic stores; one of unordered, monotonic,
+	// release or seq_cst.
+	Ordering AtomicOrdering
+	// Synchronization scope of atomic stores (e.g. "singlethread"), or the
+	// empty string for the default system scope.
+	SyncScope string
+	// Metadata attachments.
+	Metadata
+}
+
+// NewStore returns a new store instruction which writes val to the memory
+// address addr.
+func NewStore(val, addr values.Value) (*StoreInst, error) {
+	typ, ok := addr.Type().(*types.Pointer)
+	if !ok {
+		return nil, fmt.Errorf("invalid store address type; expected pointer, got %q", addr.Type())
+	}
+	if !val.Type().Equal(typ.Elem()) {
+		return nil, fmt.Errorf("invalid store value type; expected %q, got %q", typ.Elem(), val.Type())
+	}
+	return &StoreInst{Type: val.Type(), Val: val, Addr: addr}, nil
+}
+
+// NewAtomicStore returns a new atomic store instruction which writes val to the
+// memory address addr, with the given memory ordering constraint,
+// synchronization scope and alignment.
+func NewAtomicStore(val, addr values.Value, ordering AtomicOrdering, syncScope string, align int) (*StoreInst, error) {
+	inst, err := NewStore(val, addr)
+	if err != nil {
+		return nil, err
+	}
+	switch ordering {
+	case AtomicUnordered, AtomicMonotonic, AtomicRelease, AtomicSeqCst:
+		// valid ordering
+	default:
+		return nil, fmt.Errorf("invalid atomic store ordering (%v); expected unordered, monotonic, release or seq_cst", ordering)
+	}
+	if align <= 0 {
+		return nil, fmt.Errorf("invalid atomic store alignment (%d); atomic stores require an explicit alignment", align)
+	}
+	inst.Align = align
+	inst.Atomic = true
+	inst.Ordering = ordering
+	inst.SyncScope = syncScope
+	return inst, nil
+}
+
+// String returns a string representation of the store instruction, e.g.
+//
+//    store i32 42, i32* %p, align 4
+//    store atomic i32 42, i32* %p release, align 4
+func (inst *StoreInst) String() string {
+	buf := new(bytes.Buffer)
+	buf.WriteString("store ")
+	if inst.Atomic {
+		buf.WriteString("atomic ")
+	}
+	fmt.Fprintf(buf, "%v %s, %v %s", inst.Type, inst.Val.Ident(), inst.Addr.Type(), inst.Addr.Ident())
+	if inst.Atomic {
+		buf.WriteString(atomicString(inst.Ordering, inst.SyncScope))
+	}
+	if inst.Align != 0 {
+		fmt.Fprintf(buf, ", align %d", inst.Align)
+	}
+	return inst.attach(buf.String())
+}
+
+// The FenceInst introduces happens-before edges between operations.
+//
+// Syntax:
+//    fence [syncscope("<SyncScope>")] <Ordering>
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#fence-instruction
+type FenceInst struct {
+	// Memory ordering constraint; one of acquire, release, acq_rel or seq_cst.
+	Ordering AtomicOrdering
+	// Synchronization scope (e.g. "singlethread"), or the empty string for the
+	// default system scope.
+	SyncScope string
+	// Metadata attachments.
+	Metadata
+}
+
+// NewFence returns a new fence instruction with the given memory ordering
+// constraint and synchronization scope.
+func NewFence(ordering AtomicOrdering, syncScope string) (*FenceInst, error) {
+	switch ordering {
+	case AtomicAcquire, AtomicRelease, AtomicAcqRel, AtomicSeqCst:
+		// valid ordering
+	default:
+		return nil, fmt.Errorf("invalid fence ordering (%v); expected acquire, release, acq_rel or seq_cst", ordering)
+	}
+	return &FenceInst{Ordering: ordering, SyncScope: syncScope}, nil
+}
+
+// String returns a string representation of the fence instruction, e.g.
+//
+//    fence acquire
+//    fence syncscope("singlethread") seq_cst
+func (inst *FenceInst) String() string {
+	return inst.attach("fence" + atomicString(inst.Ordering, inst.SyncScope))
+}
+
+// AtomicOrdering specifies the memory ordering constraint of an atomic
+// instruction.
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#ordering
+type AtomicOrdering int
+
+// Atomic memory ordering constraints.
+const (
+	AtomicNone      AtomicOrdering = iota // not atomic
+	AtomicUnordered                       // unordered
+	AtomicMonotonic                       // monotonic
+	AtomicAcquire                         // acquire
+	AtomicRelease                         // release
+	AtomicAcqRel                          // acq_rel
+	AtomicSeqCst                          // seq_cst
+)
+
+// String returns the keyword of the memory ordering constraint, e.g.
+//
+//    acquire
+func (ordering AtomicOrdering) String() string {
+	switch ordering {
+	case AtomicNone:
+		return "none"
+	case AtomicUnordered:
+		return "unordered"
+	case AtomicMonotonic:
+		return "monotonic"
+	case AtomicAcquire:
+		return "acquire"
+	case AtomicRelease:
+		return "release"
+	case AtomicAcqRel:
+		return "acq_rel"
+	case AtomicSeqCst:
+		return "seq_cst"
+	}
+	return fmt.Sprintf("AtomicOrdering(%d)", int(ordering))
+}
+
+// ParseAtomicOrdering returns the memory ordering constraint of the given
+// keyword, e.g. AtomicAcqRel for "acq_rel".
+func ParseAtomicOrdering(s string) (AtomicOrdering, error) {
+	for ordering := AtomicUnordered; ordering <= AtomicSeqCst; ordering++ {
+		if ordering.String() == s {
+			return ordering, nil
+		}
+	}
+	return AtomicNone, fmt.Errorf("invalid atomic ordering %q", s)
+}
+
+// atomicString returns the string representation of the synchronization scope
+// (if any) and the memory ordering constraint of an atomic instruction, with a
+// leading space, e.g.
+//
+//     acquire
+//     syncscope("singlethread") seq_cst
+func atomicString(ordering AtomicOrdering, syncScope string) string {
+	if len(syncScope) > 0 {
+		return fmt.Sprintf(" syncscope(%q) %v", syncScope, ordering)
+	}
+	return " " + ordering.String()
 }
 
 // TODO(u): Add the following memory access and addressing operations:
-//    - fence
 //    - cmpxchg
 //    - atomicrmw
 
@@ -464,7 +1254,7 @@ type StoreInst struct {
 // structure. It performs address calculation only and does not access memory.
 //
 // Syntax:
-//    <Result> = getelementptr <Type>* <Ptr> {, <Type> <Idx>}*
+//    <Result> = getelementptr [inbounds] <Type>, <Type>* <Ptr> {, <Type> <Idx>}*
 //
 // Semantics:
 //    Result = &Ptr[Idx1];
@@ -474,12 +1264,76 @@ type StoreInst struct {
 // References:
 //    http://llvm.org/docs/LangRef.html#getelementptr-instruction
 type GetelementptrInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
 	// Underlying type of the pointer.
 	Type types.Type
 	// Pointer to the aggregate data structure.
 	Ptr values.Value
 	// Element indicies.
 	Indicies []int
+	// Specifies if the addressed element must be within the bounds of the
+	// allocated object.
+	InBounds bool
+	// Metadata attachments.
+	Metadata
+}
+
+// ResultType returns the type of the result of the getelementptr instruction,
+// which is a pointer to the element addressed by the indices.
+func (inst *GetelementptrInst) ResultType() (types.Type, error) {
+	// The first index steps through the pointer and does not change the type of
+	// the addressed element.
+	t := inst.Type
+	for i := 1; i < len(inst.Indicies); i++ {
+		switch typ := t.(type) {
+		case *types.Struct:
+			field, err := typ.FieldAt(inst.Indicies[i])
+			if err != nil {
+				return nil, fmt.Errorf("invalid getelementptr; %v", err)
+			}
+			t = field
+		case *types.IdentifiedStruct:
+			field, err := typ.FieldAt(inst.Indicies[i])
+			if err != nil {
+				return nil, fmt.Errorf("invalid getelementptr; %v", err)
+			}
+			t = field
+		case *types.Array:
+			t = typ.Elem()
+		case *types.Vector:
+			t = typ.Elem()
+		default:
+			return nil, fmt.Errorf("invalid getelementptr; unable to index into non-aggregate type %q", t)
+		}
+	}
+
+	// The result belongs to the same address space as the pointer.
+	space := 0
+	if inst.Ptr != nil {
+		if ptr, ok := inst.Ptr.Type().(*types.Pointer); ok {
+			space = ptr.AddrSpace()
+		}
+	}
+	return types.NewPointerInAddrSpace(t, space)
+}
+
+// String returns a string representation of the getelementptr instruction,
+// e.g.
+//
+//    %y = getelementptr {i32, float}, {i32, float}* %p, i32 0, i32 1
+//    %z = getelementptr inbounds [4 x i32], [4 x i32]* %a, i32 0, i32 3
+func (inst *GetelementptrInst) String() string {
+	buf := new(bytes.Buffer)
+	buf.WriteString("getelementptr ")
+	if inst.InBounds {
+		buf.WriteString("inbounds ")
+	}
+	fmt.Fprintf(buf, "%v, %v", inst.Type, inst.Ptr)
+	for _, index := range inst.Indicies {
+		fmt.Fprintf(buf, ", i32 %d", index)
+	}
+	return inst.attach(inst.assign(buf.String()))
 }
 
 // =============================================================================
@@ -488,20 +1342,684 @@ type GetelementptrInst struct {
 //    ref: http://llvm.org/docs/LangRef.html#conversion-operations
 // =============================================================================
 
-// TODO: Add the following instructions:
-//    - trunc
-//    - zext
-//    - sext
-//    - fptrunc
-//    - fpext
-//    - fptoui
-//    - fptosi
-//    - uitofp
-//    - sitofp
-//    - ptrtoint
-//    - inttoptr
-//    - bitcast
-//    - addrspacecast
+// The TruncInst truncates an integer value (or vector of integers) to a smaller
+// integer type (or vector of integers).
+//
+// Syntax:
+//    <Result> = trunc <Type> <From> to <To>
+//
+// Semantics:
+//    Result = (To)From; // high order bits of From are discarded.
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#trunc-to-instruction
+type TruncInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
+	// Original value.
+	From values.Value
+	// New type.
+	To types.Type
+	// Metadata attachments.
+	Metadata
+}
+
+// NewTrunc returns a new trunc instruction which truncates the integer value
+// (or vector of integers) from to the smaller integer type (or vector of
+// integers) to.
+func NewTrunc(from values.Value, to types.Type) (*TruncInst, error) {
+	// Verify type of original value.
+	if !types.IsInts(from.Type()) {
+		return nil, fmt.Errorf("invalid integer truncation; expected integer (or integer vector) for from, got %q", from.Type())
+	}
+
+	// Verify target type.
+	if !types.IsInts(to) {
+		return nil, fmt.Errorf("invalid integer truncation; expected integer (or integer vector) target type, got %q", to)
+	}
+	if !types.SameLength(from.Type(), to) {
+		return nil, fmt.Errorf("invalid integer truncation; cannot convert from %q to %q", from.Type(), to)
+	}
+	newSize, origSize := elem(to).(*types.Int).Size(), elem(from.Type()).(*types.Int).Size()
+	if newSize >= origSize {
+		return nil, fmt.Errorf("invalid integer truncation; target size (%d) not smaller than original size (%d)", newSize, origSize)
+	}
+
+	return &TruncInst{From: from, To: to}, nil
+}
+
+// String returns a string representation of the trunc instruction, e.g.
+//
+//    %y = trunc i32 %x to i8
+func (inst *TruncInst) String() string {
+	return inst.attach(inst.assign(convString("trunc", inst.From, inst.To)))
+}
+
+// The ZextInst zero extends an integer value (or vector of integers) to a larger
+// integer type (or vector of integers).
+//
+// Syntax:
+//    <Result> = zext <Type> <From> to <To>
+//
+// Semantics:
+//    Result = (To)From; // high order bits of Result are zero.
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#zext-to-instruction
+type ZextInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
+	// Original value.
+	From values.Value
+	// New type.
+	To types.Type
+	// Metadata attachments.
+	Metadata
+}
+
+// NewZext returns a new zext instruction which zero extends the integer value
+// (or vector of integers) from to the larger integer type (or vector of
+// integers) to.
+func NewZext(from values.Value, to types.Type) (*ZextInst, error) {
+	if err := checkIntExt("zero extension", from, to); err != nil {
+		return nil, err
+	}
+	return &ZextInst{From: from, To: to}, nil
+}
+
+// String returns a string representation of the zext instruction, e.g.
+//
+//    %y = zext i8 %x to i32
+func (inst *ZextInst) String() string {
+	return inst.attach(inst.assign(convString("zext", inst.From, inst.To)))
+}
+
+// The SextInst sign extends an integer value (or vector of integers) to a larger
+// integer type (or vector of integers).
+//
+// Syntax:
+//    <Result> = sext <Type> <From> to <To>
+//
+// Semantics:
+//    Result = (To)From; // high order bits of Result are copies of the sign bit.
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#sext-to-instruction
+type SextInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
+	// Original value.
+	From values.Value
+	// New type.
+	To types.Type
+	// Metadata attachments.
+	Metadata
+}
+
+// NewSext returns a new sext instruction which sign extends the integer value
+// (or vector of integers) from to the larger integer type (or vector of
+// integers) to.
+func NewSext(from values.Value, to types.Type) (*SextInst, error) {
+	if err := checkIntExt("sign extension", from, to); err != nil {
+		return nil, err
+	}
+	return &SextInst{From: from, To: to}, nil
+}
+
+// String returns a string representation of the sext instruction, e.g.
+//
+//    %y = sext i8 %x to i32
+func (inst *SextInst) String() string {
+	return inst.attach(inst.assign(convString("sext", inst.From, inst.To)))
+}
+
+// checkIntExt verifies that the integer value (or vector of integers) from may
+// be extended to the larger integer type (or vector of integers) to. The
+// operation name op is used in error messages.
+func checkIntExt(op string, from values.Value, to types.Type) error {
+	// Verify type of original value.
+	if !types.IsInts(from.Type()) {
+		return fmt.Errorf("invalid integer %s; expected integer (or integer vector) for from, got %q", op, from.Type())
+	}
+
+	// Verify target type.
+	if !types.IsInts(to) {
+		return fmt.Errorf("invalid integer %s; expected integer (or integer vector) target type, got %q", op, to)
+	}
+	if !types.SameLength(from.Type(), to) {
+		return fmt.Errorf("invalid integer %s; cannot convert from %q to %q", op, from.Type(), to)
+	}
+	newSize, origSize := elem(to).(*types.Int).Size(), elem(from.Type()).(*types.Int).Size()
+	if newSize <= origSize {
+		return fmt.Errorf("invalid integer %s; target size (%d) not larger than original size (%d)", op, newSize, origSize)
+	}
+	return nil
+}
+
+// The FptruncInst truncates a floating point value (or vector of floating point
+// values) to a smaller floating point type (or vector of floating point
+// values).
+//
+// Syntax:
+//    <Result> = fptrunc <Type> <From> to <To>
+//
+// Semantics:
+//    Result = (To)From;
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#fptrunc-to-instruction
+type FptruncInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
+	// Original value.
+	From values.Value
+	// New type.
+	To types.Type
+	// Metadata attachments.
+	Metadata
+}
+
+// NewFptrunc returns a new fptrunc instruction which truncates the floating
+// point value (or vector of floating point values) from to the smaller floating
+// point type (or vector of floating point values) to.
+func NewFptrunc(from values.Value, to types.Type) (*FptruncInst, error) {
+	// Verify type of original value.
+	if !types.IsFloats(from.Type()) {
+		return nil, fmt.Errorf("invalid floating point truncation; expected floating point (or floating point vector) for from, got %q", from.Type())
+	}
+
+	// Verify target type.
+	if !types.IsFloats(to) {
+		return nil, fmt.Errorf("invalid floating point truncation; expected floating point (or floating point vector) target type, got %q", to)
+	}
+	if !types.SameLength(from.Type(), to) {
+		return nil, fmt.Errorf("invalid floating point truncation; cannot convert from %q to %q", from.Type(), to)
+	}
+	newSize, origSize := elem(to).(*types.Float).Size(), elem(from.Type()).(*types.Float).Size()
+	if newSize >= origSize {
+		return nil, fmt.Errorf("invalid floating point truncation; target size (%d) not smaller than original size (%d)", newSize, origSize)
+	}
+
+	return &FptruncInst{From: from, To: to}, nil
+}
+
+// String returns a string representation of the fptrunc instruction, e.g.
+//
+//    %y = fptrunc double %x to float
+func (inst *FptruncInst) String() string {
+	return inst.attach(inst.assign(convString("fptrunc", inst.From, inst.To)))
+}
+
+// The FpextInst extends a floating point value (or vector of floating point
+// values) to a larger floating point type (or vector of floating point values).
+//
+// Syntax:
+//    <Result> = fpext <Type> <From> to <To>
+//
+// Semantics:
+//    Result = (To)From;
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#fpext-to-instruction
+type FpextInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
+	// Original value.
+	From values.Value
+	// New type.
+	To types.Type
+	// Metadata attachments.
+	Metadata
+}
+
+// NewFpext returns a new fpext instruction which extends the floating point
+// value (or vector of floating point values) from to the larger floating point
+// type (or vector of floating point values) to.
+func NewFpext(from values.Value, to types.Type) (*FpextInst, error) {
+	// Verify type of original value.
+	if !types.IsFloats(from.Type()) {
+		return nil, fmt.Errorf("invalid floating point extension; expected floating point (or floating point vector) for from, got %q", from.Type())
+	}
+
+	// Verify target type.
+	if !types.IsFloats(to) {
+		return nil, fmt.Errorf("invalid floating point extension; expected floating point (or floating point vector) target type, got %q", to)
+	}
+	if !types.SameLength(from.Type(), to) {
+		return nil, fmt.Errorf("invalid floating point extension; cannot convert from %q to %q", from.Type(), to)
+	}
+	newSize, origSize := elem(to).(*types.Float).Size(), elem(from.Type()).(*types.Float).Size()
+	if newSize <= origSize {
+		return nil, fmt.Errorf("invalid floating point extension; target size (%d) not larger than original size (%d)", newSize, origSize)
+	}
+
+	return &FpextInst{From: from, To: to}, nil
+}
+
+// String returns a string representation of the fpext instruction, e.g.
+//
+//    %y = fpext float %x to double
+func (inst *FpextInst) String() string {
+	return inst.attach(inst.assign(convString("fpext", inst.From, inst.To)))
+}
+
+// The FptouiInst converts a floating point value (or vector of floating point
+// values) to an unsigned integer type (or vector of integers).
+//
+// Syntax:
+//    <Result> = fptoui <Type> <From> to <To>
+//
+// Semantics:
+//    Result = (To)From; // From is interpreted as an unsigned integer.
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#fptoui-to-instruction
+type FptouiInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
+	// Original value.
+	From values.Value
+	// New type.
+	To types.Type
+	// Metadata attachments.
+	Metadata
+}
+
+// NewFptoui returns a new fptoui instruction which converts the floating point
+// value (or vector of floating point values) from to the unsigned integer type
+// (or vector of integers) to.
+func NewFptoui(from values.Value, to types.Type) (*FptouiInst, error) {
+	if err := checkFloatToInt("floating point to unsigned integer conversion", from, to); err != nil {
+		return nil, err
+	}
+	return &FptouiInst{From: from, To: to}, nil
+}
+
+// String returns a string representation of the fptoui instruction, e.g.
+//
+//    %y = fptoui double %x to i32
+func (inst *FptouiInst) String() string {
+	return inst.attach(inst.assign(convString("fptoui", inst.From, inst.To)))
+}
+
+// The FptosiInst converts a floating point value (or vector of floating point
+// values) to a signed integer type (or vector of integers).
+//
+// Syntax:
+//    <Result> = fptosi <Type> <From> to <To>
+//
+// Semantics:
+//    Result = (To)From; // Result is interpreted as a signed integer.
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#fptosi-to-instruction
+type FptosiInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
+	// Original value.
+	From values.Value
+	// New type.
+	To types.Type
+	// Metadata attachments.
+	Metadata
+}
+
+// NewFptosi returns a new fptosi instruction which converts the floating point
+// value (or vector of floating point values) from to the signed integer type
+// (or vector of integers) to.
+func NewFptosi(from values.Value, to types.Type) (*FptosiInst, error) {
+	if err := checkFloatToInt("floating point to signed integer conversion", from, to); err != nil {
+		return nil, err
+	}
+	return &FptosiInst{From: from, To: to}, nil
+}
+
+// String returns a string representation of the fptosi instruction, e.g.
+//
+//    %y = fptosi double %x to i32
+func (inst *FptosiInst) String() string {
+	return inst.attach(inst.assign(convString("fptosi", inst.From, inst.To)))
+}
+
+// The UitofpInst converts an unsigned integer value (or vector of integers) to
+// a floating point type (or vector of floating point values).
+//
+// Syntax:
+//    <Result> = uitofp <Type> <From> to <To>
+//
+// Semantics:
+//    Result = (To)From; // From is interpreted as an unsigned integer.
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#uitofp-to-instruction
+type UitofpInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
+	// Original value.
+	From values.Value
+	// New type.
+	To types.Type
+	// Metadata attachments.
+	Metadata
+}
+
+// NewUitofp returns a new uitofp instruction which converts the unsigned integer
+// value (or vector of integers) from to the floating point type (or vector of
+// floating point values) to.
+func NewUitofp(from values.Value, to types.Type) (*UitofpInst, error) {
+	if err := checkIntToFloat("unsigned integer to floating point conversion", from, to); err != nil {
+		return nil, err
+	}
+	return &UitofpInst{From: from, To: to}, nil
+}
+
+// String returns a string representation of the uitofp instruction, e.g.
+//
+//    %y = uitofp i32 %x to double
+func (inst *UitofpInst) String() string {
+	return inst.attach(inst.assign(convString("uitofp", inst.From, inst.To)))
+}
+
+// The SitofpInst converts a signed integer value (or vector of integers) to a
+// floating point type (or vector of floating point values).
+//
+// Syntax:
+//    <Result> = sitofp <Type> <From> to <To>
+//
+// Semantics:
+//    Result = (To)From; // From is interpreted as a signed integer.
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#sitofp-to-instruction
+type SitofpInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
+	// Original value.
+	From values.Value
+	// New type.
+	To types.Type
+	// Metadata attachments.
+	Metadata
+}
+
+// NewSitofp returns a new sitofp instruction which converts the signed integer
+// value (or vector of integers) from to the floating point type (or vector of
+// floating point values) to.
+func NewSitofp(from values.Value, to types.Type) (*SitofpInst, error) {
+	if err := checkIntToFloat("signed integer to floating point conversion", from, to); err != nil {
+		return nil, err
+	}
+	return &SitofpInst{From: from, To: to}, nil
+}
+
+// String returns a string representation of the sitofp instruction, e.g.
+//
+//    %y = sitofp i32 %x to double
+func (inst *SitofpInst) String() string {
+	return inst.attach(inst.assign(convString("sitofp", inst.From, inst.To)))
+}
+
+// checkFloatToInt verifies that the floating point value (or vector of floating
+// point values) from may be converted to the integer type (or vector of
+// integers) to. The operation name op is used in error messages.
+func checkFloatToInt(op string, from values.Value, to types.Type) error {
+	// Verify type of original value.
+	if !types.IsFloats(from.Type()) {
+		return fmt.Errorf("invalid %s; expected floating point (or floating point vector) for from, got %q", op, from.Type())
+	}
+
+	// Verify target type.
+	if !types.IsInts(to) {
+		return fmt.Errorf("invalid %s; expected integer (or integer vector) target type, got %q", op, to)
+	}
+	if !types.SameLength(from.Type(), to) {
+		return fmt.Errorf("invalid %s; cannot convert from %q to %q", op, from.Type(), to)
+	}
+	return nil
+}
+
+// checkIntToFloat verifies that the integer value (or vector of integers) from
+// may be converted to the floating point type (or vector of floating point
+// values) to. The operation name op is used in error messages.
+func checkIntToFloat(op string, from values.Value, to types.Type) error {
+	// Verify type of original value.
+	if !types.IsInts(from.Type()) {
+		return fmt.Errorf("invalid %s; expected integer (or integer vector) for from, got %q", op, from.Type())
+	}
+
+	// Verify target type.
+	if !types.IsFloats(to) {
+		return fmt.Errorf("invalid %s; expected floating point (or floating point vector) target type, got %q", op, to)
+	}
+	if !types.SameLength(from.Type(), to) {
+		return fmt.Errorf("invalid %s; cannot convert from %q to %q", op, from.Type(), to)
+	}
+	return nil
+}
+
+// The PtrtointInst converts a pointer (or vector of pointers) to an integer type
+// (or vector of integers).
+//
+// Syntax:
+//    <Result> = ptrtoint <Type> <From> to <To>
+//
+// Semantics:
+//    Result = (To)From; // From is truncated or zero extended to the size of To.
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#ptrtoint-to-instruction
+type PtrtointInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
+	// Original value.
+	From values.Value
+	// New type.
+	To types.Type
+	// Metadata attachments.
+	Metadata
+}
+
+// NewPtrtoint returns a new ptrtoint instruction which converts the pointer (or
+// vector of pointers) from to the integer type (or vector of integers) to.
+func NewPtrtoint(from values.Value, to types.Type) (*PtrtointInst, error) {
+	// Verify type of original value.
+	if !types.IsPointers(from.Type()) {
+		return nil, fmt.Errorf("invalid pointer to integer conversion; expected pointer (or pointer vector) for from, got %q", from.Type())
+	}
+
+	// Verify target type.
+	if !types.IsInts(to) {
+		return nil, fmt.Errorf("invalid pointer to integer conversion; expected integer (or integer vector) target type, got %q", to)
+	}
+	if !types.SameLength(from.Type(), to) {
+		return nil, fmt.Errorf("invalid pointer to integer conversion; cannot convert from %q to %q", from.Type(), to)
+	}
+
+	return &PtrtointInst{From: from, To: to}, nil
+}
+
+// String returns a string representation of the ptrtoint instruction, e.g.
+//
+//    %y = ptrtoint i8* %p to i64
+func (inst *PtrtointInst) String() string {
+	return inst.attach(inst.assign(convString("ptrtoint", inst.From, inst.To)))
+}
+
+// The InttoptrInst converts an integer value (or vector of integers) to a
+// pointer type (or vector of pointers).
+//
+// Syntax:
+//    <Result> = inttoptr <Type> <From> to <To>
+//
+// Semantics:
+//    Result = (To)From; // From is truncated or zero extended to the size of a pointer.
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#inttoptr-to-instruction
+type InttoptrInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
+	// Original value.
+	From values.Value
+	// New type.
+	To types.Type
+	// Metadata attachments.
+	Metadata
+}
+
+// NewInttoptr returns a new inttoptr instruction which converts the integer
+// value (or vector of integers) from to the pointer type (or vector of
+// pointers) to.
+func NewInttoptr(from values.Value, to types.Type) (*InttoptrInst, error) {
+	// Verify type of original value.
+	if !types.IsInts(from.Type()) {
+		return nil, fmt.Errorf("invalid integer to pointer conversion; expected integer (or integer vector) for from, got %q", from.Type())
+	}
+
+	// Verify target type.
+	if !types.IsPointers(to) {
+		return nil, fmt.Errorf("invalid integer to pointer conversion; expected pointer (or pointer vector) target type, got %q", to)
+	}
+	if !types.SameLength(from.Type(), to) {
+		return nil, fmt.Errorf("invalid integer to pointer conversion; cannot convert from %q to %q", from.Type(), to)
+	}
+
+	return &InttoptrInst{From: from, To: to}, nil
+}
+
+// String returns a string representation of the inttoptr instruction, e.g.
+//
+//    %p = inttoptr i64 %x to i8*
+func (inst *InttoptrInst) String() string {
+	return inst.attach(inst.assign(convString("inttoptr", inst.From, inst.To)))
+}
+
+// The BitcastInst converts a value to a non-aggregate type of the same size
+// without changing any bits.
+//
+// Syntax:
+//    <Result> = bitcast <Type> <From> to <To>
+//
+// Semantics:
+//    Result = *(To*)&From;
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#bitcast-to-instruction
+type BitcastInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
+	// Original value.
+	From values.Value
+	// New type.
+	To types.Type
+	// Metadata attachments.
+	Metadata
+}
+
+// NewBitcast returns a new bitcast instruction which converts the value from to
+// the non-aggregate type to of the same size.
+func NewBitcast(from values.Value, to types.Type) (*BitcastInst, error) {
+	fromType := from.Type()
+	switch {
+	case isAggregate(fromType):
+		return nil, fmt.Errorf("invalid bitcast; expected non-aggregate value for from, got %q", fromType)
+	case isAggregate(to):
+		return nil, fmt.Errorf("invalid bitcast; expected non-aggregate target type, got %q", to)
+	}
+
+	// Pointers may only be converted to pointers.
+	if types.IsPointers(fromType) || types.IsPointers(to) {
+		if !types.IsPointers(fromType) || !types.IsPointers(to) || !types.SameLength(fromType, to) {
+			return nil, fmt.Errorf("invalid bitcast; cannot convert from %q to %q", fromType, to)
+		}
+		if !sameAddrSpace(fromType, to) {
+			return nil, fmt.Errorf("invalid bitcast; cannot convert between address spaces of %q and %q; use addrspacecast instead", fromType, to)
+		}
+		return &BitcastInst{From: from, To: to}, nil
+	}
+
+	// Verify that the original value and the target type are of the same size.
+	origSize, ok := bitSize(fromType)
+	if !ok {
+		return nil, fmt.Errorf("invalid bitcast; unable to convert from %q", fromType)
+	}
+	newSize, ok := bitSize(to)
+	if !ok {
+		return nil, fmt.Errorf("invalid bitcast; unable to convert to %q", to)
+	}
+	if newSize != origSize {
+		return nil, fmt.Errorf("invalid bitcast; target size (%d) not equal to original size (%d)", newSize, origSize)
+	}
+
+	return &BitcastInst{From: from, To: to}, nil
+}
+
+// String returns a string representation of the bitcast instruction, e.g.
+//
+//    %q = bitcast i8* %p to i32*
+func (inst *BitcastInst) String() string {
+	return inst.attach(inst.assign(convString("bitcast", inst.From, inst.To)))
+}
+
+// The AddrspacecastInst converts a pointer (or vector of pointers) to a pointer
+// type (or vector of pointers) in a different address space.
+//
+// Syntax:
+//    <Result> = addrspacecast <Type> <From> to <To>
+//
+// Semantics:
+//    Result = (To)From;
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#addrspacecast-to-instruction
+type AddrspacecastInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
+	// Original value.
+	From values.Value
+	// New type.
+	To types.Type
+	// Metadata attachments.
+	Metadata
+}
+
+// NewAddrspacecast returns a new addrspacecast instruction which converts the
+// pointer (or vector of pointers) from to the pointer type (or vector of
+// pointers) to in a different address space.
+func NewAddrspacecast(from values.Value, to types.Type) (*AddrspacecastInst, error) {
+	// Verify type of original value.
+	if !types.IsPointers(from.Type()) {
+		return nil, fmt.Errorf("invalid address space conversion; expected pointer (or pointer vector) for from, got %q", from.Type())
+	}
+
+	// Verify target type.
+	if !types.IsPointers(to) {
+		return nil, fmt.Errorf("invalid address space conversion; expected pointer (or pointer vector) target type, got %q", to)
+	}
+	if !types.SameLength(from.Type(), to) {
+		return nil, fmt.Errorf("invalid address space conversion; cannot convert from %q to %q", from.Type(), to)
+	}
+	if sameAddrSpace(from.Type(), to) {
+		return nil, fmt.Errorf("invalid address space conversion; %q and %q share address space; use bitcast instead", from.Type(), to)
+	}
+
+	return &AddrspacecastInst{From: from, To: to}, nil
+}
+
+// String returns a string representation of the addrspacecast instruction, e.g.
+//
+//    %q = addrspacecast i8* %p to i8 addrspace(1)*
+func (inst *AddrspacecastInst) String() string {
+	return inst.attach(inst.assign(convString("addrspacecast", inst.From, inst.To)))
+}
+
+// convString returns the string representation of a conversion instruction
+// with the given mnemonic, e.g.
+//
+//    trunc i32 %x to i8
+func convString(op string, from values.Value, to types.Type) string {
+	return fmt.Sprintf("%s %v to %v", op, from, to)
+}
 
 // =============================================================================
 // Other Operations
@@ -520,6 +2038,8 @@ type GetelementptrInst struct {
 // References:
 //    http://llvm.org/docs/LangRef.html#icmp-instruction
 type IcmpInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
 	// Comparison operation.
 	Pred IntPredicate
 	// TODO: Restrict to IntsType and IntsValue?
@@ -528,6 +2048,8 @@ type IcmpInst struct {
 	Type types.Type
 	// Operands.
 	Op1, Op2 values.Value
+	// Metadata attachments.
+	Metadata
 }
 
 // IntPredicate specifies a comparison operation to perform between two integer
@@ -548,10 +2070,53 @@ const (
 	IntSle                     // signed less or equal
 )
 
+// intPredNames maps from integer predicate to keyword.
+var intPredNames = map[IntPredicate]string{
+	IntEq:  "eq",
+	IntNe:  "ne",
+	IntUgt: "ugt",
+	IntUge: "uge",
+	IntUlt: "ult",
+	IntUle: "ule",
+	IntSgt: "sgt",
+	IntSge: "sge",
+	IntSlt: "slt",
+	IntSle: "sle",
+}
+
+// String returns the keyword of the integer predicate, e.g.
+//
+//    slt
+func (pred IntPredicate) String() string {
+	if s, ok := intPredNames[pred]; ok {
+		return s
+	}
+	return fmt.Sprintf("IntPredicate(%d)", int(pred))
+}
+
+// ParseIntPredicate returns the integer predicate of the given keyword, e.g.
+// IntSlt for "slt".
+func ParseIntPredicate(s string) (IntPredicate, error) {
+	for pred, name := range intPredNames {
+		if name == s {
+			return pred, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid integer predicate %q", s)
+}
+
+// String returns a string representation of the icmp instruction, e.g.
+//
+//    %x = icmp slt i32 %a, %b
+func (inst *IcmpInst) String() string {
+	mnem := "icmp " + inst.Pred.String()
+	return inst.attach(inst.assign(binaryString(mnem, inst.Type, inst.Op1, inst.Op2)))
+}
+
 // The FcmpInst compares floating point values.
 //
 // Syntax:
-//    <Result> = fcmp <Pred> <Type> <Op1>, <Op2>
+//    <Result> = fcmp [FastMathFlags] <Pred> <Type> <Op1>, <Op2>
 //
 // Semantics:
 //    Result = (Op1 Pred Op2); // Where Pred is ==, !=, >, >=, < or <=.
@@ -559,6 +2124,8 @@ const (
 // References:
 //    http://llvm.org/docs/LangRef.html#fcmp-instruction
 type FcmpInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
 	// Comparison operation.
 	Pred FloatPredicate
 	// TODO: Restrict to FloatsType and FloatsValue?
@@ -567,6 +2134,10 @@ type FcmpInst struct {
 	Type types.Type
 	// Operands.
 	Op1, Op2 values.Value
+	// Fast-math flags.
+	FastMath FastMathFlags
+	// Metadata attachments.
+	Metadata
 }
 
 // FloatPredicate specifies a comparison operation to perform between two
@@ -591,9 +2162,58 @@ const (
 	FloatUne                         // unordered or not equal
 	FloatUno                         // unordered (either nans)
 	FloatTrue                        // no comparison, always returns true
-
 )
 
+// floatPredNames maps from floating point predicate to keyword.
+var floatPredNames = map[FloatPredicate]string{
+	FloatFalse: "false",
+	FloatOeq:   "oeq",
+	FloatOgt:   "ogt",
+	FloatOge:   "oge",
+	FloatOlt:   "olt",
+	FloatOle:   "ole",
+	FloatOne:   "one",
+	FloatOrd:   "ord",
+	FloatUeq:   "ueq",
+	FloatUgt:   "ugt",
+	FloatUge:   "uge",
+	FloatUlt:   "ult",
+	FloatUle:   "ule",
+	FloatUne:   "une",
+	FloatUno:   "uno",
+	FloatTrue:  "true",
+}
+
+// String returns the keyword of the floating point predicate, e.g.
+//
+//    oeq
+func (pred FloatPredicate) String() string {
+	if s, ok := floatPredNames[pred]; ok {
+		return s
+	}
+	return fmt.Sprintf("FloatPredicate(%d)", int(pred))
+}
+
+// ParseFloatPredicate returns the floating point predicate of the given
+// keyword, e.g. FloatOeq for "oeq".
+func ParseFloatPredicate(s string) (FloatPredicate, error) {
+	for pred, name := range floatPredNames {
+		if name == s {
+			return pred, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid floating point predicate %q", s)
+}
+
+// String returns a string representation of the fcmp instruction, e.g.
+//
+//    %x = fcmp one float %a, %b
+//    %x = fcmp nnan olt double %a, %b
+func (inst *FcmpInst) String() string {
+	mnem := fastMath("fcmp", inst.FastMath) + " " + inst.Pred.String()
+	return inst.attach(inst.assign(binaryString(mnem, inst.Type, inst.Op1, inst.Op2)))
+}
+
 // The PhiInst is used to implement φ nodes in the SSA graph representation of a
 // function.
 //
@@ -606,29 +2226,503 @@ const (
 // References:
 //    http://llvm.org/docs/LangRef.html#phi-instruction
 type PhiInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
 	// Value type.
 	Type types.Type
 	// Predecessor basic block labels and their corresponding values.
 	Preds map[string]values.Value
+	// Predecessor basic block labels in the order their incoming values were
+	// added using AddIncoming.
+	order []string
+	// Metadata attachments.
+	Metadata
+}
+
+// AddIncoming adds the incoming value val from the predecessor basic block
+// block to the phi instruction. Incoming values added this way are printed in
+// insertion order, before any incoming values set directly in Preds.
+func (inst *PhiInst) AddIncoming(block *BasicBlock, val values.Value) {
+	if inst.Preds == nil {
+		inst.Preds = make(map[string]values.Value)
+	}
+	if _, ok := inst.Preds[block.Name]; !ok {
+		inst.order = append(inst.order, block.Name)
+	}
+	inst.Preds[block.Name] = val
+}
+
+// String returns a string representation of the phi instruction, e.g.
+//
+//    %x = phi i32 [ 42, %a ], [ %y, %b ]
+func (inst *PhiInst) String() string {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "phi %v ", inst.Type)
+	for i, pred := range inst.predNames() {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		fmt.Fprintf(buf, "[ %s, %%%s ]", inst.Preds[pred].Ident(), pred)
+	}
+	return inst.attach(inst.assign(buf.String()))
+}
+
+// Validate validates the incoming values of the phi instruction against the
+// given predecessor basic block names of its parent basic block. An error is
+// returned if the phi instruction has an incoming value for a basic block which
+// is not a predecessor, or lacks an incoming value for a predecessor.
+func (inst *PhiInst) Validate(predecessors []string) error {
+	isPred := make(map[string]bool)
+	for _, pred := range predecessors {
+		isPred[pred] = true
+	}
+	for _, pred := range inst.predNames() {
+		if !isPred[pred] {
+			return fmt.Errorf("invalid phi incoming value for %q; not a predecessor basic block", pred)
+		}
+	}
+	preds := append([]string(nil), predecessors...)
+	sort.Strings(preds)
+	for _, pred := range preds {
+		if _, ok := inst.Preds[pred]; !ok {
+			return fmt.Errorf("invalid phi; missing incoming value for predecessor basic block %q", pred)
+		}
+	}
+	return nil
+}
+
+// The SelectInst selects one of two values based on a condition.
+//
+// Syntax:
+//    <Result> = select <CondType> <Cond>, <Type> <TrueValue>, <Type> <FalseValue>
+//
+// Semantics:
+//    Result = Cond ? TrueValue : FalseValue; // element-wise for vector conditions.
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#select-instruction
+type SelectInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
+	// Selection condition; of type i1 or vector of i1.
+	Cond values.Value
+	// Value type.
+	Type types.Type
+	// Value selected if Cond is true.
+	TrueValue values.Value
+	// Value selected if Cond is false.
+	FalseValue values.Value
+	// Metadata attachments.
+	Metadata
+}
+
+// NewSelect returns a new select instruction which selects trueValue if cond
+// is true and falseValue otherwise.
+func NewSelect(cond, trueValue, falseValue values.Value) (*SelectInst, error) {
+	// Verify type of condition.
+	condType := cond.Type()
+	if !isBools(condType) {
+		return nil, fmt.Errorf("invalid select condition; expected i1 (or vector of i1), got %q", condType)
+	}
+
+	// Verify operand types.
+	typ := trueValue.Type()
+	if !typ.Equal(falseValue.Type()) {
+		return nil, fmt.Errorf("invalid select operands; type mismatch between %q and %q", typ, falseValue.Type())
+	}
+	if isVector(condType) && !(isVector(typ) && types.SameLength(condType, typ)) {
+		return nil, fmt.Errorf("invalid select operands; vector condition %q requires operands of the same length, got %q", condType, typ)
+	}
+
+	return &SelectInst{Cond: cond, Type: typ, TrueValue: trueValue, FalseValue: falseValue}, nil
+}
+
+// String returns a string representation of the select instruction, e.g.
+//
+//    %y = select i1 %cond, i32 %a, i32 42
+func (inst *SelectInst) String() string {
+	return inst.attach(inst.assign(fmt.Sprintf("select %v, %v, %v", inst.Cond, inst.TrueValue, inst.FalseValue)))
+}
+
+// The CallInst represents a simple function call.
+//
+// Syntax:
+//    <Result> = call [CallConv] <Type> <Callee>(<Args>)
+//
+// Semantics:
+//    Result = Callee(Args...);
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#call-instruction
+type CallInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
+	// Result type.
+	Type types.Type
+	// Callee; a function or a pointer to a function.
+	Callee values.Value
+	// Function arguments.
+	Args []values.Value
+	// Calling convention of the call, which must match the calling convention
+	// of the callee.
+	CallConv CallConv
+	// Metadata attachments.
+	Metadata
+}
+
+// NewCall returns a new call instruction which invokes callee with the given
+// function arguments.
+func NewCall(callee values.Value, args []values.Value) (*CallInst, error) {
+	sig, err := checkCall(callee, args)
+	if err != nil {
+		return nil, err
+	}
+	return &CallInst{Type: sig.Result(), Callee: callee, Args: args}, nil
+}
+
+// String returns a string representation of the call instruction, e.g.
+//
+//    %x = call i32 @f(i32 %a, i32 42)
+//    call fastcc void @g()
+//    %y = call i32 (i8*, ...) @printf(i8* %format, i32 %x)
+func (inst *CallInst) String() string {
+	buf := new(bytes.Buffer)
+	buf.WriteString("call ")
+	if inst.CallConv != CallConvC {
+		fmt.Fprintf(buf, "%v ", inst.CallConv)
+	}
+	buf.WriteString(callString(inst.Type, inst.Callee, inst.Args))
+	if isVoid(inst) {
+		return inst.attach(buf.String())
+	}
+	return inst.attach(inst.assign(buf.String()))
+}
+
+// Validate verifies that the callee of the call instruction is a function (or
+// pointer to function) which may be invoked with its function arguments, and
+// that the result type of the call matches the signature of the callee.
+func (inst *CallInst) Validate() error {
+	if inst.Callee == nil {
+		return errors.New("invalid call; missing callee")
+	}
+	sig, err := checkCall(inst.Callee, inst.Args)
+	if err != nil {
+		return err
+	}
+	if !sig.Result().Equal(inst.Type) {
+		return fmt.Errorf("invalid call result type; expected %q, got %q", sig.Result(), inst.Type)
+	}
+	return nil
+}
+
+// callString returns the string representation of a call to callee with the
+// given result type and function arguments, as used by the call and invoke
+// instructions, e.g.
+//
+//    i32 @f(i32 %a, i32 42)
+//    i32 (i8*, ...) @printf(i8* %format, i32 %x)
+//
+// The full function signature is printed in place of the result type when the
+// callee is variadic.
+func callString(typ types.Type, callee values.Value, args []values.Value) string {
+	buf := new(bytes.Buffer)
+	if sig, ok := calleeSig(callee.Type()); ok && sig.IsVariadic() {
+		typ = sig
+	}
+	fmt.Fprintf(buf, "%v %s(", typ, callee.Ident())
+	for i, arg := range args {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(arg.String())
+	}
+	buf.WriteString(")")
+	return buf.String()
+}
+
+// checkCall verifies that callee is a function (or pointer to function) which
+// may be invoked with the given function arguments, and returns its function
+// signature.
+func checkCall(callee values.Value, args []values.Value) (*types.Func, error) {
+	// Verify callee type.
+	sig, ok := calleeSig(callee.Type())
+	if !ok {
+		return nil, fmt.Errorf("invalid callee type; expected function (or pointer to function), got %q", callee.Type())
+	}
+
+	// Verify function arguments.
+	params := sig.Params()
+	if len(args) < len(params) || (len(args) > len(params) && !sig.IsVariadic()) {
+		return nil, fmt.Errorf("invalid number of function arguments; expected %d, got %d", len(params), len(args))
+	}
+	for i, arg := range args {
+		if arg == nil {
+			return nil, fmt.Errorf("invalid function argument %d; missing value", i)
+		}
+	}
+	for i, param := range params {
+		if arg := args[i].Type(); !param.Equal(arg) {
+			return nil, fmt.Errorf("invalid function argument %d; expected %q, got %q", i, param, arg)
+		}
+	}
+
+	return sig, nil
 }
 
 // TODO: Add the following instructions:
-//    - select
-//    - call
 //    - va_arg
-//    - landingpad
+
+// The LandingpadInst specifies that a basic block is a landing site for an
+// exception, and the value it yields contains the information carried by the
+// exception.
+//
+// Syntax:
+//    <Result> = landingpad <Type> cleanup <Clause>*
+//    <Result> = landingpad <Type> <Clause>+
+//
+//    <Clause> = catch <Type> <Val>
+//    <Clause> = filter <ArrayType> <ArrayConst>
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#landingpad-instruction
+type LandingpadInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
+	// Result type.
+	Type types.Type
+	// Specifies if the landing pad is a cleanup.
+	Cleanup bool
+	// Catch and filter clauses.
+	Clauses []LandingpadClause
+	// Metadata attachments.
+	Metadata
+}
+
+// A LandingpadClause is a catch or filter clause of a landingpad instruction.
+type LandingpadClause struct {
+	// Specifies if the clause is a filter clause; otherwise it is a catch
+	// clause.
+	Filter bool
+	// Clause value; the type of exceptions to catch (catch clause) or an array
+	// of exception types to filter (filter clause).
+	Val values.Value
+}
+
+// NewLandingpad returns a new landingpad instruction of the given result type
+// with the specified catch and filter clauses.
+func NewLandingpad(typ types.Type, cleanup bool, clauses []LandingpadClause) (*LandingpadInst, error) {
+	if !cleanup && len(clauses) == 0 {
+		return nil, errors.New("invalid landingpad; non-cleanup landingpad requires at least one clause")
+	}
+	for i, clause := range clauses {
+		if _, ok := clause.Val.Type().(*types.Array); clause.Filter && !ok {
+			return nil, fmt.Errorf("invalid landingpad filter clause %d; expected array type, got %q", i, clause.Val.Type())
+		}
+	}
+	return &LandingpadInst{Type: typ, Cleanup: cleanup, Clauses: clauses}, nil
+}
+
+// String returns a string representation of the landingpad instruction, e.g.
+//
+//    %lp = landingpad {i8*, i32} cleanup
+//    %lp = landingpad {i8*, i32} catch i8* @_ZTIi filter [1 x i8*] [i8* @_ZTIc]
+func (inst *LandingpadInst) String() string {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "landingpad %v", inst.Type)
+	if inst.Cleanup {
+		buf.WriteString(" cleanup")
+	}
+	for _, clause := range inst.Clauses {
+		if clause.Filter {
+			fmt.Fprintf(buf, " filter %v", clause.Val)
+		} else {
+			fmt.Fprintf(buf, " catch %v", clause.Val)
+		}
+	}
+	return inst.attach(inst.assign(buf.String()))
+}
 
 // isInst ensures that only non-terminator instructions can be assigned to the
 // Instruction interface.
-func (AddInst) isInst()  {}
-func (FaddInst) isInst() {}
-func (SubInst) isInst()  {}
-func (FsubInst) isInst() {}
-func (MulInst) isInst()  {}
-func (FmulInst) isInst() {}
-func (UdivInst) isInst() {}
-func (SdivInst) isInst() {}
-func (FdivInst) isInst() {}
-func (UremInst) isInst() {}
-func (SremInst) isInst() {}
-func (FremInst) isInst() {}
+func (AddInst) isInst()           {}
+func (FaddInst) isInst()          {}
+func (SubInst) isInst()           {}
+func (FsubInst) isInst()          {}
+func (MulInst) isInst()           {}
+func (FmulInst) isInst()          {}
+func (UdivInst) isInst()          {}
+func (SdivInst) isInst()          {}
+func (FdivInst) isInst()          {}
+func (UremInst) isInst()          {}
+func (SremInst) isInst()          {}
+func (FremInst) isInst()          {}
+func (ShlInst) isInst()           {}
+func (LshrInst) isInst()          {}
+func (AshrInst) isInst()          {}
+func (AndInst) isInst()           {}
+func (OrInst) isInst()            {}
+func (XorInst) isInst()           {}
+func (ExtractvalueInst) isInst()  {}
+func (InsertvalueInst) isInst()   {}
+func (AllocaInst) isInst()        {}
+func (LoadInst) isInst()          {}
+func (StoreInst) isInst()         {}
+func (FenceInst) isInst()         {}
+func (GetelementptrInst) isInst() {}
+func (TruncInst) isInst()         {}
+func (ZextInst) isInst()          {}
+func (SextInst) isInst()          {}
+func (FptruncInst) isInst()       {}
+func (FpextInst) isInst()         {}
+func (FptouiInst) isInst()        {}
+func (FptosiInst) isInst()        {}
+func (UitofpInst) isInst()        {}
+func (SitofpInst) isInst()        {}
+func (PtrtointInst) isInst()      {}
+func (InttoptrInst) isInst()      {}
+func (BitcastInst) isInst()       {}
+func (AddrspacecastInst) isInst() {}
+func (IcmpInst) isInst()          {}
+func (FcmpInst) isInst()          {}
+func (PhiInst) isInst()           {}
+func (SelectInst) isInst()        {}
+func (CallInst) isInst()          {}
+func (LandingpadInst) isInst()    {}
+
+// SetOverflowFlags sets the nuw (no unsigned wrap) and nsw (no signed wrap)
+// overflow flags of the given add, sub, mul or shl instruction. An error is
+// returned for instructions which do not support overflow flags (e.g. fadd).
+func SetOverflowFlags(inst Instruction, nuw, nsw bool) error {
+	switch inst := inst.(type) {
+	case *AddInst:
+		inst.NUW, inst.NSW = nuw, nsw
+	case *SubInst:
+		inst.NUW, inst.NSW = nuw, nsw
+	case *MulInst:
+		inst.NUW, inst.NSW = nuw, nsw
+	case *ShlInst:
+		inst.NUW, inst.NSW = nuw, nsw
+	default:
+		return fmt.Errorf("invalid overflow flags; %s instruction does not support nuw and nsw", Opcode(inst))
+	}
+	return nil
+}
+
+// SetExact sets the exact flag of the given udiv, sdiv, lshr or ashr
+// instruction. An error is returned for instructions which do not support the
+// exact flag (e.g. fdiv).
+func SetExact(inst Instruction, exact bool) error {
+	switch inst := inst.(type) {
+	case *UdivInst:
+		inst.Exact = exact
+	case *SdivInst:
+		inst.Exact = exact
+	case *LshrInst:
+		inst.Exact = exact
+	case *AshrInst:
+		inst.Exact = exact
+	default:
+		return fmt.Errorf("invalid exact flag; %s instruction does not support exact", Opcode(inst))
+	}
+	return nil
+}
+
+// overflowFlags returns the given mnemonic followed by the nuw and nsw overflow
+// flags, if set, e.g.
+//
+//    add nuw nsw
+func overflowFlags(mnem string, nuw, nsw bool) string {
+	if nuw {
+		mnem += " nuw"
+	}
+	if nsw {
+		mnem += " nsw"
+	}
+	return mnem
+}
+
+// exactFlag returns the given mnemonic followed by the exact flag, if set, e.g.
+//
+//    sdiv exact
+func exactFlag(mnem string, exact bool) string {
+	if exact {
+		mnem += " exact"
+	}
+	return mnem
+}
+
+// binaryString returns a string representation of the binary instruction with
+// the given mnemonic, operand type and operands, e.g.
+//
+//    add i32 %a, %b
+func binaryString(mnem string, typ types.Type, op1, op2 values.Value) string {
+	return fmt.Sprintf("%s %v %s, %s", mnem, typ, op1.Ident(), op2.Ident())
+}
+
+// elem returns the element type of t if t is a vector type, and t otherwise.
+func elem(t types.Type) types.Type {
+	if t, ok := t.(*types.Vector); ok {
+		return t.Elem()
+	}
+	return t
+}
+
+// sameAddrSpace returns true if the pointer types (or vectors of pointers) a
+// and b belong to the same address space, and false otherwise.
+func sameAddrSpace(a, b types.Type) bool {
+	return elem(a).(*types.Pointer).AddrSpace() == elem(b).(*types.Pointer).AddrSpace()
+}
+
+// isBools returns true if t is a boolean type (i1) or a vector of booleans, and
+// false otherwise.
+func isBools(t types.Type) bool {
+	if t, ok := elem(t).(*types.Int); ok {
+		return t.Size() == 1
+	}
+	return false
+}
+
+// isVector returns true if t is a vector type, and false otherwise.
+func isVector(t types.Type) bool {
+	_, ok := t.(*types.Vector)
+	return ok
+}
+
+// calleeSig returns the function signature of a callee of type t, which is
+// either a function type or a pointer to a function type. The boolean return
+// value is false for any other type.
+func calleeSig(t types.Type) (*types.Func, bool) {
+	if t, ok := t.(*types.Pointer); ok {
+		sig, ok := t.Elem().(*types.Func)
+		return sig, ok
+	}
+	sig, ok := t.(*types.Func)
+	return sig, ok
+}
+
+// isAggregate returns true if t is an aggregate type (array or structure), and
+// false otherwise.
+func isAggregate(t types.Type) bool {
+	switch t.(type) {
+	case *types.Array, *types.Struct, *types.IdentifiedStruct:
+		return true
+	}
+	return false
+}
+
+// bitSize returns the size in bits of the integer, floating point, x86_mmx or
+// vector type t. The boolean return value is false for types without a target
+// independent size.
+func bitSize(t types.Type) (int, bool) {
+	switch t := t.(type) {
+	case *types.Int:
+		return t.Size(), true
+	case *types.Float:
+		return t.Size(), true
+	case *types.MMX:
+		return 64, true
+	case *types.Vector:
+		return t.Size()
+	}
+	return 0, false
+}
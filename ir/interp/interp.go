@@ -0,0 +1,494 @@
+// Package interp implements an interpreter of LLVM IR functions, which
+// evaluates functions over concrete constant inputs.
+//
+// The interpreter supports the binary, bitwise binary, integer conversion,
+// comparison, select, phi and call instructions, the alloca, load, store and
+// getelementptr memory instructions (through a simulated heap), and the ret, br,
+// switch and unreachable terminators. Aggregate and vector values are not
+// supported, and neither are calls to external functions.
+package interp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/llir/llvm/consts"
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/types"
+	"github.com/llir/llvm/values"
+)
+
+// Run evaluates the function fn with the given function arguments, and returns
+// its result; or nil if the function returns void. The function arguments must
+// be constants of the parameter types of the function.
+//
+// An error is returned if the function executes an unsupported instruction, or
+// one with undefined behaviour (e.g. division by zero, or loading from memory
+// out of bounds).
+func Run(fn *ir.Function, args []values.Value) (values.Value, error) {
+	m := &machine{globals: make(map[*ir.Global]*object)}
+	return m.call(fn, args)
+}
+
+// A machine holds the state of the interpreter shared between function calls.
+type machine struct {
+	// Memory objects of global variables, allocated on first use.
+	globals map[*ir.Global]*object
+}
+
+// A frame holds the state of a function invocation.
+type frame struct {
+	// Values of the function parameters and instruction results, mapping from
+	// local name to value.
+	locals map[string]values.Value
+}
+
+// call evaluates the function fn with the given function arguments.
+func (m *machine) call(fn *ir.Function, args []values.Value) (values.Value, error) {
+	if len(fn.Blocks) == 0 {
+		return nil, fmt.Errorf("unable to call external function %s", fn.Ident())
+	}
+	params := fn.Sig.Params()
+	if len(args) != len(params) {
+		return nil, fmt.Errorf("invalid number of function arguments to %s; expected %d, got %d", fn.Ident(), len(params), len(args))
+	}
+	fr := &frame{locals: make(map[string]values.Value)}
+	for i, arg := range args {
+		if arg == nil {
+			return nil, fmt.Errorf("invalid function argument %d to %s; missing value", i, fn.Ident())
+		}
+		if !arg.Type().Equal(params[i]) {
+			return nil, fmt.Errorf("invalid function argument %d to %s; expected %q, got %q", i, fn.Ident(), params[i], arg.Type())
+		}
+		if i < len(fn.Params) {
+			fr.locals[fn.Params[i].Name] = arg
+		}
+	}
+
+	var pred *ir.BasicBlock
+	block := fn.Entry()
+	for {
+		// The phi instructions at the beginning of a basic block are evaluated
+		// simultaneously, based on the predecessor basic block.
+		phis := make(map[ir.Instruction]values.Value)
+		for _, inst := range block.Insts {
+			phi, ok := inst.(*ir.PhiInst)
+			if !ok {
+				break
+			}
+			if pred == nil {
+				return nil, fmt.Errorf("%s: phi in entry basic block", block.Ident())
+			}
+			v, ok := phi.Preds[pred.Name]
+			if !ok {
+				return nil, fmt.Errorf("%s: missing phi incoming value for predecessor %s", block.Ident(), pred.Ident())
+			}
+			val, err := m.eval(fr, v)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", block.Ident(), err)
+			}
+			phis[inst] = val
+		}
+		for _, inst := range block.Insts {
+			val, ok := phis[inst]
+			if !ok {
+				var err error
+				if val, err = m.exec(fr, inst); err != nil {
+					return nil, fmt.Errorf("%s: %v", block.Ident(), err)
+				}
+			}
+			if val == nil {
+				continue
+			}
+			// Unnamed results cannot be referenced, and are therefore not
+			// recorded.
+			if local, err := ir.NewLocal(inst, val.Type()); err == nil {
+				fr.locals[local.Name] = val
+			}
+		}
+
+		next, ret, err := m.term(fr, block.Term)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", block.Ident(), err)
+		}
+		if next == nil {
+			return ret, nil
+		}
+		pred, block = block, next
+	}
+}
+
+// exec evaluates the given non-terminator instruction, and returns its result;
+// or nil if it produces no value.
+func (m *machine) exec(fr *frame, inst ir.Instruction) (values.Value, error) {
+	switch inst := inst.(type) {
+	// Binary, bitwise binary and integer comparison instructions.
+	case *ir.AddInst, *ir.FaddInst, *ir.SubInst, *ir.FsubInst, *ir.MulInst, *ir.FmulInst, *ir.UdivInst, *ir.SdivInst, *ir.FdivInst, *ir.UremInst, *ir.SremInst, *ir.FremInst,
+		*ir.ShlInst, *ir.LshrInst, *ir.AshrInst, *ir.AndInst, *ir.OrInst, *ir.XorInst,
+		*ir.IcmpInst:
+		return m.fold(fr, inst)
+	case *ir.FcmpInst:
+		x, err := m.evalFloat(fr, inst.Op1)
+		if err != nil {
+			return nil, err
+		}
+		y, err := m.evalFloat(fr, inst.Op2)
+		if err != nil {
+			return nil, err
+		}
+		cond, err := fcmp(inst.Pred, x, y)
+		if err != nil {
+			return nil, err
+		}
+		return newBool(cond), nil
+
+	// Memory instructions.
+	case *ir.AllocaInst:
+		typ, err := types.NewPointer(inst.Type)
+		if err != nil {
+			return nil, err
+		}
+		obj := &object{slots: make([]values.Value, slots(inst.Type)*inst.NumElems)}
+		return &pointer{typ: typ, obj: obj}, nil
+	case *ir.LoadInst:
+		p, err := m.evalPointer(fr, inst.Addr)
+		if err != nil {
+			return nil, err
+		}
+		return p.load(inst.Type)
+	case *ir.StoreInst:
+		v, err := m.eval(fr, inst.Val)
+		if err != nil {
+			return nil, err
+		}
+		p, err := m.evalPointer(fr, inst.Addr)
+		if err != nil {
+			return nil, err
+		}
+		return nil, p.store(v)
+	case *ir.GetelementptrInst:
+		p, err := m.evalPointer(fr, inst.Ptr)
+		if err != nil {
+			return nil, err
+		}
+		typ, err := inst.ResultType()
+		if err != nil {
+			return nil, err
+		}
+		off, err := offset(inst.Type, inst.Indicies)
+		if err != nil {
+			return nil, err
+		}
+		return &pointer{typ: typ, obj: p.obj, off: p.off + off}, nil
+
+	// Conversion instructions.
+	case *ir.TruncInst:
+		return m.convInt(fr, inst.From, inst.To, signed)
+	case *ir.ZextInst:
+		return m.convInt(fr, inst.From, inst.To, unsigned)
+	case *ir.SextInst:
+		return m.convInt(fr, inst.From, inst.To, signed)
+
+	// Other instructions.
+	case *ir.SelectInst:
+		cond, err := m.evalBool(fr, inst.Cond)
+		if err != nil {
+			return nil, err
+		}
+		if cond {
+			return m.eval(fr, inst.TrueValue)
+		}
+		return m.eval(fr, inst.FalseValue)
+	case *ir.CallInst:
+		callee, err := m.eval(fr, inst.Callee)
+		if err != nil {
+			return nil, err
+		}
+		f, ok := callee.(*ir.Function)
+		if !ok {
+			return nil, fmt.Errorf("unsupported indirect call of %v", callee)
+		}
+		var args []values.Value
+		for _, arg := range inst.Args {
+			v, err := m.eval(fr, arg)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, v)
+		}
+		return m.call(f, args)
+	}
+	return nil, fmt.Errorf("unsupported instruction %q", inst)
+}
+
+// term evaluates the given terminator, and returns the basic block to which it
+// transfers control flow; or nil and the returned value (which is nil for void)
+// if it returns from the function.
+func (m *machine) term(fr *frame, term ir.Terminator) (*ir.BasicBlock, values.Value, error) {
+	switch term := term.(type) {
+	case nil:
+		return nil, nil, errors.New("missing terminator")
+	case *ir.ReturnInst:
+		if term.Val == nil {
+			return nil, nil, nil
+		}
+		v, err := m.eval(fr, term.Val)
+		return nil, v, err
+	case *ir.BranchInst:
+		return term.Target, nil, nil
+	case *ir.CondBranchInst:
+		cond, err := m.evalBool(fr, term.Cond)
+		if err != nil {
+			return nil, nil, err
+		}
+		if cond {
+			return term.True, nil, nil
+		}
+		return term.False, nil, nil
+	case *ir.SwitchInst:
+		v, err := m.evalInt(fr, term.Val)
+		if err != nil {
+			return nil, nil, err
+		}
+		size := uint(v.Type().(*types.Int).Size())
+		for _, c := range term.Cases {
+			x, ok := c.Val.(*consts.Int)
+			if ok && unsigned(x.BigInt(), size).Cmp(unsigned(v.BigInt(), size)) == 0 {
+				return c.Target, nil, nil
+			}
+		}
+		return term.Default, nil, nil
+	case *ir.UnreachableInst:
+		return nil, nil, errors.New("reached unreachable")
+	}
+	return nil, nil, fmt.Errorf("unsupported terminator %q", term)
+}
+
+// eval returns the value of the operand v.
+func (m *machine) eval(fr *frame, v values.Value) (values.Value, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, errors.New("missing operand")
+	case *values.Local:
+		if val, ok := fr.locals[v.Name]; ok {
+			return val, nil
+		}
+		return nil, fmt.Errorf("use of undefined value %s", v.Ident())
+	case *values.Param:
+		if val, ok := fr.locals[v.Name]; ok {
+			return val, nil
+		}
+		return nil, fmt.Errorf("use of undefined value %s", v.Ident())
+	case *ir.Global:
+		return m.global(v)
+	case *ir.Function, *pointer:
+		return v, nil
+	case consts.Expr:
+		return nil, fmt.Errorf("unsupported constant expression %v", v)
+	case consts.Constant:
+		return v, nil
+	}
+	return nil, fmt.Errorf("unsupported operand %v", v)
+}
+
+// evalInt returns the value of the integer operand v.
+func (m *machine) evalInt(fr *frame, v values.Value) (*consts.Int, error) {
+	val, err := m.eval(fr, v)
+	if err != nil {
+		return nil, err
+	}
+	x, ok := val.(*consts.Int)
+	if !ok {
+		return nil, fmt.Errorf("unsupported operand %v; expected integer constant", val)
+	}
+	return x, nil
+}
+
+// evalBool returns the value of the boolean operand v.
+func (m *machine) evalBool(fr *frame, v values.Value) (bool, error) {
+	x, err := m.evalInt(fr, v)
+	if err != nil {
+		return false, err
+	}
+	if !x.Type().Equal(types.I1) {
+		return false, fmt.Errorf("invalid condition type; expected i1, got %q", x.Type())
+	}
+	return x.BigInt().Sign() != 0, nil
+}
+
+// evalFloat returns the value of the floating point operand v.
+func (m *machine) evalFloat(fr *frame, v values.Value) (float64, error) {
+	val, err := m.eval(fr, v)
+	if err != nil {
+		return 0, err
+	}
+	x, ok := val.(*consts.Float)
+	if !ok {
+		return 0, fmt.Errorf("unsupported operand %v; expected floating point constant", val)
+	}
+	return x.Float64(), nil
+}
+
+// evalPointer returns the value of the pointer operand v.
+func (m *machine) evalPointer(fr *frame, v values.Value) (*pointer, error) {
+	val, err := m.eval(fr, v)
+	if err != nil {
+		return nil, err
+	}
+	switch val := val.(type) {
+	case *pointer:
+		return val, nil
+	case *consts.Null:
+		return nil, errors.New("null pointer dereference")
+	}
+	return nil, fmt.Errorf("unsupported operand %v; expected pointer", val)
+}
+
+// fold evaluates the given binary, bitwise binary or icmp instruction by folding
+// a copy of it with constant operands.
+func (m *machine) fold(fr *frame, inst ir.Instruction) (values.Value, error) {
+	c := inst.Clone()
+	for i, op := range inst.Operands() {
+		v, err := m.eval(fr, op)
+		if err != nil {
+			return nil, err
+		}
+		switch v.(type) {
+		case *consts.Int, *consts.Float:
+		default:
+			return nil, fmt.Errorf("unsupported operand %v of %q", v, inst)
+		}
+		if err := c.SetOperand(i, v); err != nil {
+			return nil, err
+		}
+	}
+	v, ok := ir.Fold(c)
+	if !ok {
+		return nil, fmt.Errorf("undefined result of %q (e.g. division by zero, oversized shift or overflow)", c)
+	}
+	return v, nil
+}
+
+// convInt evaluates the integer conversion of the operand from to the integer
+// type to, where wrap interprets the value of from within its size.
+func (m *machine) convInt(fr *frame, from values.Value, to types.Type, wrap func(x *big.Int, size uint) *big.Int) (values.Value, error) {
+	x, err := m.evalInt(fr, from)
+	if err != nil {
+		return nil, err
+	}
+	typ, ok := to.(*types.Int)
+	if !ok {
+		return nil, fmt.Errorf("unsupported conversion to %q; expected integer type", to)
+	}
+	size := uint(x.Type().(*types.Int).Size())
+	return newInt(typ, wrap(x.BigInt(), size)), nil
+}
+
+// global returns a pointer to the memory object of the given global variable,
+// which is allocated and initialized on first use.
+func (m *machine) global(g *ir.Global) (*pointer, error) {
+	typ, ok := g.Type().(*types.Pointer)
+	if !ok {
+		return nil, fmt.Errorf("invalid global variable type %q; expected pointer", g.Type())
+	}
+	obj, ok := m.globals[g]
+	if !ok {
+		obj = &object{slots: make([]values.Value, slots(g.Content))}
+		switch init := g.Init.(type) {
+		case nil:
+			// External global variables are left uninitialized.
+		case *consts.Int, *consts.Float, *consts.Null:
+			obj.slots[0] = init
+		default:
+			return nil, fmt.Errorf("unsupported initializer %v of global variable %s", init, g.Ident())
+		}
+		m.globals[g] = obj
+	}
+	return &pointer{typ: typ, obj: obj}, nil
+}
+
+// fcmp returns the result of comparing the floating point values x and y using
+// the given predicate.
+func fcmp(pred ir.FloatPredicate, x, y float64) (bool, error) {
+	uno := math.IsNaN(x) || math.IsNaN(y)
+	switch pred {
+	case ir.FloatFalse:
+		return false, nil
+	case ir.FloatOeq:
+		return !uno && x == y, nil
+	case ir.FloatOgt:
+		return !uno && x > y, nil
+	case ir.FloatOge:
+		return !uno && x >= y, nil
+	case ir.FloatOlt:
+		return !uno && x < y, nil
+	case ir.FloatOle:
+		return !uno && x <= y, nil
+	case ir.FloatOne:
+		return !uno && x != y, nil
+	case ir.FloatOrd:
+		return !uno, nil
+	case ir.FloatUeq:
+		return uno || x == y, nil
+	case ir.FloatUgt:
+		return uno || x > y, nil
+	case ir.FloatUge:
+		return uno || x >= y, nil
+	case ir.FloatUlt:
+		return uno || x < y, nil
+	case ir.FloatUle:
+		return uno || x <= y, nil
+	case ir.FloatUne:
+		return uno || x != y, nil
+	case ir.FloatUno:
+		return uno, nil
+	case ir.FloatTrue:
+		return true, nil
+	}
+	return false, fmt.Errorf("invalid floating point predicate %v", pred)
+}
+
+// newBool returns the boolean constant of the given value.
+func newBool(cond bool) *consts.Int {
+	if cond {
+		return newInt(types.I1, big.NewInt(1))
+	}
+	return newInt(types.I1, big.NewInt(0))
+}
+
+// newInt returns an integer constant of the given type, with the value x
+// wrapped around at the size of the type.
+func newInt(typ *types.Int, x *big.Int) *consts.Int {
+	size := uint(typ.Size())
+	if size == 1 {
+		// Booleans are represented as 0 or 1.
+		x = unsigned(x, size)
+	} else {
+		x = signed(x, size)
+	}
+	c, err := consts.NewInt(typ, x.String())
+	if err != nil {
+		// The value is within the range of the type after wrapping.
+		panic(err)
+	}
+	return c
+}
+
+// unsigned returns the value of x interpreted as an unsigned integer of the
+// given size, i.e. x modulo 2^size.
+func unsigned(x *big.Int, size uint) *big.Int {
+	m := new(big.Int).Lsh(big.NewInt(1), size)
+	return new(big.Int).Mod(x, m)
+}
+
+// signed returns the value of x interpreted as a signed integer of the given
+// size in two's complement representation (e.g. i1 true is -1).
+func signed(x *big.Int, size uint) *big.Int {
+	z := unsigned(x, size)
+	if z.Bit(int(size-1)) == 1 {
+		z.Sub(z, new(big.Int).Lsh(big.NewInt(1), size))
+	}
+	return z
+}
@@ -0,0 +1,199 @@
+package interp_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/llir/llvm/consts"
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/interp"
+	"github.com/llir/llvm/types"
+	"github.com/llir/llvm/values"
+)
+
+func TestRun(t *testing.T) {
+	i32 := func(s string) *consts.Int {
+		c, err := consts.NewInt(types.I32, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	sig, err := types.NewFunc(types.I32, []types.Type{types.I32}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := values.NewParam("n", types.I32)
+	// newFunc returns a function of signature i32 (i32 %n) with the given name
+	// and basic blocks.
+	newFunc := func(name string, blocks ...*ir.BasicBlock) *ir.Function {
+		f := &ir.Function{Name: name, Sig: sig, Params: []*values.Param{n}}
+		for _, block := range blocks {
+			f.AppendBlock(block)
+		}
+		return f
+	}
+
+	// Sum of the integers 1 through n.
+	//    entry:
+	//       br label %loop
+	//    loop:
+	//       %i = phi i32 [ 1, %entry ], [ %i1, %loop ]
+	//       %s = phi i32 [ 0, %entry ], [ %s1, %loop ]
+	//       %s1 = add i32 %s, %i
+	//       %i1 = add i32 %i, 1
+	//       %c = icmp sle i32 %i1, %n
+	//       br i1 %c, label %loop, label %exit
+	//    exit:
+	//       ret i32 %s1
+	i, i1 := values.NewLocal("i", types.I32), values.NewLocal("i1", types.I32)
+	s, s1 := values.NewLocal("s", types.I32), values.NewLocal("s1", types.I32)
+	c := values.NewLocal("c", types.I1)
+	entry := &ir.BasicBlock{Name: "entry"}
+	loop := &ir.BasicBlock{Name: "loop"}
+	exit := &ir.BasicBlock{Name: "exit", Term: ir.NewRet(s1)}
+	entry.SetTerm(ir.NewBr(loop))
+	phiI := &ir.PhiInst{LocalIdent: ir.LocalIdent{Name: "i"}, Type: types.I32}
+	phiI.AddIncoming(entry, i32("1"))
+	phiI.AddIncoming(loop, i1)
+	phiS := &ir.PhiInst{LocalIdent: ir.LocalIdent{Name: "s"}, Type: types.I32}
+	phiS.AddIncoming(entry, i32("0"))
+	phiS.AddIncoming(loop, s1)
+	loop.AppendInst(phiI)
+	loop.AppendInst(phiS)
+	loop.AppendInst(&ir.AddInst{LocalIdent: ir.LocalIdent{Name: "s1"}, Type: types.I32, Op1: s, Op2: i})
+	loop.AppendInst(&ir.AddInst{LocalIdent: ir.LocalIdent{Name: "i1"}, Type: types.I32, Op1: i, Op2: i32("1")})
+	loop.AppendInst(&ir.IcmpInst{LocalIdent: ir.LocalIdent{Name: "c"}, Pred: ir.IntSle, Type: types.I32, Op1: i1, Op2: n})
+	condbr, err := ir.NewCondBr(c, loop, exit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loop.SetTerm(condbr)
+	sum := newFunc("sum", entry, loop, exit)
+
+	// Memory accesses through getelementptr and a global variable.
+	//    @g = global i32 7
+	//
+	//    %a = alloca [4 x i32]
+	//    %p = getelementptr [4 x i32], [4 x i32]* %a, i32 0, i32 2
+	//    store i32 %n, i32* %p
+	//    %x = load i32, i32* %p
+	//    %y = load i32, i32* @g
+	//    %z = sub i32 %x, %y
+	//    ret i32 %z
+	arrTyp, err := types.NewArray(types.I32, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	arrPtrTyp, err := types.NewPointer(arrTyp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i32PtrTyp, err := types.NewPointer(types.I32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, p := values.NewLocal("a", arrPtrTyp), values.NewLocal("p", i32PtrTyp)
+	x, y := values.NewLocal("x", types.I32), values.NewLocal("y", types.I32)
+	g := &ir.Global{Name: "g", Content: types.I32, Init: i32("7")}
+	mem := &ir.BasicBlock{Name: "entry"}
+	mem.AppendInst(&ir.AllocaInst{LocalIdent: ir.LocalIdent{Name: "a"}, Type: arrTyp, NumElems: 1})
+	mem.AppendInst(&ir.GetelementptrInst{LocalIdent: ir.LocalIdent{Name: "p"}, Type: arrTyp, Ptr: a, Indicies: []int{0, 2}})
+	mem.AppendInst(&ir.StoreInst{Type: types.I32, Val: n, Addr: p})
+	mem.AppendInst(&ir.LoadInst{LocalIdent: ir.LocalIdent{Name: "x"}, Type: types.I32, Addr: p})
+	mem.AppendInst(&ir.LoadInst{LocalIdent: ir.LocalIdent{Name: "y"}, Type: types.I32, Addr: g})
+	mem.AppendInst(&ir.SubInst{LocalIdent: ir.LocalIdent{Name: "z"}, Type: types.I32, Op1: x, Op2: y})
+	mem.SetTerm(ir.NewRet(values.NewLocal("z", types.I32)))
+	memory := newFunc("memory", mem)
+
+	// Call of a function.
+	//    %r = call i32 @sum(i32 %n)
+	//    %t = trunc i32 %r to i8
+	//    %u = sext i8 %t to i32
+	//    ret i32 %u
+	call := &ir.BasicBlock{Name: "entry"}
+	call.AppendInst(&ir.CallInst{LocalIdent: ir.LocalIdent{Name: "r"}, Type: types.I32, Callee: sum, Args: []values.Value{n}})
+	call.AppendInst(&ir.TruncInst{LocalIdent: ir.LocalIdent{Name: "t"}, From: values.NewLocal("r", types.I32), To: types.I8})
+	call.AppendInst(&ir.SextInst{LocalIdent: ir.LocalIdent{Name: "u"}, From: values.NewLocal("t", types.I8), To: types.I32})
+	call.SetTerm(ir.NewRet(values.NewLocal("u", types.I32)))
+	caller := newFunc("caller", call)
+
+	// Division by zero.
+	//    %q = sdiv i32 42, %n
+	//    ret i32 %q
+	div := &ir.BasicBlock{Name: "entry"}
+	div.AppendInst(&ir.SdivInst{LocalIdent: ir.LocalIdent{Name: "q"}, Type: types.I32, Op1: i32("42"), Op2: n})
+	div.SetTerm(ir.NewRet(values.NewLocal("q", types.I32)))
+	divide := newFunc("divide", div)
+
+	// Out of bounds memory access.
+	//    %a = alloca i32
+	//    %p = getelementptr i32, i32* %a, i32 1
+	//    %x = load i32, i32* %p
+	//    ret i32 %x
+	a = values.NewLocal("a", i32PtrTyp)
+	oob := &ir.BasicBlock{Name: "entry"}
+	oob.AppendInst(&ir.AllocaInst{LocalIdent: ir.LocalIdent{Name: "a"}, Type: types.I32, NumElems: 1})
+	oob.AppendInst(&ir.GetelementptrInst{LocalIdent: ir.LocalIdent{Name: "p"}, Type: types.I32, Ptr: a, Indicies: []int{1}})
+	oob.AppendInst(&ir.LoadInst{LocalIdent: ir.LocalIdent{Name: "x"}, Type: types.I32, Addr: p})
+	oob.SetTerm(ir.NewRet(x))
+	outOfBounds := newFunc("out_of_bounds", oob)
+
+	// Unsupported instruction.
+	//    fence seq_cst
+	//    unreachable
+	fence, err := ir.NewFence(ir.AtomicSeqCst, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsupported := newFunc("unsupported", &ir.BasicBlock{Name: "entry", Insts: []ir.Instruction{fence}, Term: &ir.UnreachableInst{}})
+	unreachable := newFunc("unreachable", &ir.BasicBlock{Name: "entry", Term: &ir.UnreachableInst{}})
+
+	golden := []struct {
+		f    *ir.Function
+		args []values.Value
+		want string
+		err  string
+	}{
+		// i=0
+		{f: sum, args: []values.Value{i32("10")}, want: "i32 55"},
+		// i=1
+		{f: sum, args: []values.Value{i32("1")}, want: "i32 1"},
+		// i=2
+		{f: memory, args: []values.Value{i32("12")}, want: "i32 5"},
+		// i=3
+		{f: caller, args: []values.Value{i32("16")}, want: "i32 -120"},
+		// i=4
+		{f: divide, args: []values.Value{i32("6")}, want: "i32 7"},
+		// i=5
+		{f: divide, args: []values.Value{i32("0")}, err: `%entry: undefined result of "%q = sdiv i32 42, 0" (e.g. division by zero, oversized shift or overflow)`},
+		// i=6
+		{f: outOfBounds, args: []values.Value{i32("0")}, err: "%entry: memory access out of bounds; slot 1 of 1"},
+		// i=7
+		{f: unsupported, args: []values.Value{i32("0")}, err: `%entry: unsupported instruction "fence seq_cst"`},
+		// i=8
+		{f: unreachable, args: []values.Value{i32("0")}, err: "%entry: reached unreachable"},
+		// i=9
+		{f: sum, args: nil, err: "invalid number of function arguments to @sum; expected 1, got 0"},
+		// i=10
+		{f: sum, args: []values.Value{c}, err: `invalid function argument 0 to @sum; expected "i32", got "i1"`},
+	}
+
+	for i, g := range golden {
+		v, err := interp.Run(g.f, g.args)
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != g.err {
+			t.Errorf("i=%d: error mismatch; expected %q, got %q", i, g.err, got)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		if got := fmt.Sprint(v); got != g.want {
+			t.Errorf("i=%d: result mismatch; expected %q, got %q", i, g.want, got)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package interp
+
+import (
+	"fmt"
+
+	"github.com/llir/llvm/consts"
+	"github.com/llir/llvm/types"
+	"github.com/llir/llvm/values"
+)
+
+// An object is a memory object of the simulated heap, allocated by an alloca
+// instruction or for a global variable. Memory is modelled as a sequence of
+// slots, each holding a single scalar value; aggregates occupy one slot per
+// scalar element, in order.
+type object struct {
+	// Values stored in the memory object; nil if uninitialized.
+	slots []values.Value
+}
+
+// A pointer is a pointer into a memory object of the simulated heap.
+type pointer struct {
+	// Pointer type.
+	typ types.Type
+	// Memory object pointed to.
+	obj *object
+	// Slot offset into the memory object.
+	off int
+}
+
+// Type returns the type of the pointer.
+func (p *pointer) Type() types.Type {
+	return p.typ
+}
+
+// Ident returns the identifier associated with the pointer, which states the
+// address of its memory object and its slot offset, e.g.
+//
+//    <0xc000010018+2>
+func (p *pointer) Ident() string {
+	return fmt.Sprintf("<%p+%d>", p.obj, p.off)
+}
+
+// String returns a string representation of the pointer, e.g.
+//
+//    i32* <0xc000010018+2>
+func (p *pointer) String() string {
+	return fmt.Sprintf("%v %s", p.typ, p.Ident())
+}
+
+// load returns the value of the given scalar type stored at the pointer. Loads
+// from uninitialized memory yield undef.
+func (p *pointer) load(typ types.Type) (values.Value, error) {
+	if err := p.check(typ); err != nil {
+		return nil, err
+	}
+	v := p.obj.slots[p.off]
+	if v == nil {
+		return consts.NewUndef(typ)
+	}
+	if !v.Type().Equal(typ) {
+		return nil, fmt.Errorf("invalid load of %q from memory holding %v", typ, v)
+	}
+	return v, nil
+}
+
+// store stores the scalar value v at the pointer.
+func (p *pointer) store(v values.Value) error {
+	if err := p.check(v.Type()); err != nil {
+		return err
+	}
+	p.obj.slots[p.off] = v
+	return nil
+}
+
+// check verifies that a value of the given type may be accessed through the
+// pointer.
+func (p *pointer) check(typ types.Type) error {
+	switch typ.(type) {
+	case *types.Array, *types.Struct, *types.IdentifiedStruct, *types.Vector:
+		return fmt.Errorf("unsupported memory access of aggregate or vector type %q", typ)
+	}
+	if p.off < 0 || p.off >= len(p.obj.slots) {
+		return fmt.Errorf("memory access out of bounds; slot %d of %d", p.off, len(p.obj.slots))
+	}
+	return nil
+}
+
+// slots returns the number of memory slots occupied by a value of the given
+// type.
+func slots(typ types.Type) int {
+	switch typ := typ.(type) {
+	case *types.Array:
+		return typ.Len() * slots(typ.Elem())
+	case *types.Struct:
+		return fieldSlots(typ.Fields())
+	case *types.IdentifiedStruct:
+		return fieldSlots(typ.Fields())
+	}
+	return 1
+}
+
+// fieldSlots returns the number of memory slots occupied by the given
+// structure fields.
+func fieldSlots(fields []types.Type) int {
+	n := 0
+	for _, field := range fields {
+		n += slots(field)
+	}
+	return n
+}
+
+// offset returns the slot offset addressed by the indices of a getelementptr
+// instruction, where typ is the element type of its pointer operand.
+func offset(typ types.Type, indices []int) (int, error) {
+	if len(indices) == 0 {
+		return 0, nil
+	}
+	off := indices[0] * slots(typ)
+	for _, index := range indices[1:] {
+		switch t := typ.(type) {
+		case *types.Array:
+			typ = t.Elem()
+			off += index * slots(typ)
+		case *types.Struct, *types.IdentifiedStruct:
+			fields := t.(interface {
+				Fields() []types.Type
+			}).Fields()
+			if index < 0 || index >= len(fields) {
+				return 0, fmt.Errorf("invalid structure index (%d) for %q", index, typ)
+			}
+			off += fieldSlots(fields[:index])
+			typ = fields[index]
+		default:
+			return 0, fmt.Errorf("unsupported getelementptr index into %q", typ)
+		}
+	}
+	return off, nil
+}
@@ -0,0 +1,4945 @@
+package ir_test
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/llir/llvm/consts"
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/types"
+	"github.com/llir/llvm/values"
+)
+
+var (
+	// i1, i8, i32, i64
+	i1Typ, i8Typ, i32Typ, i64Typ *types.Int
+	// float, double
+	f32Typ, f64Typ *types.Float
+	// <2 x i8>
+	i8x2VecTyp *types.Vector
+	// <2 x i32>
+	i32x2VecTyp *types.Vector
+	// <3 x i8>
+	i8x3VecTyp *types.Vector
+	// <2 x float>
+	f32x2VecTyp *types.Vector
+	// <4 x i1>
+	i1x4VecTyp *types.Vector
+	// <4 x i32>
+	i32x4VecTyp *types.Vector
+	// i8*, i32*
+	i8PtrTyp, i32PtrTyp *types.Pointer
+	// i8 addrspace(1)*
+	i8Ptr1Typ *types.Pointer
+	// i8 3
+	i8Three consts.Constant
+	// i32 42
+	i32FortyTwo consts.Constant
+	// i64 42
+	i64FortyTwo consts.Constant
+	// float 3.0
+	f32Three consts.Constant
+	// double 3.0
+	f64Three consts.Constant
+	// <2 x i32> <i32 3, i32 42>
+	i32x2VecThreeFortyTwo consts.Constant
+	// <2 x float> <float 3.0, float 3.0>
+	f32x2VecThreeThree consts.Constant
+	// i8* %p
+	i8PtrP values.Value
+	// i32* %q
+	i32PtrQ values.Value
+	// i8 addrspace(1)* %r
+	i8Ptr1R values.Value
+	// i1 %cond
+	i1Cond values.Value
+	// <4 x i1> %conds
+	i1x4VecConds values.Value
+	// <4 x i32> %a, <4 x i32> %b
+	i32x4VecA, i32x4VecB values.Value
+	// i32 %x
+	i32X values.Value
+	// i32 (i32, i32)* %f
+	funcF values.Value
+	// i32 (i8*, ...)* %printf
+	funcPrintf values.Value
+	// void ()* %g
+	funcG values.Value
+	// {i8*, i32}
+	i8Ptri32StructTyp *types.Struct
+	// [1 x i8*] %filter
+	i8Ptrx1ArrFilter values.Value
+	// {i32, float} %s
+	i32f32StructS values.Value
+	// {{i8, i8}, i32} %n
+	i8i8i32StructN values.Value
+	// [2 x i32] %arr
+	i32x2ArrArr values.Value
+)
+
+func init() {
+	// i1
+	var err error
+	i1Typ, err = types.NewInt(1)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// i8
+	i8Typ, err = types.NewInt(8)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// i32
+	i32Typ, err = types.NewInt(32)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// i64
+	i64Typ, err = types.NewInt(64)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// float
+	f32Typ, err = types.NewFloat(types.Float32)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// double
+	f64Typ, err = types.NewFloat(types.Float64)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// <2 x i8>
+	i8x2VecTyp, err = types.NewVector(i8Typ, 2)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// <2 x i32>
+	i32x2VecTyp, err = types.NewVector(i32Typ, 2)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// <3 x i8>
+	i8x3VecTyp, err = types.NewVector(i8Typ, 3)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// <2 x float>
+	f32x2VecTyp, err = types.NewVector(f32Typ, 2)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// <4 x i1>
+	i1x4VecTyp, err = types.NewVector(i1Typ, 4)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// <4 x i32>
+	i32x4VecTyp, err = types.NewVector(i32Typ, 4)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// i8*
+	i8PtrTyp, err = types.NewPointer(i8Typ)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// i32*
+	i32PtrTyp, err = types.NewPointer(i32Typ)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// i8 addrspace(1)*
+	i8Ptr1Typ, err = types.NewPointerInAddrSpace(i8Typ, 1)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// i8 3
+	i8Three, err = consts.NewInt(i8Typ, "3")
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// i32 3
+	i32Three, err := consts.NewInt(i32Typ, "3")
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// i32 42
+	i32FortyTwo, err = consts.NewInt(i32Typ, "42")
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// i64 42
+	i64FortyTwo, err = consts.NewInt(i64Typ, "42")
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// float 3.0
+	f32Three, err = consts.NewFloat(f32Typ, "3.0")
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// double 3.0
+	f64Three, err = consts.NewFloat(f64Typ, "3.0")
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// <2 x i32> <i32 3, i32 42>
+	i32x2VecThreeFortyTwo, err = consts.NewVector(i32x2VecTyp, []consts.Constant{i32Three, i32FortyTwo})
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// <2 x float> <float 3.0, float 3.0>
+	f32x2VecThreeThree, err = consts.NewVector(f32x2VecTyp, []consts.Constant{f32Three, f32Three})
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// i8* %p
+	i8PtrP = &local{name: "p", typ: i8PtrTyp}
+	// i32* %q
+	i32PtrQ = &local{name: "q", typ: i32PtrTyp}
+	// i8 addrspace(1)* %r
+	i8Ptr1R = &local{name: "r", typ: i8Ptr1Typ}
+	// i1 %cond
+	i1Cond = &local{name: "cond", typ: i1Typ}
+	// <4 x i1> %conds
+	i1x4VecConds = &local{name: "conds", typ: i1x4VecTyp}
+	// <4 x i32> %a, <4 x i32> %b
+	i32x4VecA = &local{name: "a", typ: i32x4VecTyp}
+	i32x4VecB = &local{name: "b", typ: i32x4VecTyp}
+	// i32 %x
+	i32X = &local{name: "x", typ: i32Typ}
+	// i32 (i32, i32)* %f
+	fTyp, err := types.NewFunc(i32Typ, []types.Type{i32Typ, i32Typ}, false)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	fPtrTyp, err := types.NewPointer(fTyp)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	funcF = &local{name: "f", typ: fPtrTyp}
+	// i32 (i8*, ...)* %printf
+	printfTyp, err := types.NewFunc(i32Typ, []types.Type{i8PtrTyp}, true)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	printfPtrTyp, err := types.NewPointer(printfTyp)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	funcPrintf = &local{name: "printf", typ: printfPtrTyp}
+	// void ()* %g
+	gTyp, err := types.NewFunc(types.NewVoid(), nil, false)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	gPtrTyp, err := types.NewPointer(gTyp)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	funcG = &local{name: "g", typ: gPtrTyp}
+	// {i8*, i32}
+	i8Ptri32StructTyp, err = types.NewStruct([]types.Type{i8PtrTyp, i32Typ}, false)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// [1 x i8*] %filter
+	i8Ptrx1ArrTyp, err := types.NewArray(i8PtrTyp, 1)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	i8Ptrx1ArrFilter = &local{name: "filter", typ: i8Ptrx1ArrTyp}
+	// {i32, float} %s
+	i32f32StructTyp, err := types.NewStruct([]types.Type{i32Typ, f32Typ}, false)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	i32f32StructS = &local{name: "s", typ: i32f32StructTyp}
+	// {{i8, i8}, i32} %n
+	i8i8StructTyp, err := types.NewStruct([]types.Type{i8Typ, i8Typ}, false)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	i8i8i32StructTyp, err := types.NewStruct([]types.Type{i8i8StructTyp, i32Typ}, false)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	i8i8i32StructN = &local{name: "n", typ: i8i8i32StructTyp}
+	// [2 x i32] %arr
+	i32x2ArrTyp, err := types.NewArray(i32Typ, 2)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	i32x2ArrArr = &local{name: "arr", typ: i32x2ArrTyp}
+}
+
+// A local is a named local variable, which is used as a non-constant operand in
+// test cases.
+type local struct {
+	// Variable name.
+	name string
+	// Variable type.
+	typ types.Type
+}
+
+// Type returns the type of the value.
+func (v *local) Type() types.Type {
+	return v.typ
+}
+
+// Ident returns the identifier associated with the local variable.
+func (v *local) Ident() string {
+	return "%" + v.name
+}
+
+// String returns a string representation of the local variable.
+func (v *local) String() string {
+	return fmt.Sprintf("%v %s", v.typ, v.Ident())
+}
+
+func TestNewTrunc(t *testing.T) {
+	golden := []struct {
+		from values.Value
+		to   types.Type
+		err  string
+	}{
+		// i=0
+		{
+			from: i32FortyTwo, to: i8Typ,
+		},
+		// i=1
+		{
+			from: i32x2VecThreeFortyTwo, to: i8x2VecTyp,
+		},
+		// i=2
+		{
+			from: i8Three, to: i32Typ,
+			err: "invalid integer truncation; target size (32) not smaller than original size (8)",
+		},
+		// i=3
+		{
+			from: i32FortyTwo, to: i32Typ,
+			err: "invalid integer truncation; target size (32) not smaller than original size (32)",
+		},
+		// i=4
+		{
+			from: f32Three, to: i8Typ,
+			err: `invalid integer truncation; expected integer (or integer vector) for from, got "float"`,
+		},
+		// i=5
+		{
+			from: i32FortyTwo, to: f32Typ,
+			err: `invalid integer truncation; expected integer (or integer vector) target type, got "float"`,
+		},
+		// i=6
+		{
+			from: i32x2VecThreeFortyTwo, to: i8x3VecTyp,
+			err: `invalid integer truncation; cannot convert from "<2 x i32>" to "<3 x i8>"`,
+		},
+	}
+
+	for i, g := range golden {
+		inst, err := ir.NewTrunc(g.from, g.to)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		if inst.From != g.from {
+			t.Errorf("i=%d: from mismatch; expected %v, got %v", i, g.from, inst.From)
+		}
+		if !inst.To.Equal(g.to) {
+			t.Errorf("i=%d: type mismatch; expected %v, got %v", i, g.to, inst.To)
+		}
+	}
+}
+
+func TestNewZext(t *testing.T) {
+	golden := []struct {
+		from values.Value
+		to   types.Type
+		err  string
+	}{
+		// i=0
+		{
+			from: i8Three, to: i32Typ,
+		},
+		// i=1
+		{
+			from: i32FortyTwo, to: i64Typ,
+		},
+		// i=2
+		{
+			from: i32FortyTwo, to: i8Typ,
+			err: "invalid integer zero extension; target size (8) not larger than original size (32)",
+		},
+		// i=3
+		{
+			from: i32FortyTwo, to: i32Typ,
+			err: "invalid integer zero extension; target size (32) not larger than original size (32)",
+		},
+		// i=4
+		{
+			from: f32Three, to: i64Typ,
+			err: `invalid integer zero extension; expected integer (or integer vector) for from, got "float"`,
+		},
+		// i=5
+		{
+			from: i32FortyTwo, to: f32Typ,
+			err: `invalid integer zero extension; expected integer (or integer vector) target type, got "float"`,
+		},
+	}
+
+	for i, g := range golden {
+		inst, err := ir.NewZext(g.from, g.to)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		if inst.From != g.from {
+			t.Errorf("i=%d: from mismatch; expected %v, got %v", i, g.from, inst.From)
+		}
+		if !inst.To.Equal(g.to) {
+			t.Errorf("i=%d: type mismatch; expected %v, got %v", i, g.to, inst.To)
+		}
+	}
+}
+
+func TestNewSext(t *testing.T) {
+	golden := []struct {
+		from values.Value
+		to   types.Type
+		err  string
+	}{
+		// i=0
+		{
+			from: i8Three, to: i32Typ,
+		},
+		// i=1
+		{
+			from: i32FortyTwo, to: i64Typ,
+		},
+		// i=2
+		{
+			from: i32FortyTwo, to: i8Typ,
+			err: "invalid integer sign extension; target size (8) not larger than original size (32)",
+		},
+		// i=3
+		{
+			from: i32FortyTwo, to: i32Typ,
+			err: "invalid integer sign extension; target size (32) not larger than original size (32)",
+		},
+		// i=4
+		{
+			from: f32Three, to: i64Typ,
+			err: `invalid integer sign extension; expected integer (or integer vector) for from, got "float"`,
+		},
+		// i=5
+		{
+			from: i32FortyTwo, to: f32Typ,
+			err: `invalid integer sign extension; expected integer (or integer vector) target type, got "float"`,
+		},
+	}
+
+	for i, g := range golden {
+		inst, err := ir.NewSext(g.from, g.to)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		if inst.From != g.from {
+			t.Errorf("i=%d: from mismatch; expected %v, got %v", i, g.from, inst.From)
+		}
+		if !inst.To.Equal(g.to) {
+			t.Errorf("i=%d: type mismatch; expected %v, got %v", i, g.to, inst.To)
+		}
+	}
+}
+
+func TestNewFptrunc(t *testing.T) {
+	golden := []struct {
+		from values.Value
+		to   types.Type
+		err  string
+	}{
+		// i=0
+		{
+			from: f64Three, to: f32Typ,
+		},
+		// i=1
+		{
+			from: f32Three, to: f64Typ,
+			err: "invalid floating point truncation; target size (64) not smaller than original size (32)",
+		},
+		// i=2
+		{
+			from: f32Three, to: f32Typ,
+			err: "invalid floating point truncation; target size (32) not smaller than original size (32)",
+		},
+		// i=3
+		{
+			from: i32FortyTwo, to: f32Typ,
+			err: `invalid floating point truncation; expected floating point (or floating point vector) for from, got "i32"`,
+		},
+		// i=4
+		{
+			from: f32Three, to: i32Typ,
+			err: `invalid floating point truncation; expected floating point (or floating point vector) target type, got "i32"`,
+		},
+	}
+
+	for i, g := range golden {
+		inst, err := ir.NewFptrunc(g.from, g.to)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		if inst.From != g.from {
+			t.Errorf("i=%d: from mismatch; expected %v, got %v", i, g.from, inst.From)
+		}
+		if !inst.To.Equal(g.to) {
+			t.Errorf("i=%d: type mismatch; expected %v, got %v", i, g.to, inst.To)
+		}
+	}
+}
+
+func TestNewFpext(t *testing.T) {
+	golden := []struct {
+		from values.Value
+		to   types.Type
+		err  string
+	}{
+		// i=0
+		{
+			from: f32Three, to: f64Typ,
+		},
+		// i=1
+		{
+			from: f64Three, to: f32Typ,
+			err: "invalid floating point extension; target size (32) not larger than original size (64)",
+		},
+		// i=2
+		{
+			from: f32Three, to: f32Typ,
+			err: "invalid floating point extension; target size (32) not larger than original size (32)",
+		},
+		// i=3
+		{
+			from: i32FortyTwo, to: f32Typ,
+			err: `invalid floating point extension; expected floating point (or floating point vector) for from, got "i32"`,
+		},
+		// i=4
+		{
+			from: f32Three, to: i32Typ,
+			err: `invalid floating point extension; expected floating point (or floating point vector) target type, got "i32"`,
+		},
+	}
+
+	for i, g := range golden {
+		inst, err := ir.NewFpext(g.from, g.to)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		if inst.From != g.from {
+			t.Errorf("i=%d: from mismatch; expected %v, got %v", i, g.from, inst.From)
+		}
+		if !inst.To.Equal(g.to) {
+			t.Errorf("i=%d: type mismatch; expected %v, got %v", i, g.to, inst.To)
+		}
+	}
+}
+
+func TestNewFptoui(t *testing.T) {
+	golden := []struct {
+		from values.Value
+		to   types.Type
+		err  string
+	}{
+		// i=0
+		{
+			from: f32Three, to: i32Typ,
+		},
+		// i=1
+		{
+			from: f32x2VecThreeThree, to: i32x2VecTyp,
+		},
+		// i=2
+		{
+			from: i32FortyTwo, to: i32Typ,
+			err: `invalid floating point to unsigned integer conversion; expected floating point (or floating point vector) for from, got "i32"`,
+		},
+		// i=3
+		{
+			from: f32Three, to: f64Typ,
+			err: `invalid floating point to unsigned integer conversion; expected integer (or integer vector) target type, got "double"`,
+		},
+		// i=4
+		{
+			from: f32x2VecThreeThree, to: i8x3VecTyp,
+			err: `invalid floating point to unsigned integer conversion; cannot convert from "<2 x float>" to "<3 x i8>"`,
+		},
+		// i=5
+		{
+			from: f32x2VecThreeThree, to: i32Typ,
+			err: `invalid floating point to unsigned integer conversion; cannot convert from "<2 x float>" to "i32"`,
+		},
+	}
+
+	for i, g := range golden {
+		inst, err := ir.NewFptoui(g.from, g.to)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		if inst.From != g.from {
+			t.Errorf("i=%d: from mismatch; expected %v, got %v", i, g.from, inst.From)
+		}
+		if !inst.To.Equal(g.to) {
+			t.Errorf("i=%d: type mismatch; expected %v, got %v", i, g.to, inst.To)
+		}
+	}
+}
+
+func TestNewFptosi(t *testing.T) {
+	golden := []struct {
+		from values.Value
+		to   types.Type
+		err  string
+	}{
+		// i=0
+		{
+			from: f32Three, to: i32Typ,
+		},
+		// i=1
+		{
+			from: f32x2VecThreeThree, to: i32x2VecTyp,
+		},
+		// i=2
+		{
+			from: i32FortyTwo, to: i32Typ,
+			err: `invalid floating point to signed integer conversion; expected floating point (or floating point vector) for from, got "i32"`,
+		},
+		// i=3
+		{
+			from: f32Three, to: f64Typ,
+			err: `invalid floating point to signed integer conversion; expected integer (or integer vector) target type, got "double"`,
+		},
+		// i=4
+		{
+			from: f32x2VecThreeThree, to: i8x3VecTyp,
+			err: `invalid floating point to signed integer conversion; cannot convert from "<2 x float>" to "<3 x i8>"`,
+		},
+		// i=5
+		{
+			from: f32x2VecThreeThree, to: i32Typ,
+			err: `invalid floating point to signed integer conversion; cannot convert from "<2 x float>" to "i32"`,
+		},
+	}
+
+	for i, g := range golden {
+		inst, err := ir.NewFptosi(g.from, g.to)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		if inst.From != g.from {
+			t.Errorf("i=%d: from mismatch; expected %v, got %v", i, g.from, inst.From)
+		}
+		if !inst.To.Equal(g.to) {
+			t.Errorf("i=%d: type mismatch; expected %v, got %v", i, g.to, inst.To)
+		}
+	}
+}
+
+func TestNewUitofp(t *testing.T) {
+	golden := []struct {
+		from values.Value
+		to   types.Type
+		err  string
+	}{
+		// i=0
+		{
+			from: i32FortyTwo, to: f32Typ,
+		},
+		// i=1
+		{
+			from: i32x2VecThreeFortyTwo, to: f32x2VecTyp,
+		},
+		// i=2
+		{
+			from: f32Three, to: f64Typ,
+			err: `invalid unsigned integer to floating point conversion; expected integer (or integer vector) for from, got "float"`,
+		},
+		// i=3
+		{
+			from: i32FortyTwo, to: i64Typ,
+			err: `invalid unsigned integer to floating point conversion; expected floating point (or floating point vector) target type, got "i64"`,
+		},
+		// i=4
+		{
+			from: i32x2VecThreeFortyTwo, to: f32Typ,
+			err: `invalid unsigned integer to floating point conversion; cannot convert from "<2 x i32>" to "float"`,
+		},
+	}
+
+	for i, g := range golden {
+		inst, err := ir.NewUitofp(g.from, g.to)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		if inst.From != g.from {
+			t.Errorf("i=%d: from mismatch; expected %v, got %v", i, g.from, inst.From)
+		}
+		if !inst.To.Equal(g.to) {
+			t.Errorf("i=%d: type mismatch; expected %v, got %v", i, g.to, inst.To)
+		}
+	}
+}
+
+func TestNewSitofp(t *testing.T) {
+	golden := []struct {
+		from values.Value
+		to   types.Type
+		err  string
+	}{
+		// i=0
+		{
+			from: i32FortyTwo, to: f32Typ,
+		},
+		// i=1
+		{
+			from: i32x2VecThreeFortyTwo, to: f32x2VecTyp,
+		},
+		// i=2
+		{
+			from: f32Three, to: f64Typ,
+			err: `invalid signed integer to floating point conversion; expected integer (or integer vector) for from, got "float"`,
+		},
+		// i=3
+		{
+			from: i32FortyTwo, to: i64Typ,
+			err: `invalid signed integer to floating point conversion; expected floating point (or floating point vector) target type, got "i64"`,
+		},
+		// i=4
+		{
+			from: i32x2VecThreeFortyTwo, to: f32Typ,
+			err: `invalid signed integer to floating point conversion; cannot convert from "<2 x i32>" to "float"`,
+		},
+	}
+
+	for i, g := range golden {
+		inst, err := ir.NewSitofp(g.from, g.to)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		if inst.From != g.from {
+			t.Errorf("i=%d: from mismatch; expected %v, got %v", i, g.from, inst.From)
+		}
+		if !inst.To.Equal(g.to) {
+			t.Errorf("i=%d: type mismatch; expected %v, got %v", i, g.to, inst.To)
+		}
+	}
+}
+
+func TestNewPtrtoint(t *testing.T) {
+	golden := []struct {
+		from values.Value
+		to   types.Type
+		err  string
+	}{
+		// i=0
+		{
+			from: i8PtrP, to: i64Typ,
+		},
+		// i=1
+		{
+			from: f32Three, to: i64Typ,
+			err: `invalid pointer to integer conversion; expected pointer (or pointer vector) for from, got "float"`,
+		},
+		// i=2
+		{
+			from: i8PtrP, to: f32Typ,
+			err: `invalid pointer to integer conversion; expected integer (or integer vector) target type, got "float"`,
+		},
+	}
+
+	for i, g := range golden {
+		inst, err := ir.NewPtrtoint(g.from, g.to)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		if inst.From != g.from {
+			t.Errorf("i=%d: from mismatch; expected %v, got %v", i, g.from, inst.From)
+		}
+		if !inst.To.Equal(g.to) {
+			t.Errorf("i=%d: type mismatch; expected %v, got %v", i, g.to, inst.To)
+		}
+	}
+}
+
+func TestNewInttoptr(t *testing.T) {
+	golden := []struct {
+		from values.Value
+		to   types.Type
+		err  string
+	}{
+		// i=0
+		{
+			from: i64FortyTwo, to: i8PtrTyp,
+		},
+		// i=1
+		{
+			from: f32Three, to: i8PtrTyp,
+			err: `invalid integer to pointer conversion; expected integer (or integer vector) for from, got "float"`,
+		},
+		// i=2
+		{
+			from: i64FortyTwo, to: i64Typ,
+			err: `invalid integer to pointer conversion; expected pointer (or pointer vector) target type, got "i64"`,
+		},
+	}
+
+	for i, g := range golden {
+		inst, err := ir.NewInttoptr(g.from, g.to)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		if inst.From != g.from {
+			t.Errorf("i=%d: from mismatch; expected %v, got %v", i, g.from, inst.From)
+		}
+		if !inst.To.Equal(g.to) {
+			t.Errorf("i=%d: type mismatch; expected %v, got %v", i, g.to, inst.To)
+		}
+	}
+}
+
+func TestNewBitcast(t *testing.T) {
+	golden := []struct {
+		from values.Value
+		to   types.Type
+		err  string
+	}{
+		// i=0
+		{
+			from: f32Three, to: i32Typ,
+		},
+		// i=1
+		{
+			from: i32FortyTwo, to: f32Typ,
+		},
+		// i=2
+		{
+			from: i8PtrP, to: i32PtrTyp,
+		},
+		// i=3
+		{
+			from: i32PtrQ, to: i8PtrTyp,
+		},
+		// i=4
+		{
+			from: i32x2VecThreeFortyTwo, to: i64Typ,
+		},
+		// i=5
+		{
+			from: i64FortyTwo, to: f32Typ,
+			err: "invalid bitcast; target size (32) not equal to original size (64)",
+		},
+		// i=6
+		{
+			from: i8PtrP, to: i64Typ,
+			err: `invalid bitcast; cannot convert from "i8*" to "i64"`,
+		},
+		// i=7
+		{
+			from: i8Ptr1R, to: i8PtrTyp,
+			err: `invalid bitcast; cannot convert between address spaces of "i8 addrspace(1)*" and "i8*"; use addrspacecast instead`,
+		},
+	}
+
+	for i, g := range golden {
+		inst, err := ir.NewBitcast(g.from, g.to)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		if inst.From != g.from {
+			t.Errorf("i=%d: from mismatch; expected %v, got %v", i, g.from, inst.From)
+		}
+		if !inst.To.Equal(g.to) {
+			t.Errorf("i=%d: type mismatch; expected %v, got %v", i, g.to, inst.To)
+		}
+	}
+}
+
+func TestNewAddrspacecast(t *testing.T) {
+	golden := []struct {
+		from values.Value
+		to   types.Type
+		err  string
+	}{
+		// i=0
+		{
+			from: i8Ptr1R, to: i8PtrTyp,
+		},
+		// i=1
+		{
+			from: i8PtrP, to: i8Ptr1Typ,
+		},
+		// i=2
+		{
+			from: i8PtrP, to: i32PtrTyp,
+			err: `invalid address space conversion; "i8*" and "i32*" share address space; use bitcast instead`,
+		},
+		// i=3
+		{
+			from: i64FortyTwo, to: i8PtrTyp,
+			err: `invalid address space conversion; expected pointer (or pointer vector) for from, got "i64"`,
+		},
+		// i=4
+		{
+			from: i8Ptr1R, to: i64Typ,
+			err: `invalid address space conversion; expected pointer (or pointer vector) target type, got "i64"`,
+		},
+	}
+
+	for i, g := range golden {
+		inst, err := ir.NewAddrspacecast(g.from, g.to)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		if inst.From != g.from {
+			t.Errorf("i=%d: from mismatch; expected %v, got %v", i, g.from, inst.From)
+		}
+		if !inst.To.Equal(g.to) {
+			t.Errorf("i=%d: type mismatch; expected %v, got %v", i, g.to, inst.To)
+		}
+	}
+}
+
+func TestNewSelect(t *testing.T) {
+	golden := []struct {
+		cond, trueValue, falseValue values.Value
+		err                         string
+	}{
+		// i=0
+		{
+			cond: i1Cond, trueValue: i32FortyTwo, falseValue: i32FortyTwo,
+		},
+		// i=1
+		{
+			cond: i1Cond, trueValue: i32x4VecA, falseValue: i32x4VecB,
+		},
+		// i=2
+		{
+			cond: i1x4VecConds, trueValue: i32x4VecA, falseValue: i32x4VecB,
+		},
+		// i=3
+		{
+			cond: i32FortyTwo, trueValue: i32FortyTwo, falseValue: i32FortyTwo,
+			err: `invalid select condition; expected i1 (or vector of i1), got "i32"`,
+		},
+		// i=4
+		{
+			cond: i1Cond, trueValue: i32FortyTwo, falseValue: i64FortyTwo,
+			err: `invalid select operands; type mismatch between "i32" and "i64"`,
+		},
+		// i=5
+		{
+			cond: i1x4VecConds, trueValue: i32FortyTwo, falseValue: i32FortyTwo,
+			err: `invalid select operands; vector condition "<4 x i1>" requires operands of the same length, got "i32"`,
+		},
+		// i=6
+		{
+			cond: i1x4VecConds, trueValue: i32x2VecThreeFortyTwo, falseValue: i32x2VecThreeFortyTwo,
+			err: `invalid select operands; vector condition "<4 x i1>" requires operands of the same length, got "<2 x i32>"`,
+		},
+	}
+
+	for i, g := range golden {
+		inst, err := ir.NewSelect(g.cond, g.trueValue, g.falseValue)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		if !inst.Type.Equal(g.trueValue.Type()) {
+			t.Errorf("i=%d: type mismatch; expected %v, got %v", i, g.trueValue.Type(), inst.Type)
+		}
+	}
+}
+
+func TestNewCall(t *testing.T) {
+	golden := []struct {
+		callee values.Value
+		args   []values.Value
+		err    string
+	}{
+		// i=0
+		{
+			callee: funcF, args: []values.Value{i32FortyTwo, i32FortyTwo},
+		},
+		// i=1
+		{
+			callee: funcPrintf, args: []values.Value{i8PtrP},
+		},
+		// i=2
+		{
+			callee: funcPrintf, args: []values.Value{i8PtrP, i32FortyTwo, f64Three},
+		},
+		// i=3
+		{
+			callee: funcF, args: []values.Value{i32FortyTwo, f32Three},
+			err: `invalid function argument 1; expected "i32", got "float"`,
+		},
+		// i=4
+		{
+			callee: funcF, args: []values.Value{i32FortyTwo},
+			err: "invalid number of function arguments; expected 2, got 1",
+		},
+		// i=5
+		{
+			callee: funcF, args: []values.Value{i32FortyTwo, i32FortyTwo, i32FortyTwo},
+			err: "invalid number of function arguments; expected 2, got 3",
+		},
+		// i=6
+		{
+			callee: funcPrintf, args: nil,
+			err: "invalid number of function arguments; expected 1, got 0",
+		},
+		// i=7
+		{
+			callee: i8PtrP, args: nil,
+			err: `invalid callee type; expected function (or pointer to function), got "i8*"`,
+		},
+	}
+
+	for i, g := range golden {
+		inst, err := ir.NewCall(g.callee, g.args)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		if !inst.Type.Equal(i32Typ) {
+			t.Errorf("i=%d: type mismatch; expected %v, got %v", i, i32Typ, inst.Type)
+		}
+	}
+}
+
+func TestCallString(t *testing.T) {
+	golden := []struct {
+		callee values.Value
+		args   []values.Value
+		want   string
+	}{
+		// i=0
+		{
+			callee: funcF, args: []values.Value{i32X, i32FortyTwo},
+			want: "call i32 %f(i32 %x, i32 42)",
+		},
+		// i=1
+		{
+			callee: funcPrintf, args: []values.Value{i8PtrP},
+			want: "call i32 (i8*, ...) %printf(i8* %p)",
+		},
+		// i=2
+		{
+			callee: funcPrintf, args: []values.Value{i8PtrP, i32FortyTwo, f64Three},
+			want: "call i32 (i8*, ...) %printf(i8* %p, i32 42, double 3.0)",
+		},
+	}
+
+	for i, g := range golden {
+		inst, err := ir.NewCall(g.callee, g.args)
+		if err != nil {
+			t.Errorf("i=%d: %v", i, err)
+			continue
+		}
+		got := inst.String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %q, got %q", i, g.want, got)
+		}
+	}
+}
+
+func TestNewLandingpad(t *testing.T) {
+	golden := []struct {
+		cleanup bool
+		clauses []ir.LandingpadClause
+		want    string
+		err     string
+	}{
+		// i=0
+		{
+			clauses: []ir.LandingpadClause{{Val: i8PtrP}},
+			want:    "%lp = landingpad {i8*, i32} catch i8* %p",
+		},
+		// i=1
+		{
+			cleanup: true,
+			want:    "%lp = landingpad {i8*, i32} cleanup",
+		},
+		// i=2
+		{
+			cleanup: true,
+			clauses: []ir.LandingpadClause{{Val: i8PtrP}, {Filter: true, Val: i8Ptrx1ArrFilter}},
+			want:    "%lp = landingpad {i8*, i32} cleanup catch i8* %p filter [1 x i8*] %filter",
+		},
+		// i=3
+		{
+			err: "invalid landingpad; non-cleanup landingpad requires at least one clause",
+		},
+		// i=4
+		{
+			clauses: []ir.LandingpadClause{{Filter: true, Val: i8PtrP}},
+			err:     `invalid landingpad filter clause 0; expected array type, got "i8*"`,
+		},
+	}
+
+	for i, g := range golden {
+		inst, err := ir.NewLandingpad(i8Ptri32StructTyp, g.cleanup, g.clauses)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		if inst.Cleanup != g.cleanup {
+			t.Errorf("i=%d: cleanup mismatch; expected %v, got %v", i, g.cleanup, inst.Cleanup)
+		}
+		if len(inst.Clauses) != len(g.clauses) {
+			t.Errorf("i=%d: clause count mismatch; expected %d, got %d", i, len(g.clauses), len(inst.Clauses))
+		}
+		inst.Name = "lp"
+		if got := inst.String(); got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %q, got %q", i, g.want, got)
+		}
+
+		// Resume the exception caught by the landing pad.
+		var term ir.Terminator = &ir.ResumeInst{Val: &local{name: "exn", typ: inst.Type}}
+		if got := term.(*ir.ResumeInst).Val.Type(); !got.Equal(i8Ptri32StructTyp) {
+			t.Errorf("i=%d: resume type mismatch; expected %v, got %v", i, i8Ptri32StructTyp, got)
+		}
+	}
+}
+
+func TestNewExtractvalue(t *testing.T) {
+	golden := []struct {
+		aggregate values.Value
+		indices   []int
+		want      types.Type
+		err       string
+	}{
+		// i=0
+		{
+			aggregate: i32f32StructS, indices: []int{1},
+			want: f32Typ,
+		},
+		// i=1
+		{
+			aggregate: i8i8i32StructN, indices: []int{0, 1},
+			want: i8Typ,
+		},
+		// i=2
+		{
+			aggregate: i32x2ArrArr, indices: []int{1},
+			want: i32Typ,
+		},
+		// i=3
+		{
+			aggregate: i32f32StructS, indices: []int{2},
+			err: `invalid extractvalue; index (2) out of range for "{i32, float}"`,
+		},
+		// i=4
+		{
+			aggregate: i32x2ArrArr, indices: []int{-1},
+			err: `invalid extractvalue; index (-1) out of range for "[2 x i32]"`,
+		},
+		// i=5
+		{
+			aggregate: i32f32StructS, indices: []int{0, 0},
+			err: `invalid extractvalue; unable to index into non-aggregate type "i32"`,
+		},
+		// i=6
+		{
+			aggregate: i32f32StructS, indices: nil,
+			err: "invalid extractvalue; expected at least one index",
+		},
+	}
+
+	for i, g := range golden {
+		inst, err := ir.NewExtractvalue(g.aggregate, g.indices)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		if !inst.Type.Equal(g.want) {
+			t.Errorf("i=%d: type mismatch; expected %v, got %v", i, g.want, inst.Type)
+		}
+	}
+}
+
+func TestNewInsertvalue(t *testing.T) {
+	golden := []struct {
+		aggregate values.Value
+		elem      values.Value
+		indices   []int
+		err       string
+	}{
+		// i=0
+		{
+			aggregate: i8i8i32StructN, elem: i8Three, indices: []int{0, 1},
+		},
+		// i=1
+		{
+			aggregate: i8i8i32StructN, elem: i32FortyTwo, indices: []int{1},
+		},
+		// i=2
+		{
+			aggregate: i8i8i32StructN, elem: i32FortyTwo, indices: []int{0, 1},
+			err: `invalid insertvalue; element type mismatch; expected "i8", got "i32"`,
+		},
+		// i=3
+		{
+			aggregate: i8i8i32StructN, elem: i8Three, indices: []int{0, 2},
+			err: `invalid insertvalue; index (2) out of range for "{i8, i8}"`,
+		},
+	}
+
+	for i, g := range golden {
+		inst, err := ir.NewInsertvalue(g.aggregate, g.elem, g.indices)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		if inst.Element != g.elem {
+			t.Errorf("i=%d: element mismatch; expected %v, got %v", i, g.elem, inst.Element)
+		}
+	}
+}
+
+func TestNewFence(t *testing.T) {
+	golden := []struct {
+		ordering  ir.AtomicOrdering
+		syncScope string
+		err       string
+	}{
+		// i=0
+		{
+			ordering: ir.AtomicSeqCst,
+		},
+		// i=1
+		{
+			ordering: ir.AtomicRelease, syncScope: "singlethread",
+		},
+		// i=2
+		{
+			ordering: ir.AtomicAcquire,
+		},
+		// i=3
+		{
+			ordering: ir.AtomicAcqRel,
+		},
+		// i=4
+		{
+			ordering: ir.AtomicMonotonic,
+			err:      "invalid fence ordering (monotonic); expected acquire, release, acq_rel or seq_cst",
+		},
+		// i=5
+		{
+			ordering: ir.AtomicUnordered,
+			err:      "invalid fence ordering (unordered); expected acquire, release, acq_rel or seq_cst",
+		},
+		// i=6
+		{
+			ordering: ir.AtomicNone,
+			err:      "invalid fence ordering (none); expected acquire, release, acq_rel or seq_cst",
+		},
+	}
+
+	for i, g := range golden {
+		inst, err := ir.NewFence(g.ordering, g.syncScope)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		if inst.Ordering != g.ordering {
+			t.Errorf("i=%d: ordering mismatch; expected %v, got %v", i, g.ordering, inst.Ordering)
+		}
+		if inst.SyncScope != g.syncScope {
+			t.Errorf("i=%d: sync scope mismatch; expected %q, got %q", i, g.syncScope, inst.SyncScope)
+		}
+	}
+}
+
+func TestReturnString(t *testing.T) {
+	golden := []struct {
+		val  values.Value
+		typ  types.Type
+		want string
+	}{
+		// i=0
+		{
+			val: nil, typ: types.NewVoid(),
+			want: "ret void",
+		},
+		// i=1
+		{
+			val: i32X, typ: i32Typ,
+			want: "ret i32 %x",
+		},
+		// i=2
+		{
+			val: i32FortyTwo, typ: i32Typ,
+			want: "ret i32 42",
+		},
+	}
+
+	for i, g := range golden {
+		term := ir.NewRet(g.val)
+		if !term.Type.Equal(g.typ) {
+			t.Errorf("i=%d: type mismatch; expected %v, got %v", i, g.typ, term.Type)
+		}
+		got := term.String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %v, got %v", i, g.want, got)
+		}
+	}
+}
+
+func TestBranchString(t *testing.T) {
+	golden := []struct {
+		target *ir.BasicBlock
+		want   string
+	}{
+		// i=0
+		{
+			target: &ir.BasicBlock{Name: "x"},
+			want:   "br label %x",
+		},
+		// i=1
+		{
+			target: &ir.BasicBlock{Name: "0"},
+			want:   "br label %0",
+		},
+	}
+
+	for i, g := range golden {
+		term := ir.NewBr(g.target)
+		if term.Target != g.target {
+			t.Errorf("i=%d: target mismatch; expected %v, got %v", i, g.target.Name, term.Target.Name)
+		}
+		got := term.String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %v, got %v", i, g.want, got)
+		}
+	}
+}
+
+func TestCondBranchString(t *testing.T) {
+	trueBranch, falseBranch := &ir.BasicBlock{Name: "t"}, &ir.BasicBlock{Name: "f"}
+	golden := []struct {
+		cond values.Value
+		want string
+		err  string
+	}{
+		// i=0
+		{
+			cond: &local{name: "c", typ: i1Typ},
+			want: "br i1 %c, label %t, label %f",
+		},
+		// i=1
+		{
+			cond: i32X,
+			err:  `invalid branching condition; expected i1, got "i32"`,
+		},
+		// i=2
+		{
+			cond: i1x4VecConds,
+			err:  `invalid branching condition; expected i1, got "<4 x i1>"`,
+		},
+	}
+
+	for i, g := range golden {
+		term, err := ir.NewCondBr(g.cond, trueBranch, falseBranch)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		got := term.String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %v, got %v", i, g.want, got)
+		}
+	}
+}
+
+func TestSwitchString(t *testing.T) {
+	def, a, b := &ir.BasicBlock{Name: "def"}, &ir.BasicBlock{Name: "a"}, &ir.BasicBlock{Name: "b"}
+	i32Zero, err := consts.NewInt(i32Typ, "0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	i32One, err := consts.NewInt(i32Typ, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := []struct {
+		val   values.Value
+		cases []ir.SwitchCase
+		want  string
+		err   string
+	}{
+		// i=0
+		{
+			val:   i32X,
+			cases: []ir.SwitchCase{{Val: i32Zero, Target: a}, {Val: i32One, Target: b}},
+			want:  "switch i32 %x, label %def [ i32 0, label %a i32 1, label %b ]",
+		},
+		// i=1
+		{
+			val:  i32X,
+			want: "switch i32 %x, label %def [ ]",
+		},
+		// i=2
+		{
+			val:   i32X,
+			cases: []ir.SwitchCase{{Val: i32Zero, Target: a}, {Val: i32Zero, Target: b}},
+			err:   `invalid switch case "i32 0"; duplicate case value`,
+		},
+		// i=3
+		{
+			val:   i32X,
+			cases: []ir.SwitchCase{{Val: i8Three, Target: a}},
+			err:   `invalid switch case "i8 3"; type mismatch; expected "i32", got "i8"`,
+		},
+		// i=4
+		{
+			val: f32Three,
+			err: `invalid switch comparison value; expected integer, got "float"`,
+		},
+	}
+
+	for i, g := range golden {
+		term, err := ir.NewSwitch(g.val, def, g.cases)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		got := term.String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %v, got %v", i, g.want, got)
+		}
+	}
+}
+
+func TestIndirectbrString(t *testing.T) {
+	a, b := &ir.BasicBlock{Name: "a"}, &ir.BasicBlock{Name: "b"}
+	golden := []struct {
+		addr    values.Value
+		targets []*ir.BasicBlock
+		want    string
+		err     string
+	}{
+		// i=0
+		{
+			addr: i8PtrP, targets: []*ir.BasicBlock{a, b},
+			want: "indirectbr i8* %p, [label %a, label %b]",
+		},
+		// i=1
+		{
+			addr: i8PtrP, targets: nil,
+			err: "invalid indirectbr; expected at least one target",
+		},
+		// i=2
+		{
+			addr: i64FortyTwo, targets: []*ir.BasicBlock{a},
+			err: `invalid indirectbr address; expected pointer, got "i64"`,
+		},
+	}
+
+	for i, g := range golden {
+		term, err := ir.NewIndirectbr(g.addr, g.targets)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		got := term.String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %v, got %v", i, g.want, got)
+		}
+	}
+}
+
+func TestBlockAddress(t *testing.T) {
+	sig, err := types.NewFunc(types.NewVoid(), nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := &ir.BasicBlock{Name: "a"}, &ir.BasicBlock{Name: "b"}
+	f := &ir.Function{Name: "f", Sig: sig}
+	f.AppendBlock(a)
+	f.AppendBlock(b)
+
+	addr, err := ir.NewBlockAddress(f, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !addr.Type().Equal(i8PtrTyp) {
+		t.Errorf("type mismatch; expected %v, got %v", i8PtrTyp, addr.Type())
+	}
+	if got, want := addr.Ident(), "blockaddress(@f, %b)"; got != want {
+		t.Errorf("ident mismatch; expected %q, got %q", want, got)
+	}
+	term, err := ir.NewIndirectbr(addr, []*ir.BasicBlock{a, b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := term.String(), "indirectbr i8* blockaddress(@f, %b), [label %a, label %b]"; got != want {
+		t.Errorf("string mismatch; expected %q, got %q", want, got)
+	}
+
+	const want = "invalid blockaddress; basic block %c does not belong to function @f"
+	if _, err := ir.NewBlockAddress(f, &ir.BasicBlock{Name: "c"}); !sameError(err, want) {
+		t.Errorf("error mismatch; expected %v, got %v", want, err)
+	}
+}
+
+func TestUnreachableString(t *testing.T) {
+	var term ir.Terminator = &ir.UnreachableInst{}
+	const want = "unreachable"
+	got := term.(fmt.Stringer).String()
+	if got != want {
+		t.Errorf("string mismatch; expected %v, got %v", want, got)
+	}
+}
+
+func TestResumeString(t *testing.T) {
+	lp, err := ir.NewLandingpad(i8Ptri32StructTyp, true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lp.Name = "lp"
+	block := &ir.BasicBlock{Name: "unwind"}
+	block.AppendInst(lp)
+	block.SetTerm(&ir.ResumeInst{Val: values.NewLocal("lp", i8Ptri32StructTyp)})
+
+	golden := []struct {
+		v    fmt.Stringer
+		want string
+	}{
+		// i=0
+		{
+			v:    block.Term.(fmt.Stringer),
+			want: "resume {i8*, i32} %lp",
+		},
+		// i=1
+		{
+			v:    block,
+			want: "unwind:\n  %lp = landingpad {i8*, i32} cleanup\n  resume {i8*, i32} %lp\n",
+		},
+	}
+
+	for i, g := range golden {
+		got := g.v.String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %q, got %q", i, g.want, got)
+		}
+	}
+}
+
+func TestNewInvoke(t *testing.T) {
+	normal, unwind := &ir.BasicBlock{Name: "normal"}, &ir.BasicBlock{Name: "unwind"}
+	golden := []struct {
+		callee         values.Value
+		args           []values.Value
+		normal, unwind *ir.BasicBlock
+		want           types.Type
+		err            string
+	}{
+		// i=0
+		{
+			callee: funcG, normal: normal, unwind: unwind,
+			want: types.NewVoid(),
+		},
+		// i=1
+		{
+			callee: funcF, args: []values.Value{i32FortyTwo, i32X}, normal: normal, unwind: unwind,
+			want: i32Typ,
+		},
+		// i=2
+		{
+			callee: funcG, args: []values.Value{i32X}, normal: normal, unwind: unwind,
+			err: "invalid number of function arguments; expected 0, got 1",
+		},
+		// i=3
+		{
+			callee: funcG, unwind: unwind,
+			err: "invalid invoke; missing normal destination",
+		},
+		// i=4
+		{
+			callee: funcG, normal: normal,
+			err: "invalid invoke; missing unwind destination",
+		},
+	}
+
+	for i, g := range golden {
+		term, err := ir.NewInvoke(g.callee, g.args, g.normal, g.unwind)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		if !term.Type.Equal(g.want) {
+			t.Errorf("i=%d: type mismatch; expected %v, got %v", i, g.want, term.Type)
+		}
+		if term.Normal != g.normal || term.Unwind != g.unwind {
+			t.Errorf("i=%d: destination mismatch", i)
+		}
+	}
+}
+
+func TestInvokeString(t *testing.T) {
+	sig, err := types.NewFunc(i32Typ, []types.Type{i32Typ}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	callee := &ir.Function{Name: "g", Sig: sig}
+	normal, unwind := &ir.BasicBlock{Name: "normal"}, &ir.BasicBlock{Name: "unwind"}
+	invoke, err := ir.NewInvoke(callee, []values.Value{i32FortyTwo}, normal, unwind)
+	if err != nil {
+		t.Fatal(err)
+	}
+	invoke.Name = "y"
+	y := values.NewLocal("y", i32Typ)
+	entry := &ir.BasicBlock{Name: "entry"}
+	entry.SetTerm(invoke)
+	normal.SetTerm(ir.NewRet(y))
+	unwind.SetTerm(&ir.UnreachableInst{})
+	f := &ir.Function{Name: "f", Sig: sig, Params: []*values.Param{values.NewParam("x", i32Typ)}}
+	f.AppendBlock(entry)
+	f.AppendBlock(normal)
+	f.AppendBlock(unwind)
+
+	golden := []struct {
+		v    fmt.Stringer
+		want string
+	}{
+		// i=0
+		{
+			v:    invoke,
+			want: "%y = invoke i32 @g(i32 42) to label %normal unwind label %unwind",
+		},
+		// i=1
+		{
+			v:    &ir.InvokeInst{Type: types.NewVoid(), Callee: funcG, Normal: normal, Unwind: unwind},
+			want: "invoke void %g() to label %normal unwind label %unwind",
+		},
+		// i=2
+		{
+			v:    &ir.InvokeInst{Type: i32Typ, Callee: funcPrintf, Args: []values.Value{i8PtrP}, Normal: normal, Unwind: unwind},
+			want: "invoke i32 (i8*, ...) %printf(i8* %p) to label %normal unwind label %unwind",
+		},
+		// i=3
+		{
+			v:    f,
+			want: "define i32 @f(i32 %x) {\nentry:\n  %y = invoke i32 @g(i32 42) to label %normal unwind label %unwind\n\nnormal:\n  ret i32 %y\n\nunwind:\n  unreachable\n}",
+		},
+	}
+
+	for i, g := range golden {
+		got := g.v.String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %q, got %q", i, g.want, got)
+		}
+	}
+}
+
+func TestNewLocal(t *testing.T) {
+	// Chain instructions by referencing the result of a previous instruction.
+	//    %tmp = add i32 %x, 42
+	//    %y = mul i32 %tmp, %tmp
+	add := &ir.AddInst{LocalIdent: ir.LocalIdent{Name: "tmp"}, Type: i32Typ, Op1: i32X, Op2: i32FortyTwo}
+	tmp, err := ir.NewLocal(add, i32Typ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !tmp.Type().Equal(i32Typ) {
+		t.Errorf("type mismatch; expected %v, got %v", i32Typ, tmp.Type())
+	}
+	mul := &ir.MulInst{LocalIdent: ir.LocalIdent{Name: "y"}, Type: i32Typ, Op1: tmp, Op2: tmp}
+	if got, want := mul.String(), "%y = mul i32 %tmp, %tmp"; got != want {
+		t.Errorf("string mismatch; expected %q, got %q", want, got)
+	}
+
+	golden := []struct {
+		inst ir.Instruction
+		err  string
+	}{
+		// i=0
+		{
+			inst: &ir.AddInst{Type: i32Typ, Op1: i32X, Op2: i32FortyTwo},
+			err:  "invalid local reference; unnamed result of instruction *ir.AddInst",
+		},
+		// i=1
+		{
+			inst: &ir.StoreInst{Type: i32Typ, Val: i32X, Addr: i32PtrQ},
+			err:  "invalid local reference; instruction *ir.StoreInst does not produce a value",
+		},
+		// i=2
+		{
+			inst: &ir.CallInst{LocalIdent: ir.LocalIdent{Name: "r"}, Type: types.NewVoid(), Callee: funcG},
+			err:  "invalid local reference; instruction *ir.CallInst does not produce a value",
+		},
+	}
+
+	for i, g := range golden {
+		_, err := ir.NewLocal(g.inst, i32Typ)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+		}
+	}
+}
+
+func TestNewAlloca(t *testing.T) {
+	golden := []struct {
+		typ      types.Type
+		numElems int
+		want     string
+		err      string
+	}{
+		// i=0
+		{
+			typ: i32Typ, numElems: 1,
+			want: "alloca i32, align 4",
+		},
+		// i=1
+		{
+			typ: i64Typ, numElems: 4,
+			want: "alloca i64, i32 4, align 8",
+		},
+		// i=2
+		{
+			typ: i8Ptri32StructTyp, numElems: 1,
+			want: "alloca {i8*, i32}, align 8",
+		},
+		// i=3
+		{
+			typ: i32x4VecTyp, numElems: 2,
+			want: "alloca <4 x i32>, i32 2, align 16",
+		},
+		// i=4
+		{
+			typ: i32x2ArrArr.Type(), numElems: 1,
+			want: "alloca [2 x i32], align 4",
+		},
+		// i=5
+		{
+			typ: i32Typ, numElems: 0,
+			want: "", err: "invalid alloca element count (0)",
+		},
+		// i=6
+		{
+			typ: types.NewLabel(), numElems: 1,
+			want: "", err: `invalid alloca type "label"; unable to allocate unsized type`,
+		},
+	}
+
+	for i, g := range golden {
+		inst, err := ir.NewAlloca(g.typ, g.numElems)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		got := inst.String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %q, got %q", i, g.want, got)
+		}
+	}
+}
+
+func TestAllocaSetAlign(t *testing.T) {
+	inst, err := ir.NewAlloca(i32Typ, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inst.Name = "buf"
+	if err := inst.SetAlign(8); err != nil {
+		t.Errorf("unexpected error; %v", err)
+	}
+	if got, want := inst.String(), "%buf = alloca i32, i32 4, align 8"; got != want {
+		t.Errorf("string mismatch; expected %q, got %q", want, got)
+	}
+	const want = "invalid alignment (3); not a power of two"
+	if err := inst.SetAlign(3); !sameError(err, want) {
+		t.Errorf("error mismatch; expected %v, got %v", want, err)
+	}
+	if inst.Align != 8 {
+		t.Errorf("alignment mismatch; expected 8, got %d", inst.Align)
+	}
+}
+
+func TestNewAtomicLoad(t *testing.T) {
+	golden := []struct {
+		addr      values.Value
+		ordering  ir.AtomicOrdering
+		syncScope string
+		align     int
+		want      string
+		err       string
+	}{
+		// i=0
+		{
+			addr: i32PtrQ, ordering: ir.AtomicAcquire, align: 4,
+			want: "load atomic i32, i32* %q acquire, align 4",
+		},
+		// i=1
+		{
+			addr: i32PtrQ, ordering: ir.AtomicSeqCst, syncScope: "singlethread", align: 4,
+			want: `load atomic i32, i32* %q syncscope("singlethread") seq_cst, align 4`,
+		},
+		// i=2
+		{
+			addr: i32PtrQ, ordering: ir.AtomicRelease, align: 4,
+			want: "", err: "invalid atomic load ordering (release); expected unordered, monotonic, acquire or seq_cst",
+		},
+		// i=3
+		{
+			addr: i32PtrQ, ordering: ir.AtomicAcquire,
+			want: "", err: "invalid atomic load alignment (0); atomic loads require an explicit alignment",
+		},
+		// i=4
+		{
+			addr: i32X, ordering: ir.AtomicAcquire, align: 4,
+			want: "", err: `invalid load address type; expected pointer, got "i32"`,
+		},
+	}
+
+	for i, g := range golden {
+		inst, err := ir.NewAtomicLoad(g.addr, g.ordering, g.syncScope, g.align)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		if !inst.Type.Equal(i32Typ) {
+			t.Errorf("i=%d: type mismatch; expected %v, got %v", i, i32Typ, inst.Type)
+		}
+		got := inst.String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %q, got %q", i, g.want, got)
+		}
+	}
+}
+
+func TestNewAtomicStore(t *testing.T) {
+	golden := []struct {
+		val, addr values.Value
+		ordering  ir.AtomicOrdering
+		align     int
+		want      string
+		err       string
+	}{
+		// i=0
+		{
+			val: i32FortyTwo, addr: i32PtrQ, ordering: ir.AtomicRelease, align: 4,
+			want: "store atomic i32 42, i32* %q release, align 4",
+		},
+		// i=1
+		{
+			val: i32X, addr: i32PtrQ, ordering: ir.AtomicUnordered, align: 8,
+			want: "store atomic i32 %x, i32* %q unordered, align 8",
+		},
+		// i=2
+		{
+			val: i32FortyTwo, addr: i32PtrQ, ordering: ir.AtomicAcquire, align: 4,
+			want: "", err: "invalid atomic store ordering (acquire); expected unordered, monotonic, release or seq_cst",
+		},
+		// i=3
+		{
+			val: i32FortyTwo, addr: i32PtrQ, ordering: ir.AtomicRelease,
+			want: "", err: "invalid atomic store alignment (0); atomic stores require an explicit alignment",
+		},
+		// i=4
+		{
+			val: i64FortyTwo, addr: i32PtrQ, ordering: ir.AtomicRelease, align: 4,
+			want: "", err: `invalid store value type; expected "i32", got "i64"`,
+		},
+	}
+
+	for i, g := range golden {
+		inst, err := ir.NewAtomicStore(g.val, g.addr, g.ordering, "", g.align)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		got := inst.String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %q, got %q", i, g.want, got)
+		}
+	}
+}
+
+func TestGetelementptrResultType(t *testing.T) {
+	// {i32, i8}
+	structTyp, err := types.NewStruct([]types.Type{i32Typ, i8Typ}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// [10 x {i32, i8}]
+	arrTyp, err := types.NewArray(structTyp, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	arrPtrTyp, err := types.NewPointer(arrTyp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	arrPtr1Typ, err := types.NewPointerInAddrSpace(arrTyp, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &local{name: "p", typ: arrPtrTyp}
+	p1 := &local{name: "p1", typ: arrPtr1Typ}
+
+	golden := []struct {
+		ptr     values.Value
+		indices []int
+		want    string
+		err     string
+	}{
+		// i=0
+		{
+			ptr: p, indices: []int{0, 3, 1},
+			want: "i8*",
+		},
+		// i=1
+		{
+			ptr: p, indices: []int{0, 3},
+			want: "{i32, i8}*",
+		},
+		// i=2
+		{
+			ptr: p, indices: []int{1},
+			want: "[10 x {i32, i8}]*",
+		},
+		// i=3
+		{
+			ptr: p1, indices: []int{0, 9, 0},
+			want: "i32 addrspace(1)*",
+		},
+		// i=4
+		{
+			ptr: p, indices: []int{0, 3, 2},
+			want: "", err: `invalid getelementptr; index (2) out of range for "{i32, i8}"`,
+		},
+		// i=5
+		{
+			ptr: p, indices: []int{0, 3, 1, 0},
+			want: "", err: `invalid getelementptr; unable to index into non-aggregate type "i8"`,
+		},
+	}
+
+	for i, g := range golden {
+		inst := &ir.GetelementptrInst{Type: arrTyp, Ptr: g.ptr, Indicies: g.indices}
+		typ, err := inst.ResultType()
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		got := typ.String()
+		if got != g.want {
+			t.Errorf("i=%d: type mismatch; expected %q, got %q", i, g.want, got)
+		}
+	}
+}
+
+func TestClone(t *testing.T) {
+	// Phi predecessors are copied.
+	phi := &ir.PhiInst{LocalIdent: ir.LocalIdent{Name: "x"}, Type: i32Typ, Preds: map[string]values.Value{"a": i32X, "b": i32FortyTwo}}
+	clone, ok := phi.Clone().(*ir.PhiInst)
+	if !ok {
+		t.Fatalf("invalid clone type; expected *ir.PhiInst, got %T", phi.Clone())
+	}
+	clone.Preds["a"] = i32FortyTwo
+	clone.Preds["c"] = i32X
+	delete(clone.Preds, "b")
+	clone.Name = "y"
+	if len(phi.Preds) != 2 || phi.Preds["a"] != i32X || phi.Preds["b"] != i32FortyTwo {
+		t.Errorf("original phi predecessors modified; got %v", phi.Preds)
+	}
+	if phi.Name != "x" {
+		t.Errorf("original phi name modified; expected %q, got %q", "x", phi.Name)
+	}
+
+	// Call arguments are copied.
+	call, err := ir.NewCall(funcF, []values.Value{i32X, i32FortyTwo})
+	if err != nil {
+		t.Fatal(err)
+	}
+	callClone := call.Clone().(*ir.CallInst)
+	callClone.Args[0] = i32FortyTwo
+	if call.Args[0] != i32X {
+		t.Errorf("original call arguments modified; expected %v, got %v", i32X, call.Args[0])
+	}
+
+	// Indices are copied.
+	gep := &ir.GetelementptrInst{Type: i32Typ, Ptr: i32PtrQ, Indicies: []int{0, 1}}
+	gepClone := gep.Clone().(*ir.GetelementptrInst)
+	gepClone.Indicies[1] = 2
+	if gep.Indicies[1] != 1 {
+		t.Errorf("original getelementptr indices modified; expected 1, got %d", gep.Indicies[1])
+	}
+}
+
+func TestOperands(t *testing.T) {
+	golden := []struct {
+		inst ir.Instruction
+		want []values.Value
+	}{
+		// i=0
+		{
+			inst: &ir.AddInst{Type: i32Typ, Op1: i32X, Op2: i32FortyTwo},
+			want: []values.Value{i32X, i32FortyTwo},
+		},
+		// i=1
+		{
+			inst: &ir.StoreInst{Type: i32Typ, Val: i32FortyTwo, Addr: i32PtrQ},
+			want: []values.Value{i32FortyTwo, i32PtrQ},
+		},
+		// i=2
+		{
+			inst: &ir.PhiInst{Type: i32Typ, Preds: map[string]values.Value{"b": i32FortyTwo, "a": i32X, "c": i32X}},
+			want: []values.Value{i32X, i32FortyTwo, i32X},
+		},
+		// i=3
+		{
+			inst: &ir.CallInst{Type: i32Typ, Callee: funcF, Args: []values.Value{i32X, i32FortyTwo}},
+			want: []values.Value{funcF, i32X, i32FortyTwo},
+		},
+		// i=4
+		{
+			inst: &ir.FenceInst{Ordering: ir.AtomicSeqCst},
+			want: nil,
+		},
+	}
+
+	for i, g := range golden {
+		got := g.inst.Operands()
+		if len(got) != len(g.want) {
+			t.Errorf("i=%d: operand count mismatch; expected %d, got %d", i, len(g.want), len(got))
+			continue
+		}
+		for j := range got {
+			if got[j] != g.want[j] {
+				t.Errorf("i=%d: operand %d mismatch; expected %v, got %v", i, j, g.want[j], got[j])
+			}
+		}
+	}
+}
+
+func TestSetOperand(t *testing.T) {
+	mul := &ir.MulInst{Type: i32Typ, Op1: i32X, Op2: i32FortyTwo}
+	if err := mul.SetOperand(0, i32FortyTwo); err != nil {
+		t.Fatalf("unexpected error; %v", err)
+	}
+	if mul.Op1 != i32FortyTwo {
+		t.Errorf("operand mismatch; expected %v, got %v", i32FortyTwo, mul.Op1)
+	}
+	if got, want := mul.String(), "mul i32 42, 42"; got != want {
+		t.Errorf("string mismatch; expected %q, got %q", want, got)
+	}
+
+	call := &ir.CallInst{Type: i32Typ, Callee: funcF, Args: []values.Value{i32X, i32X}}
+	if err := call.SetOperand(2, i32FortyTwo); err != nil {
+		t.Fatalf("unexpected error; %v", err)
+	}
+	if call.Args[1] != i32FortyTwo {
+		t.Errorf("argument mismatch; expected %v, got %v", i32FortyTwo, call.Args[1])
+	}
+
+	phi := &ir.PhiInst{Type: i32Typ, Preds: map[string]values.Value{"b": i32X, "a": i32X}}
+	if err := phi.SetOperand(1, i32FortyTwo); err != nil {
+		t.Fatalf("unexpected error; %v", err)
+	}
+	if phi.Preds["b"] != i32FortyTwo {
+		t.Errorf("predecessor value mismatch; expected %v, got %v", i32FortyTwo, phi.Preds["b"])
+	}
+
+	golden := []struct {
+		inst ir.Instruction
+		i    int
+		err  string
+	}{
+		// i=0
+		{
+			inst: mul, i: 2,
+			err: "invalid operand index (2); instruction has 2 operands",
+		},
+		// i=1
+		{
+			inst: mul, i: -1,
+			err: "invalid operand index (-1); instruction has 2 operands",
+		},
+		// i=2
+		{
+			inst: phi, i: 2,
+			err: "invalid operand index (2); instruction has 2 operands",
+		},
+		// i=3
+		{
+			inst: &ir.FenceInst{Ordering: ir.AtomicSeqCst}, i: 0,
+			err: "invalid operand index (0); instruction has 0 operands",
+		},
+	}
+
+	for i, g := range golden {
+		err := g.inst.SetOperand(g.i, i32X)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+		}
+	}
+}
+
+func TestBasicBlockAppendInst(t *testing.T) {
+	trunc, err := ir.NewTrunc(i32X, i8Typ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zext, err := ir.NewZext(i32X, i64Typ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sext, err := ir.NewSext(i8Three, i32Typ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ir.Instruction{trunc, zext, sext}
+	block := &ir.BasicBlock{Name: "entry"}
+	for _, inst := range want {
+		block.AppendInst(inst)
+	}
+	if len(block.Insts) != len(want) {
+		t.Fatalf("instruction count mismatch; expected %d, got %d", len(want), len(block.Insts))
+	}
+	for i, inst := range want {
+		if block.Insts[i] != inst {
+			t.Errorf("i=%d: instruction mismatch; expected %v, got %v", i, inst, block.Insts[i])
+		}
+	}
+}
+
+func TestBasicBlockReplaceUsesOf(t *testing.T) {
+	a := &local{name: "a", typ: i32Typ}
+	b := &local{name: "b", typ: i32Typ}
+	block := &ir.BasicBlock{Name: "entry"}
+	block.AppendInst(&ir.AddInst{LocalIdent: ir.LocalIdent{Name: "x"}, Type: i32Typ, Op1: a, Op2: i32FortyTwo})
+	block.AppendInst(&ir.MulInst{LocalIdent: ir.LocalIdent{Name: "y"}, Type: i32Typ, Op1: i32X, Op2: a})
+	block.SetTerm(ir.NewRet(a))
+
+	if got, want := block.ReplaceUsesOf(a, b), 3; got != want {
+		t.Errorf("replaced uses mismatch; expected %d, got %d", want, got)
+	}
+	const want = `entry:
+  %x = add i32 %b, 42
+  %y = mul i32 %x, %b
+  ret i32 %b
+`
+	if got := block.String(); got != want {
+		t.Errorf("string mismatch; expected %q, got %q", want, got)
+	}
+	if got := block.ReplaceUsesOf(a, b); got != 0 {
+		t.Errorf("replaced uses mismatch; expected 0, got %d", got)
+	}
+}
+
+func TestBasicBlockString(t *testing.T) {
+	golden := []struct {
+		name string
+		term ir.Terminator
+		want string
+	}{
+		// i=0
+		{
+			name: "entry", term: ir.NewRet(nil),
+			want: "entry:\n  ret void\n",
+		},
+		// i=1
+		{
+			name: "0", term: ir.NewRet(i32X),
+			want: "0:\n  ret i32 %x\n",
+		},
+		// i=2
+		{
+			name: "exit", term: &ir.UnreachableInst{},
+			want: "exit:\n  unreachable\n",
+		},
+	}
+
+	for i, g := range golden {
+		block := &ir.BasicBlock{Name: g.name}
+		block.SetTerm(g.term)
+		if !block.Type().Equal(types.NewLabel()) {
+			t.Errorf("i=%d: type mismatch; expected label, got %v", i, block.Type())
+		}
+		got := block.String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %q, got %q", i, g.want, got)
+		}
+	}
+}
+
+func TestFunctionEntry(t *testing.T) {
+	sig, err := types.NewFunc(i32Typ, []types.Type{i32Typ}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := values.NewParam("x", i32Typ)
+	entry, exit := &ir.BasicBlock{Name: "entry"}, &ir.BasicBlock{Name: "exit"}
+	entry.SetTerm(ir.NewBr(exit))
+	exit.SetTerm(ir.NewRet(x))
+
+	f := &ir.Function{Name: "f", Sig: sig, Params: []*values.Param{x}}
+	if got := f.Entry(); got != nil {
+		t.Errorf("entry mismatch; expected nil for function declaration, got %v", got.Name)
+	}
+	f.AppendBlock(entry)
+	f.AppendBlock(exit)
+	if got := f.Entry(); got != entry {
+		t.Errorf("entry mismatch; expected %v, got %v", entry.Name, got.Name)
+	}
+	for i, block := range f.Blocks {
+		if block.Parent != f {
+			t.Errorf("i=%d: parent mismatch of basic block %q", i, block.Name)
+		}
+	}
+	const want = "i32 (i32)*"
+	if got := f.Type().String(); got != want {
+		t.Errorf("type mismatch; expected %v, got %v", want, got)
+	}
+}
+
+func TestFunctionString(t *testing.T) {
+	sig, err := types.NewFunc(i32Typ, []types.Type{i32Typ}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	printfSig, err := types.NewFunc(i32Typ, []types.Type{i8PtrTyp}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := values.NewParam("x", i32Typ)
+	entry, exit := &ir.BasicBlock{Name: "entry"}, &ir.BasicBlock{Name: "exit"}
+	entry.SetTerm(ir.NewBr(exit))
+	exit.SetTerm(ir.NewRet(x))
+
+	golden := []struct {
+		f    *ir.Function
+		want string
+	}{
+		// i=0
+		{
+			f:    &ir.Function{Name: "printf", Sig: printfSig},
+			want: "declare i32 @printf(i8*, ...)",
+		},
+		// i=1
+		{
+			f:    &ir.Function{Name: "f", Sig: sig, Params: []*values.Param{x}, Blocks: []*ir.BasicBlock{entry, exit}},
+			want: "define i32 @f(i32 %x) {\nentry:\n  br label %exit\n\nexit:\n  ret i32 %x\n}",
+		},
+		// i=2
+		{
+			f:    &ir.Function{Name: "g", Sig: sig, Blocks: []*ir.BasicBlock{{Name: "0", Term: &ir.UnreachableInst{}}}, Personality: i8PtrP},
+			want: "define i32 @g(i32) personality i8* %p {\n0:\n  unreachable\n}",
+		},
+	}
+
+	for i, g := range golden {
+		got := g.f.String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %q, got %q", i, g.want, got)
+		}
+	}
+}
+
+func TestGlobalString(t *testing.T) {
+	str := consts.NewCharArray("foo")
+	golden := []struct {
+		global *ir.Global
+		want   string
+	}{
+		// i=0
+		{
+			global: &ir.Global{Name: "x", Content: i32Typ, Init: i32FortyTwo},
+			want:   "@x = global i32 42",
+		},
+		// i=1
+		{
+			global: &ir.Global{Name: "s", Content: str.Type(), Init: str, IsConst: true},
+			want:   `@s = constant [3 x i8] c"foo"`,
+		},
+		// i=2
+		{
+			global: &ir.Global{Name: "y", Content: i32Typ},
+			want:   "@y = external global i32",
+		},
+		// i=3
+		{
+			global: &ir.Global{Name: "z", Content: i8Ptri32StructTyp, IsConst: true},
+			want:   "@z = external constant {i8*, i32}",
+		},
+		// i=4
+		{
+			global: &ir.Global{Name: "g", Content: i32Typ, Init: i32FortyTwo, Linkage: ir.LinkagePrivate},
+			want:   "@g = private global i32 42",
+		},
+		// i=5
+		{
+			global: &ir.Global{Name: "h", Content: i32Typ, Init: i32FortyTwo, Linkage: ir.LinkageInternal, IsConst: true},
+			want:   "@h = internal constant i32 42",
+		},
+		// i=6
+		{
+			global: &ir.Global{Name: "w", Content: i32Typ, Linkage: ir.LinkageExternWeak},
+			want:   "@w = extern_weak global i32",
+		},
+		// i=7
+		{
+			global: &ir.Global{Name: "v", Content: i32Typ, Init: i32FortyTwo, Linkage: ir.LinkageInternal, Visibility: ir.VisibilityHidden, DLLStorageClass: ir.DLLStorageExport},
+			want:   "@v = internal hidden dllexport global i32 42",
+		},
+		// i=8
+		{
+			global: &ir.Global{Name: "u", Content: i32Typ, Visibility: ir.VisibilityProtected, DLLStorageClass: ir.DLLStorageImport},
+			want:   "@u = external protected dllimport global i32",
+		},
+	}
+
+	for i, g := range golden {
+		got := g.global.String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %q, got %q", i, g.want, got)
+		}
+		// The type of a global variable is a pointer to its content type.
+		ptr, ok := g.global.Type().(*types.Pointer)
+		if !ok || !ptr.Elem().Equal(g.global.Content) {
+			t.Errorf("i=%d: type mismatch; expected pointer to %v, got %v", i, g.global.Content, g.global.Type())
+		}
+		if want := "@" + g.global.Name; g.global.Ident() != want {
+			t.Errorf("i=%d: ident mismatch; expected %q, got %q", i, want, g.global.Ident())
+		}
+	}
+}
+
+func TestModuleString(t *testing.T) {
+	sig, err := types.NewFunc(i32Typ, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := &ir.BasicBlock{Name: "entry"}
+	entry.SetTerm(ir.NewRet(i32FortyTwo))
+	f := &ir.Function{Name: "f", Sig: sig}
+	f.AppendBlock(entry)
+
+	module := new(ir.Module)
+	module.AppendGlobal(&ir.Global{Name: "x", Content: i32Typ, Init: i32FortyTwo})
+	module.AppendFunc(f)
+	const want = `@x = global i32 42
+
+define i32 @f() {
+entry:
+  ret i32 42
+}
+`
+	if got := module.String(); got != want {
+		t.Errorf("string mismatch; expected %q, got %q", want, got)
+	}
+
+	// Type definitions are printed in alphabetical order.
+	module.TypeDefs = map[string]types.Type{"b": i8Ptri32StructTyp, "a": i32Typ}
+	const wantTypes = `%a = type i32
+%b = type {i8*, i32}
+
+` + want
+	if got := module.String(); got != wantTypes {
+		t.Errorf("string mismatch; expected %q, got %q", wantTypes, got)
+	}
+
+	// The data layout and target triple are printed at the top.
+	module.DataLayout = "e-m:e-p:64:64"
+	module.TargetTriple = "x86_64-unknown-linux-gnu"
+	const wantTarget = `target datalayout = "e-m:e-p:64:64"
+target triple = "x86_64-unknown-linux-gnu"
+
+` + wantTypes
+	if got := module.String(); got != wantTarget {
+		t.Errorf("string mismatch; expected %q, got %q", wantTarget, got)
+	}
+}
+
+func TestModuleTypeDef(t *testing.T) {
+	// %node = type {i32, %node*}
+	node := types.NewIdentifiedStruct("node")
+	nodePtr, err := types.NewPointer(node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := node.SetBody([]types.Type{i32Typ, nodePtr}, false); err != nil {
+		t.Fatal(err)
+	}
+	null, err := consts.NewNull(nodePtr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	module := new(ir.Module)
+	module.AddTypeDef(node)
+	module.AppendGlobal(&ir.Global{Name: "head", Content: nodePtr, Init: null})
+	const want = `%node = type {i32, %node*}
+
+@head = global %node* null
+`
+	if got := module.String(); got != want {
+		t.Errorf("string mismatch; expected %q, got %q", want, got)
+	}
+
+	// Opaque structures are printed as such.
+	module.AddTypeDef(types.NewIdentifiedStruct("handle"))
+	const wantOpaque = `%handle = type opaque
+` + want
+	if got := module.String(); got != wantOpaque {
+		t.Errorf("string mismatch; expected %q, got %q", wantOpaque, got)
+	}
+}
+
+func TestModuleAddGlobalString(t *testing.T) {
+	module := new(ir.Module)
+	global := module.AddGlobalString(".str", "hello\n")
+	if !global.Content.Equal(global.Init.Type()) {
+		t.Errorf("content type mismatch; expected %v, got %v", global.Init.Type(), global.Content)
+	}
+	const want = `@.str = private constant [7 x i8] c"hello\0A\00"
+`
+	if got := module.String(); got != want {
+		t.Errorf("string mismatch; expected %q, got %q", want, got)
+	}
+}
+
+func TestNewGetElementPtrExpr(t *testing.T) {
+	module := new(ir.Module)
+	str := module.AddGlobalString("str", "hello")
+	i32Zero, err := consts.NewInt(i32Typ, "0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	i32One, err := consts.NewInt(i32Typ, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &ir.Global{Name: "s", Content: i8Ptri32StructTyp}
+	golden := []struct {
+		base     values.Value
+		indices  []consts.Constant
+		inbounds bool
+		want     string
+		err      string
+	}{
+		// i=0
+		{
+			base: str, indices: []consts.Constant{i32Zero, i32Zero}, inbounds: true,
+			want: "i8* getelementptr inbounds ([6 x i8], [6 x i8]* @str, i32 0, i32 0)",
+		},
+		// i=1
+		{
+			base: str, indices: []consts.Constant{i32Zero},
+			want: "[6 x i8]* getelementptr ([6 x i8], [6 x i8]* @str, i32 0)",
+		},
+		// i=2
+		{
+			base: s, indices: []consts.Constant{i32Zero, i32One},
+			want: "i32* getelementptr ({i8*, i32}, {i8*, i32}* @s, i32 0, i32 1)",
+		},
+		// i=3
+		{
+			base: str, indices: nil,
+			err: "invalid getelementptr; expected at least one index",
+		},
+		// i=4
+		{
+			base: i32FortyTwo, indices: []consts.Constant{i32Zero},
+			err: `invalid getelementptr; expected pointer for base, got "i32"`,
+		},
+		// i=5
+		{
+			base: str, indices: []consts.Constant{i32Zero, i32Zero, i32Zero},
+			err: `invalid getelementptr; unable to index into non-aggregate type "i8"`,
+		},
+	}
+
+	for i, g := range golden {
+		exp, err := consts.NewGetElementPtr(g.base, g.indices, g.inbounds)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		got := exp.String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %q, got %q", i, g.want, got)
+		}
+	}
+}
+
+func TestConversionExprGlobalInit(t *testing.T) {
+	x := &ir.Global{Name: "x", Content: i32Typ}
+	ptrToInt, err := consts.NewPtrToInt(x, i64Typ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bitCast, err := consts.NewBitCast(x, i8PtrTyp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := []struct {
+		global *ir.Global
+		want   string
+	}{
+		// i=0
+		{
+			global: &ir.Global{Name: "p", Content: i64Typ, Init: ptrToInt},
+			want:   "@p = global i64 ptrtoint(i32* @x to i64)",
+		},
+		// i=1
+		{
+			global: &ir.Global{Name: "q", Content: i8PtrTyp, Init: bitCast},
+			want:   "@q = global i8* bitcast(i32* @x to i8*)",
+		},
+	}
+
+	for i, g := range golden {
+		got := g.global.String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %q, got %q", i, g.want, got)
+		}
+	}
+}
+
+func TestNewConversionExprError(t *testing.T) {
+	x := &ir.Global{Name: "x", Content: i32Typ}
+	golden := []struct {
+		exp func() (consts.Constant, error)
+		err string
+	}{
+		// i=0
+		{
+			exp: func() (consts.Constant, error) { return consts.NewPtrToInt(i32FortyTwo, i64Typ) },
+			err: `invalid pointer to integer conversion; expected pointer constant (or constant vector) for orig, got "i32"`,
+		},
+		// i=1
+		{
+			exp: func() (consts.Constant, error) { return consts.NewPtrToInt(x, f64Typ) },
+			err: `invalid pointer to integer conversion; expected integer (or integer vector) target type, got "double"`,
+		},
+		// i=2
+		{
+			exp: func() (consts.Constant, error) { return consts.NewIntToPtr(i64FortyTwo, i64Typ) },
+			err: `invalid integer to pointer conversion; expected pointer (or pointer vector) target type, got "i64"`,
+		},
+		// i=3
+		{
+			exp: func() (consts.Constant, error) { return consts.NewBitCast(i32FortyTwo, i64Typ) },
+			err: "invalid bitcast; target size (64) not equal to original size (32)",
+		},
+		// i=4
+		{
+			exp: func() (consts.Constant, error) { return consts.NewBitCast(x, i64Typ) },
+			err: `invalid bitcast; cannot convert from "i32*" to "i64"`,
+		},
+	}
+
+	for i, g := range golden {
+		_, err := g.exp()
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+		}
+	}
+}
+
+func TestModuleStringDeclare(t *testing.T) {
+	sig, err := types.NewFunc(i32Typ, []types.Type{i32Typ}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := &ir.BasicBlock{Name: "entry"}
+	entry.SetTerm(ir.NewRet(i32FortyTwo))
+	f := &ir.Function{Name: "f", Sig: sig, Params: []*values.Param{values.NewParam("x", i32Typ)}}
+	f.AppendBlock(entry)
+	g := &ir.Function{Name: "g", Sig: sig}
+
+	// External function declarations are printed before function definitions.
+	module := new(ir.Module)
+	module.AppendFunc(f)
+	module.AppendFunc(g)
+	const want = `declare i32 @g(i32)
+
+define i32 @f(i32 %x) {
+entry:
+  ret i32 42
+}
+`
+	if got := module.String(); got != want {
+		t.Errorf("string mismatch; expected %q, got %q", want, got)
+	}
+}
+
+func TestModuleStringComments(t *testing.T) {
+	sig, err := types.NewFunc(i32Typ, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := &ir.BasicBlock{Name: "entry"}
+	loop := &ir.BasicBlock{Name: "loop"}
+	exit := &ir.BasicBlock{Name: "exit", Term: ir.NewRet(i32FortyTwo)}
+	br := ir.NewBr(loop)
+	entry.SetTerm(br)
+	mul := &ir.MulInst{LocalIdent: ir.LocalIdent{Name: "y"}, Type: i32Typ, Op1: i32FortyTwo, Op2: i32FortyTwo}
+	loop.AppendInst(mul)
+	cond := values.NewLocal("c", types.I1)
+	condbr, err := ir.NewCondBr(cond, loop, exit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loop.SetTerm(condbr)
+	f := &ir.Function{Name: "f", Sig: sig}
+	f.AppendBlock(entry)
+	f.AppendBlock(loop)
+	f.AppendBlock(exit)
+
+	// The labels of basic blocks are annotated with their predecessors, and
+	// instructions are followed by their source comments.
+	module := new(ir.Module)
+	module.AppendFunc(f)
+	module.Comments = map[interface{}]string{
+		mul: "square",
+		br:  "enter loop",
+	}
+	const want = `define i32 @f() {
+entry:
+  br label %loop ; enter loop
+
+loop:                                             ; preds = %entry, %loop
+  %y = mul i32 42, 42 ; square
+  br i1 %c, label %loop, label %exit
+
+exit:                                             ; preds = %loop
+  ret i32 42
+}
+`
+	if got := module.String(); got != want {
+		t.Errorf("string mismatch; expected %q, got %q", want, got)
+	}
+
+	// The string representation of functions is left unannotated.
+	const wantFunc = `define i32 @f() {
+entry:
+  br label %loop
+
+loop:
+  %y = mul i32 42, 42
+  br i1 %c, label %loop, label %exit
+
+exit:
+  ret i32 42
+}`
+	if got := f.String(); got != wantFunc {
+		t.Errorf("string mismatch; expected %q, got %q", wantFunc, got)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	sig, err := types.NewFunc(i32Typ, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// define i32 @f() {
+	// entry:
+	//   %x = add i32 42, 42
+	//   br label %exit
+	// exit:
+	//   %y = add i32 %x, 42
+	//   %z = mul i32 %y, 42
+	//   ret i32 %z
+	// }
+	f := &ir.Function{Name: "f", Sig: sig}
+	entry := &ir.BasicBlock{Name: "entry"}
+	exit := &ir.BasicBlock{Name: "exit"}
+	x := &ir.AddInst{LocalIdent: ir.LocalIdent{Name: "x"}, Type: i32Typ, Op1: i32FortyTwo, Op2: i32FortyTwo}
+	y := &ir.AddInst{LocalIdent: ir.LocalIdent{Name: "y"}, Type: i32Typ, Op1: i32X, Op2: i32FortyTwo}
+	z := &ir.MulInst{LocalIdent: ir.LocalIdent{Name: "z"}, Type: i32Typ, Op1: i32X, Op2: i32FortyTwo}
+	entry.AppendInst(x)
+	entry.SetTerm(ir.NewBr(exit))
+	exit.AppendInst(y)
+	exit.AppendInst(z)
+	exit.SetTerm(ir.NewRet(i32X))
+	f.AppendBlock(entry)
+	f.AppendBlock(exit)
+	// declare i32 @g()
+	g := &ir.Function{Name: "g", Sig: sig}
+
+	module := new(ir.Module)
+	module.AppendFunc(g)
+	module.AppendFunc(f)
+
+	adds := 0
+	var visited []ir.Instruction
+	ir.Walk(module, ir.VisitorFunc(func(inst ir.Instruction) {
+		if _, ok := inst.(*ir.AddInst); ok {
+			adds++
+		}
+		visited = append(visited, inst)
+	}))
+	if adds != 2 {
+		t.Errorf("add instruction count mismatch; expected 2, got %d", adds)
+	}
+	want := []ir.Instruction{x, y, z}
+	if len(visited) != len(want) {
+		t.Fatalf("visited instruction count mismatch; expected %d, got %d", len(want), len(visited))
+	}
+	for i := range want {
+		if visited[i] != want[i] {
+			t.Errorf("i=%d: visited instruction mismatch; expected %v, got %v", i, want[i], visited[i])
+		}
+	}
+}
+
+func TestPhiValidate(t *testing.T) {
+	phi := &ir.PhiInst{Type: i32Typ, Preds: map[string]values.Value{"a": i32X, "b": i32FortyTwo}}
+	golden := []struct {
+		preds []string
+		err   string
+	}{
+		// i=0
+		{
+			preds: []string{"b", "a"},
+			err:   "",
+		},
+		// i=1
+		{
+			preds: []string{"a", "b", "c"},
+			err:   `invalid phi; missing incoming value for predecessor basic block "c"`,
+		},
+		// i=2
+		{
+			preds: []string{"b"},
+			err:   `invalid phi incoming value for "a"; not a predecessor basic block`,
+		},
+	}
+
+	for i, g := range golden {
+		err := phi.Validate(g.preds)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+		}
+	}
+}
+
+func TestPhiAddIncoming(t *testing.T) {
+	phi := &ir.PhiInst{LocalIdent: ir.LocalIdent{Name: "x"}, Type: i32Typ}
+	phi.AddIncoming(&ir.BasicBlock{Name: "c"}, i32X)
+	phi.AddIncoming(&ir.BasicBlock{Name: "a"}, i32FortyTwo)
+	phi.AddIncoming(&ir.BasicBlock{Name: "b"}, i32X)
+	want := "%x = phi i32 [ %x, %c ], [ 42, %a ], [ %x, %b ]"
+	for i := 0; i < 10; i++ {
+		if got := phi.String(); got != want {
+			t.Fatalf("string mismatch; expected %q, got %q", want, got)
+		}
+	}
+	if got := phi.Operands(); len(got) != 3 || got[1] != i32FortyTwo {
+		t.Errorf("operands mismatch; expected [%v %v %v], got %v", i32X, i32FortyTwo, i32X, got)
+	}
+}
+
+func TestBasicBlockSuccessors(t *testing.T) {
+	// Construct an if/else diamond.
+	entry := &ir.BasicBlock{Name: "entry"}
+	t1 := &ir.BasicBlock{Name: "true"}
+	f1 := &ir.BasicBlock{Name: "false"}
+	join := &ir.BasicBlock{Name: "join", Term: ir.NewRet(nil)}
+	cond, err := consts.NewInt(types.I1, "true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry.Term, err = ir.NewCondBr(cond, t1, f1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t1.Term = ir.NewBr(join)
+	f1.Term = ir.NewBr(join)
+	f := &ir.Function{Name: "f", Blocks: []*ir.BasicBlock{entry, t1, f1, join}}
+
+	if got := entry.Successors(); len(got) != 2 || got[0] != t1 || got[1] != f1 {
+		t.Errorf("successors mismatch of %v; expected [%v %v], got %v", entry.Ident(), t1.Ident(), f1.Ident(), got)
+	}
+	if got := join.Successors(); len(got) != 0 {
+		t.Errorf("successors mismatch of %v; expected none, got %v", join.Ident(), got)
+	}
+
+	preds := ir.Predecessors(f)
+	if got := preds[join]; len(got) != 2 || got[0] != t1 || got[1] != f1 {
+		t.Errorf("predecessors mismatch of %v; expected [%v %v], got %v", join.Ident(), t1.Ident(), f1.Ident(), got)
+	}
+	if got := preds[t1]; len(got) != 1 || got[0] != entry {
+		t.Errorf("predecessors mismatch of %v; expected [%v], got %v", t1.Ident(), entry.Ident(), got)
+	}
+	if got, ok := preds[entry]; ok {
+		t.Errorf("predecessors mismatch of %v; expected none, got %v", entry.Ident(), got)
+	}
+}
+
+func TestComputeDominators(t *testing.T) {
+	// Construct an if/else diamond, followed by a loop, and an unreachable basic
+	// block.
+	entry := &ir.BasicBlock{Name: "entry"}
+	t1 := &ir.BasicBlock{Name: "true"}
+	f1 := &ir.BasicBlock{Name: "false"}
+	join := &ir.BasicBlock{Name: "join"}
+	loop := &ir.BasicBlock{Name: "loop"}
+	exit := &ir.BasicBlock{Name: "exit", Term: ir.NewRet(nil)}
+	dead := &ir.BasicBlock{Name: "dead"}
+	cond, err := consts.NewInt(types.I1, "true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Term, err = ir.NewCondBr(cond, t1, f1); err != nil {
+		t.Fatal(err)
+	}
+	t1.Term = ir.NewBr(join)
+	f1.Term = ir.NewBr(join)
+	join.Term = ir.NewBr(loop)
+	if loop.Term, err = ir.NewCondBr(cond, loop, exit); err != nil {
+		t.Fatal(err)
+	}
+	dead.Term = ir.NewBr(join)
+	f := &ir.Function{Name: "f", Blocks: []*ir.BasicBlock{entry, t1, f1, join, loop, exit, dead}}
+	dt := ir.ComputeDominators(f)
+
+	golden := []struct {
+		block *ir.BasicBlock
+		idom  *ir.BasicBlock
+	}{
+		// i=0
+		{block: entry, idom: nil},
+		// i=1
+		{block: t1, idom: entry},
+		// i=2
+		{block: f1, idom: entry},
+		// i=3
+		{block: join, idom: entry},
+		// i=4
+		{block: loop, idom: join},
+		// i=5
+		{block: exit, idom: loop},
+		// i=6
+		{block: dead, idom: nil},
+	}
+	for i, g := range golden {
+		if got := dt.IDom(g.block); got != g.idom {
+			t.Errorf("i=%d: immediate dominator mismatch of %v; expected %v, got %v", i, g.block.Ident(), g.idom, got)
+		}
+	}
+
+	dominates := []struct {
+		a, b *ir.BasicBlock
+		want bool
+	}{
+		// i=0
+		{a: entry, b: exit, want: true},
+		// i=1
+		{a: join, b: join, want: true},
+		// i=2
+		{a: t1, b: join, want: false},
+		// i=3
+		{a: exit, b: loop, want: false},
+		// i=4
+		{a: entry, b: dead, want: false},
+		// i=5
+		{a: dead, b: join, want: false},
+	}
+	for i, g := range dominates {
+		if got := dt.Dominates(g.a, g.b); got != g.want {
+			t.Errorf("i=%d: dominance mismatch of %v over %v; expected %v, got %v", i, g.a.Ident(), g.b.Ident(), g.want, got)
+		}
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	sig, err := types.NewFunc(i32Typ, []types.Type{i32Typ, i32Typ}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := values.NewParam("a", i32Typ), values.NewParam("b", i32Typ)
+	f := &ir.Function{Name: "sum", Sig: sig, Params: []*values.Param{a, b}}
+	entry := &ir.BasicBlock{Name: "entry"}
+	f.AppendBlock(entry)
+
+	builder := ir.NewBuilder(entry)
+	ptr := builder.CreateAlloca(i32Typ)
+	builder.CreateStore(builder.CreateAdd(a, b), ptr)
+	builder.CreateRet(builder.CreateLoad(ptr))
+
+	want := `define i32 @sum(i32 %a, i32 %b) {
+entry:
+  %0 = alloca i32, align 4
+  %1 = add i32 %a, %b
+  store i32 %1, i32* %0
+  %2 = load i32, i32* %0
+  ret i32 %2
+}`
+	if got := f.String(); got != want {
+		t.Errorf("function mismatch; expected %q, got %q", want, got)
+	}
+}
+
+func TestBuilderUnnamedBlocks(t *testing.T) {
+	sig, err := types.NewFunc(i32Typ, []types.Type{i32Typ}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := values.NewParam("x", i32Typ)
+	f := &ir.Function{Name: "f", Sig: sig, Params: []*values.Param{x}}
+	entry, exit := &ir.BasicBlock{}, &ir.BasicBlock{}
+	f.AppendBlock(entry)
+	f.AppendBlock(exit)
+
+	// Unnamed values are numbered in textual order, even when instructions are
+	// emitted into an earlier basic block after a later one has been populated.
+	b := ir.NewBuilder(entry)
+	square := b.CreateMul(x, x)
+	b.CreateBr(exit)
+	b.SetBlock(exit)
+	b.CreateRet(b.CreateAdd(square, b.CreateSub(x, i32FortyTwo)))
+	b.SetBlock(entry)
+	b.CreateAdd(square, square)
+
+	want := `define i32 @f(i32 %x) {
+0:
+  %1 = mul i32 %x, %x
+  %2 = add i32 %1, %1
+  br label %3
+
+3:
+  %4 = sub i32 %x, 42
+  %5 = add i32 %1, %4
+  ret i32 %5
+}`
+	if got := f.String(); got != want {
+		t.Errorf("function mismatch; expected %q, got %q", want, got)
+	}
+	if errs := ir.ValidateSSA(f); len(errs) != 0 {
+		t.Errorf("unexpected SSA errors; %v", errs)
+	}
+}
+
+func TestBuilderUnreachableAfterNoReturn(t *testing.T) {
+	sig, err := types.NewFunc(types.NewVoid(), nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	abort := &ir.Function{Name: "abort", Sig: sig, FuncAttrs: ir.FuncNoReturn}
+	f := &ir.Function{Name: "f", Sig: sig}
+	exit := &ir.BasicBlock{Name: "exit"}
+
+	golden := []struct {
+		callee *ir.Function
+		term   ir.Terminator
+		want   string
+	}{
+		// i=0
+		{callee: abort, want: "unreachable"},
+		// i=1
+		{callee: f, want: ""},
+		// i=2
+		{callee: abort, term: ir.NewBr(exit), want: "br label %exit"},
+	}
+
+	for i, g := range golden {
+		entry := &ir.BasicBlock{Name: "entry", Term: g.term}
+		call, err := ir.NewCall(g.callee, nil)
+		if err != nil {
+			t.Errorf("i=%d: unexpected error; %v", i, err)
+			continue
+		}
+		entry.AppendInst(call)
+		ir.NewBuilder(entry).CreateUnreachableAfterNoReturn(call)
+		var got string
+		if entry.Term != nil {
+			got = fmt.Sprint(entry.Term)
+		}
+		if got != g.want {
+			t.Errorf("i=%d: terminator mismatch; expected %q, got %q", i, g.want, got)
+		}
+	}
+}
+
+func TestBuilderInvalidOperands(t *testing.T) {
+	golden := []struct {
+		create func(b *ir.Builder)
+		err    string
+	}{
+		// i=0
+		{
+			create: func(b *ir.Builder) { b.CreateAdd(f32Three, f32Three) },
+			err:    `invalid add operand type; expected integer (or vector of integers), got "float"`,
+		},
+		// i=1
+		{
+			create: func(b *ir.Builder) { b.CreateMul(i32X, i64FortyTwo) },
+			err:    `invalid mul operands; type mismatch between "i32" and "i64"`,
+		},
+		// i=2
+		{
+			create: func(b *ir.Builder) { b.CreateIcmp(ir.IntEq, f64Three, f64Three) },
+			err:    `invalid icmp operand type; expected integer or pointer (or vector thereof), got "double"`,
+		},
+	}
+
+	for i, g := range golden {
+		entry := &ir.BasicBlock{Name: "entry"}
+		err := func() (err error) {
+			defer func() {
+				if e, ok := recover().(error); ok {
+					err = e
+				}
+			}()
+			g.create(ir.NewBuilder(entry))
+			return nil
+		}()
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		}
+		if len(entry.Insts) != 0 {
+			t.Errorf("i=%d: invalid instruction appended; %v", i, entry.Insts)
+		}
+	}
+}
+
+func TestInferType(t *testing.T) {
+	i1x4VecTyp, err := types.NewVector(types.I1, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := []struct {
+		inst ir.Instruction
+		want types.Type
+		err  string
+	}{
+		// i=0
+		{
+			inst: &ir.AddInst{Op1: i32X, Op2: i32FortyTwo},
+			want: i32Typ,
+		},
+		// i=1
+		{
+			inst: &ir.AddInst{Op1: i32X, Op2: i64FortyTwo},
+			err:  `invalid add operands; type mismatch between "i32" and "i64"`,
+		},
+		// i=2
+		{
+			inst: &ir.IcmpInst{Pred: ir.IntSlt, Op1: i32X, Op2: i32FortyTwo},
+			want: types.I1,
+		},
+		// i=3
+		{
+			inst: &ir.IcmpInst{Pred: ir.IntEq, Op1: i32x4VecA, Op2: i32x4VecB},
+			want: i1x4VecTyp,
+		},
+		// i=4
+		{
+			inst: &ir.LoadInst{Addr: i32PtrQ},
+			want: i32Typ,
+		},
+		// i=5
+		{
+			inst: &ir.LoadInst{Addr: i32X},
+			err:  `invalid load address type; expected pointer, got "i32"`,
+		},
+		// i=6
+		{
+			inst: &ir.AllocaInst{Type: i32Typ},
+			want: i32PtrTyp,
+		},
+		// i=7
+		{
+			inst: &ir.PhiInst{},
+			err:  "unable to infer result type of phi; missing incoming values",
+		},
+		// i=8
+		{
+			inst: &ir.PhiInst{Preds: map[string]values.Value{"a": i32X, "b": i32FortyTwo}},
+			want: i32Typ,
+		},
+		// i=9
+		{
+			inst: &ir.PhiInst{Preds: map[string]values.Value{"a": i32X, "b": i64FortyTwo}},
+			err:  `invalid phi incoming values; type mismatch between "i32" and "i64"`,
+		},
+		// i=10
+		{
+			inst: &ir.CallInst{Callee: funcF, Args: []values.Value{i32X, i32X}},
+			want: i32Typ,
+		},
+		// i=11
+		{
+			inst: &ir.CallInst{Callee: i32X},
+			err:  `invalid callee type; expected function (or pointer to function), got "i32"`,
+		},
+		// i=12
+		{
+			inst: &ir.ExtractvalueInst{Aggregate: i8i8i32StructN, Indices: []int{0, 1}},
+			want: i8Typ,
+		},
+		// i=13
+		{
+			inst: &ir.ExtractvalueInst{Aggregate: i32x2ArrArr, Indices: []int{2}},
+			err:  `invalid extractvalue; index (2) out of range for "[2 x i32]"`,
+		},
+		// i=14
+		{
+			inst: &ir.LandingpadInst{Type: i8Ptri32StructTyp, Cleanup: true},
+			want: i8Ptri32StructTyp,
+		},
+		// i=15
+		{
+			inst: &ir.AddInst{Op1: f32Three, Op2: f32Three},
+			err:  `invalid add operand type; expected integer (or vector of integers), got "float"`,
+		},
+		// i=16
+		{
+			inst: &ir.ShlInst{Op1: f64Three, Op2: f64Three},
+			err:  `invalid shl operand type; expected integer (or vector of integers), got "double"`,
+		},
+		// i=17
+		{
+			inst: &ir.FaddInst{Op1: i32X, Op2: i32FortyTwo},
+			err:  `invalid fadd operand type; expected floating point (or vector of floating points), got "i32"`,
+		},
+		// i=18
+		{
+			inst: &ir.FaddInst{Op1: f32Three, Op2: f32Three},
+			want: f32Typ,
+		},
+		// i=19
+		{
+			inst: &ir.IcmpInst{Pred: ir.IntEq, Op1: f32Three, Op2: f32Three},
+			err:  `invalid icmp operand type; expected integer or pointer (or vector thereof), got "float"`,
+		},
+		// i=20
+		{
+			inst: &ir.IcmpInst{Pred: ir.IntEq, Op1: i32PtrQ, Op2: i32PtrQ},
+			want: types.I1,
+		},
+		// i=21
+		{
+			inst: &ir.FcmpInst{Pred: ir.FloatOeq, Op1: i32X, Op2: i32X},
+			err:  `invalid fcmp operand type; expected floating point (or vector of floating points), got "i32"`,
+		},
+	}
+
+	for i, g := range golden {
+		got, err := ir.InferType(g.inst)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		if !got.Equal(g.want) {
+			t.Errorf("i=%d: type mismatch; expected %v, got %v", i, g.want, got)
+		}
+	}
+}
+
+func TestCallConvString(t *testing.T) {
+	void := types.NewVoid()
+	sig, err := types.NewFunc(void, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := []struct {
+		cc   ir.CallConv
+		call string
+		decl string
+	}{
+		// i=0
+		{
+			cc:   ir.CallConvC,
+			call: "%y = call i32 %f(i32 %x, i32 42)",
+			decl: "declare void @g()",
+		},
+		// i=1
+		{
+			cc:   ir.CallConvFast,
+			call: "%y = call fastcc i32 %f(i32 %x, i32 42)",
+			decl: "declare fastcc void @g()",
+		},
+		// i=2
+		{
+			cc:   ir.CallConv(11),
+			call: "%y = call cc 11 i32 %f(i32 %x, i32 42)",
+			decl: "declare cc 11 void @g()",
+		},
+		// i=3
+		{
+			// cc 10 has the keyword ghccc.
+			cc:   ir.CallConv(10),
+			call: "%y = call ghccc i32 %f(i32 %x, i32 42)",
+			decl: "declare ghccc void @g()",
+		},
+	}
+
+	for i, g := range golden {
+		call := &ir.CallInst{LocalIdent: ir.LocalIdent{Name: "y"}, Type: i32Typ, Callee: funcF, Args: []values.Value{i32X, i32FortyTwo}, CallConv: g.cc}
+		if got := call.String(); got != g.call {
+			t.Errorf("i=%d: call mismatch; expected %q, got %q", i, g.call, got)
+		}
+		f := &ir.Function{Name: "g", Sig: sig, CallConv: g.cc}
+		if got := f.String(); got != g.decl {
+			t.Errorf("i=%d: declaration mismatch; expected %q, got %q", i, g.decl, got)
+		}
+	}
+}
+
+func TestFunctionSymbolString(t *testing.T) {
+	sig, err := types.NewFunc(types.NewVoid(), nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := []struct {
+		linkage    ir.Linkage
+		visibility ir.Visibility
+		class      ir.DLLStorageClass
+		want       string
+	}{
+		// i=0
+		{
+			linkage: ir.LinkageExternal,
+			want:    "define void @f() {\nentry:\n  ret void\n}",
+		},
+		// i=1
+		{
+			linkage: ir.LinkageInternal,
+			want:    "define internal void @f() {\nentry:\n  ret void\n}",
+		},
+		// i=2
+		{
+			linkage: ir.LinkagePrivate,
+			want:    "define private void @f() {\nentry:\n  ret void\n}",
+		},
+		// i=3
+		{
+			linkage: ir.LinkageInternal, visibility: ir.VisibilityHidden,
+			want: "define internal hidden void @f() {\nentry:\n  ret void\n}",
+		},
+		// i=4
+		{
+			visibility: ir.VisibilityProtected, class: ir.DLLStorageExport,
+			want: "define protected dllexport void @f() {\nentry:\n  ret void\n}",
+		},
+	}
+
+	for i, g := range golden {
+		f := &ir.Function{Name: "f", Sig: sig, Linkage: g.linkage, Visibility: g.visibility, DLLStorageClass: g.class}
+		f.AppendBlock(&ir.BasicBlock{Name: "entry", Term: ir.NewRet(nil)})
+		if got := f.String(); got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %q, got %q", i, g.want, got)
+		}
+	}
+}
+
+func TestFunctionAttrsString(t *testing.T) {
+	sig, err := types.NewFunc(types.NewVoid(), []types.Type{i8PtrTyp, i32Typ}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zextSig, err := types.NewFunc(types.I8, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := []struct {
+		f    *ir.Function
+		want string
+	}{
+		// i=0
+		{
+			f: &ir.Function{
+				Name: "f", Sig: sig,
+				Params:     []*values.Param{values.NewParam("p", i8PtrTyp), values.NewParam("n", i32Typ)},
+				ParamAttrs: []ir.ParamAttrs{ir.ParamNoAlias | ir.ParamNoCapture},
+			},
+			want: "declare void @f(i8* noalias nocapture %p, i32 %n)",
+		},
+		// i=1
+		{
+			f: &ir.Function{
+				Name: "f", Sig: sig,
+				ParamAttrs: []ir.ParamAttrs{ir.ParamReadOnly, ir.ParamSignExt},
+				FuncAttrs:  ir.FuncNoReturn,
+			},
+			want: "declare void @f(i8* readonly, i32 signext) noreturn",
+		},
+		// i=2
+		{
+			f: &ir.Function{
+				Name: "g", Sig: zextSig,
+				ResultAttrs: ir.ParamZeroExt,
+				FuncAttrs:   ir.FuncNoUnwind | ir.FuncNoReturn,
+			},
+			want: "declare zeroext i8 @g() noreturn nounwind",
+		},
+		// i=3
+		{
+			f: &ir.Function{
+				Name: "h", Sig: zextSig,
+				FuncAttrs:   ir.FuncUWTable,
+				StringAttrs: ir.StringAttrs{"target-cpu": "x86-64", "frame-pointer": "all", "no-builtins": "", "target-features": "+sse2"},
+			},
+			want: `declare i8 @h() uwtable "frame-pointer"="all" "no-builtins" "target-cpu"="x86-64" "target-features"="+sse2"`,
+		},
+		// i=4
+		{
+			f: &ir.Function{
+				Name: "h", Sig: zextSig,
+				StringAttrs: ir.StringAttrs{"quote": `a"b`},
+			},
+			want: `declare i8 @h() "quote"="a\22b"`,
+		},
+		// i=5
+		{
+			f: &ir.Function{
+				Name: "h", Sig: zextSig,
+				FuncAttrs:   ir.FuncNoUnwind | ir.FuncUWTable,
+				UWTableKind: ir.UWTableSync,
+			},
+			want: "declare i8 @h() nounwind uwtable(sync)",
+		},
+		// i=6
+		{
+			f: &ir.Function{
+				Name: "h", Sig: zextSig,
+				FuncAttrs:   ir.FuncUWTable,
+				UWTableKind: ir.UWTableAsync,
+				StringAttrs: ir.StringAttrs{"target-cpu": "x86-64"},
+			},
+			want: `declare i8 @h() uwtable(async) "target-cpu"="x86-64"`,
+		},
+		// i=7
+		{
+			f: &ir.Function{
+				Name: "h", Sig: zextSig,
+				UWTableKind: ir.UWTableSync,
+			},
+			want: "declare i8 @h()",
+		},
+	}
+
+	for i, g := range golden {
+		if got := g.f.String(); got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %q, got %q", i, g.want, got)
+		}
+	}
+}
+
+func TestMetadataString(t *testing.T) {
+	flags := &ir.MetadataNode{ID: 0, Fields: []values.Value{i32FortyTwo, ir.MetadataString("Dwarf Version")}}
+	// Fields may refer to other metadata nodes and to null.
+	node := &ir.MetadataNode{ID: 1, Fields: []values.Value{flags, nil}}
+	if got, want := flags.String(), `!{i32 42, !"Dwarf Version"}`; got != want {
+		t.Errorf("string mismatch; expected %q, got %q", want, got)
+	}
+	if got, want := node.String(), "!{!0, null}"; got != want {
+		t.Errorf("string mismatch; expected %q, got %q", want, got)
+	}
+
+	// Metadata attachments are appended to the instruction.
+	dbg := &ir.MetadataNode{ID: 3}
+	store := &ir.StoreInst{Type: i32Typ, Val: i32FortyTwo, Addr: i32PtrQ, Metadata: ir.Metadata{"dbg": dbg}}
+	if got, want := store.String(), "store i32 42, i32* %q, !dbg !3"; got != want {
+		t.Errorf("string mismatch; expected %q, got %q", want, got)
+	}
+	trunc := &ir.TruncInst{LocalIdent: ir.LocalIdent{Name: "y"}, From: i32X, To: i8Typ, Metadata: ir.Metadata{"dbg": dbg}}
+	if got, want := trunc.String(), "%y = trunc i32 %x to i8, !dbg !3"; got != want {
+		t.Errorf("string mismatch; expected %q, got %q", want, got)
+	}
+	fence := &ir.FenceInst{Ordering: ir.AtomicSeqCst, Metadata: ir.Metadata{"dbg": dbg}}
+	if got, want := fence.String(), "fence seq_cst, !dbg !3"; got != want {
+		t.Errorf("string mismatch; expected %q, got %q", want, got)
+	}
+	// Attachments are preserved by Clone, without sharing the map.
+	c := store.Clone().(*ir.StoreInst)
+	delete(c.Metadata, "dbg")
+	if _, ok := store.Metadata["dbg"]; !ok {
+		t.Errorf("metadata attachment of original instruction removed by clone")
+	}
+
+	// Named metadata is printed before the metadata nodes.
+	module := &ir.Module{
+		NamedMetadata: map[string][]*ir.MetadataNode{"llvm.module.flags": {flags}},
+		Metadata:      []*ir.MetadataNode{flags, node},
+	}
+	const want = `!llvm.module.flags = !{!0}
+
+!0 = !{i32 42, !"Dwarf Version"}
+!1 = !{!0, null}
+`
+	if got := module.String(); got != want {
+		t.Errorf("string mismatch; expected %q, got %q", want, got)
+	}
+}
+
+func TestRange(t *testing.T) {
+	i8 := func(s string) *consts.Int {
+		c, err := consts.NewInt(i8Typ, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	// !0 = !{i8 0, i8 2}
+	node, err := ir.NewRange(0, i8("0"), i8("2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	i8PtrP := values.NewLocal("p", i8PtrTyp)
+	load := &ir.LoadInst{LocalIdent: ir.LocalIdent{Name: "tag"}, Type: i8Typ, Addr: i8PtrP}
+	if err := ir.AttachRange(load, node); err != nil {
+		t.Fatal(err)
+	}
+	module := &ir.Module{Metadata: []*ir.MetadataNode{node}}
+	const want = "!0 = !{i8 0, i8 2}\n"
+	if got := module.String(); got != want {
+		t.Errorf("string mismatch; expected %q, got %q", want, got)
+	}
+	const wantLoad = "%tag = load i8, i8* %p, !range !0"
+	if got := load.String(); got != wantLoad {
+		t.Errorf("string mismatch; expected %q, got %q", wantLoad, got)
+	}
+
+	golden := []struct {
+		inst   ir.Instruction
+		lo, hi *consts.Int
+		err    string
+	}{
+		// i=0
+		{
+			inst: &ir.CallInst{LocalIdent: ir.LocalIdent{Name: "y"}, Type: i8Typ, Callee: funcF},
+			lo:   i8("-1"), hi: i8("1"),
+		},
+		// i=1
+		{
+			inst: load,
+			lo:   i8("2"), hi: i8("2"),
+			err: "invalid range metadata [2, 2); expected lower bound less than upper bound",
+		},
+		// i=2
+		{
+			inst: &ir.LoadInst{LocalIdent: ir.LocalIdent{Name: "x"}, Type: i32Typ, Addr: i32PtrQ},
+			lo:   i8("0"), hi: i8("2"),
+			err: `invalid range metadata bound i8 0; expected constant of type "i32"`,
+		},
+		// i=3
+		{
+			inst: &ir.LoadInst{LocalIdent: ir.LocalIdent{Name: "x"}, Type: f32Typ, Addr: i32PtrQ},
+			lo:   i8("0"), hi: i8("2"),
+			err: `invalid range metadata of type "float"; expected integer type`,
+		},
+		// i=4
+		{
+			inst: &ir.AddInst{LocalIdent: ir.LocalIdent{Name: "x"}, Type: i8Typ, Op1: i8("0"), Op2: i8("2")},
+			lo:   i8("0"), hi: i8("2"),
+			err: `invalid instruction "add" for range metadata; expected load or call`,
+		},
+	}
+
+	for i, g := range golden {
+		err := ir.AttachRange(g.inst, &ir.MetadataNode{ID: 1, Fields: []values.Value{g.lo, g.hi}})
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		if !strings.HasSuffix(fmt.Sprint(g.inst), ", !range !1") {
+			t.Errorf("i=%d: missing range metadata attachment; got %q", i, g.inst)
+		}
+	}
+	if _, err := ir.NewRange(0, i8("2"), i8("0")); err == nil {
+		t.Errorf("expected error for invalid range [2, 0)")
+	}
+}
+
+func TestAttachVectorizeHints(t *testing.T) {
+	sig, err := types.NewFunc(i32Typ, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// entry:
+	//   br label %loop
+	// loop:
+	//   br i1 %c, label %loop, label %exit
+	// exit:
+	//   ret i32 42
+	entry := &ir.BasicBlock{Name: "entry"}
+	loop := &ir.BasicBlock{Name: "loop"}
+	exit := &ir.BasicBlock{Name: "exit", Term: ir.NewRet(i32FortyTwo)}
+	entry.SetTerm(ir.NewBr(loop))
+	condbr, err := ir.NewCondBr(values.NewLocal("c", types.I1), loop, exit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loop.SetTerm(condbr)
+	f := &ir.Function{Name: "f", Sig: sig}
+	f.AppendBlock(entry)
+	f.AppendBlock(loop)
+	f.AppendBlock(exit)
+
+	flags := &ir.MetadataNode{ID: 0, Fields: []values.Value{i32FortyTwo}}
+	module := &ir.Module{Metadata: []*ir.MetadataNode{flags}}
+	module.AppendFunc(f)
+	if _, err := module.AttachVectorizeHints(loop, true, 4); err != nil {
+		t.Fatal(err)
+	}
+	const want = `define i32 @f() {
+entry:
+  br label %loop
+
+loop:                                             ; preds = %entry, %loop
+  br i1 %c, label %loop, label %exit, !llvm.loop !1
+
+exit:                                             ; preds = %loop
+  ret i32 42
+}
+
+!0 = !{i32 42}
+!1 = distinct !{!1, !2, !3}
+!2 = !{!"llvm.loop.vectorize.enable", i1 true}
+!3 = !{!"llvm.loop.vectorize.width", i32 4}
+`
+	if got := module.String(); got != want {
+		t.Errorf("string mismatch; expected %q, got %q", want, got)
+	}
+
+	// The width hint is omitted if the width is 0.
+	exit.SetTerm(ir.NewBr(exit))
+	node, err := module.AttachVectorizeHints(exit, false, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const wantNode = `distinct !{!4, !5}`
+	if got := node.String(); got != wantNode {
+		t.Errorf("string mismatch; expected %q, got %q", wantNode, got)
+	}
+	const wantTerm = "br label %exit, !llvm.loop !4"
+	if got := fmt.Sprint(exit.Term); got != wantTerm {
+		t.Errorf("string mismatch; expected %q, got %q", wantTerm, got)
+	}
+
+	golden := []struct {
+		header *ir.BasicBlock
+		width  int
+		err    string
+	}{
+		// i=0
+		{header: entry, err: "invalid loop header %entry; no back edge"},
+		// i=1
+		{header: &ir.BasicBlock{Name: "orphan"}, err: "invalid loop header %orphan; not part of a function"},
+		// i=2
+		{header: loop, width: -1, err: "invalid vectorization width (-1); expected >= 0"},
+	}
+	for i, g := range golden {
+		_, err := module.AttachVectorizeHints(g.header, true, g.width)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+		}
+	}
+}
+
+func TestAlignString(t *testing.T) {
+	dbg := &ir.MetadataNode{ID: 1}
+	golden := []struct {
+		inst fmt.Stringer
+		want string
+	}{
+		// i=0
+		{
+			inst: &ir.AllocaInst{Name: "x", Type: i64Typ, NumElems: 1},
+			want: "%x = alloca i64",
+		},
+		// i=1
+		{
+			inst: &ir.AllocaInst{Name: "x", Type: i64Typ, NumElems: 2, Align: 8},
+			want: "%x = alloca i64, i32 2, align 8",
+		},
+		// i=2
+		{
+			inst: &ir.LoadInst{Name: "y", Type: i32Typ, Addr: i32PtrQ},
+			want: "%y = load i32, i32* %q",
+		},
+		// i=3
+		{
+			inst: &ir.LoadInst{Name: "y", Type: i32Typ, Addr: i32PtrQ, Align: 8, Metadata: ir.Metadata{"dbg": dbg}},
+			want: "%y = load i32, i32* %q, align 8, !dbg !1",
+		},
+		// i=4
+		{
+			inst: &ir.StoreInst{Type: i32Typ, Val: i32FortyTwo, Addr: i32PtrQ},
+			want: "store i32 42, i32* %q",
+		},
+		// i=5
+		{
+			inst: &ir.StoreInst{Type: i32Typ, Val: i32FortyTwo, Addr: i32PtrQ, Atomic: true, Ordering: ir.AtomicRelease, Align: 8},
+			want: "store atomic i32 42, i32* %q release, align 8",
+		},
+	}
+
+	for i, g := range golden {
+		got := g.inst.String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %q, got %q", i, g.want, got)
+		}
+	}
+}
+
+func TestFold(t *testing.T) {
+	i8 := func(s string) values.Value {
+		c, err := consts.NewInt(i8Typ, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	i32Zero, err := consts.NewInt(i32Typ, "0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	i1True, err := consts.NewInt(i1Typ, "true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := []struct {
+		inst ir.Instruction
+		want string
+		ok   bool
+	}{
+		// i=0
+		{
+			// add i8 200, 100
+			inst: &ir.AddInst{Type: i8Typ, Op1: i8("200"), Op2: i8("100")},
+			want: "i8 44", ok: true,
+		},
+		// i=1
+		{
+			inst: &ir.AddInst{Type: i8Typ, Op1: i8("100"), Op2: i8("100")},
+			want: "i8 -56", ok: true,
+		},
+		// i=2
+		{
+			inst: &ir.AddInst{Type: i8Typ, Op1: i8("100"), Op2: i8("100"), NSW: true},
+			ok:   false,
+		},
+		// i=3
+		{
+			inst: &ir.AddInst{Type: i8Typ, Op1: i8("200"), Op2: i8("100"), NUW: true},
+			ok:   false,
+		},
+		// i=4
+		{
+			inst: &ir.SubInst{Type: i8Typ, Op1: i8("-128"), Op2: i8("1")},
+			want: "i8 127", ok: true,
+		},
+		// i=5
+		{
+			inst: &ir.SdivInst{Type: i32Typ, Op1: i32FortyTwo, Op2: i32Zero},
+			ok:   false,
+		},
+		// i=6
+		{
+			inst: &ir.SdivInst{Type: i32Typ, Op1: i32X, Op2: i32Zero},
+			ok:   false,
+		},
+		// i=7
+		{
+			inst: &ir.SdivInst{Type: i8Typ, Op1: i8("-128"), Op2: i8("-1")},
+			ok:   false,
+		},
+		// i=8
+		{
+			inst: &ir.UdivInst{Type: i8Typ, Op1: i8("-1"), Op2: i8("2")},
+			want: "i8 127", ok: true,
+		},
+		// i=9
+		{
+			inst: &ir.UdivInst{Type: i8Typ, Op1: i8("-1"), Op2: i8("2"), Exact: true},
+			ok:   false,
+		},
+		// i=10
+		{
+			inst: &ir.SremInst{Type: i8Typ, Op1: i8("-7"), Op2: i8("2")},
+			want: "i8 -1", ok: true,
+		},
+		// i=11
+		{
+			inst: &ir.ShlInst{Type: i8Typ, Op1: i8("1"), Op2: i8("7")},
+			want: "i8 -128", ok: true,
+		},
+		// i=12
+		{
+			inst: &ir.ShlInst{Type: i8Typ, Op1: i8("1"), Op2: i8("8")},
+			ok:   false,
+		},
+		// i=13
+		{
+			inst: &ir.LshrInst{Type: i8Typ, Op1: i8("-1"), Op2: i8("4")},
+			want: "i8 15", ok: true,
+		},
+		// i=14
+		{
+			inst: &ir.AshrInst{Type: i8Typ, Op1: i8("-16"), Op2: i8("2")},
+			want: "i8 -4", ok: true,
+		},
+		// i=15
+		{
+			inst: &ir.XorInst{Type: i1Typ, Op1: i1True, Op2: i1True},
+			want: "i1 false", ok: true,
+		},
+		// i=16
+		{
+			inst: &ir.IcmpInst{Pred: ir.IntUlt, Type: i8Typ, Op1: i8("-1"), Op2: i8("1")},
+			want: "i1 false", ok: true,
+		},
+		// i=17
+		{
+			inst: &ir.IcmpInst{Pred: ir.IntSlt, Type: i8Typ, Op1: i8("-1"), Op2: i8("1")},
+			want: "i1 true", ok: true,
+		},
+		// i=18
+		{
+			inst: &ir.FaddInst{Type: f64Typ, Op1: f64Three, Op2: f64Three},
+			want: "double 6.0", ok: true,
+		},
+		// i=19
+		{
+			inst: &ir.AddInst{Type: i32Typ, Op1: i32FortyTwo, Op2: i64FortyTwo},
+			ok:   false,
+		},
+	}
+
+	for i, g := range golden {
+		v, ok := ir.Fold(g.inst)
+		if ok != g.ok {
+			t.Errorf("i=%d: fold mismatch; expected %v, got %v", i, g.ok, ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if got := v.String(); got != g.want {
+			t.Errorf("i=%d: constant mismatch; expected %q, got %q", i, g.want, got)
+		}
+	}
+}
+
+func TestIntPredicateString(t *testing.T) {
+	golden := []struct {
+		pred ir.IntPredicate
+		want string
+	}{
+		{pred: ir.IntEq, want: "eq"},
+		{pred: ir.IntNe, want: "ne"},
+		{pred: ir.IntUgt, want: "ugt"},
+		{pred: ir.IntUge, want: "uge"},
+		{pred: ir.IntUlt, want: "ult"},
+		{pred: ir.IntUle, want: "ule"},
+		{pred: ir.IntSgt, want: "sgt"},
+		{pred: ir.IntSge, want: "sge"},
+		{pred: ir.IntSlt, want: "slt"},
+		{pred: ir.IntSle, want: "sle"},
+	}
+
+	for i, g := range golden {
+		got := g.pred.String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %q, got %q", i, g.want, got)
+		}
+		pred, err := ir.ParseIntPredicate(got)
+		if err != nil {
+			t.Errorf("i=%d: unexpected error; %v", i, err)
+			continue
+		}
+		if pred != g.pred {
+			t.Errorf("i=%d: predicate mismatch; expected %v, got %v", i, g.pred, pred)
+		}
+	}
+
+	const want = `invalid integer predicate "oeq"`
+	if _, err := ir.ParseIntPredicate("oeq"); !sameError(err, want) {
+		t.Errorf("error mismatch; expected %v, got %v", want, err)
+	}
+	inst := &ir.IcmpInst{Pred: ir.IntSlt, Type: i32Typ, Op1: i32X, Op2: i32FortyTwo}
+	inst.Name = "y"
+	if got, want := inst.String(), "%y = icmp slt i32 %x, 42"; got != want {
+		t.Errorf("string mismatch; expected %q, got %q", want, got)
+	}
+}
+
+func TestFloatPredicateString(t *testing.T) {
+	golden := []struct {
+		pred ir.FloatPredicate
+		want string
+	}{
+		{pred: ir.FloatFalse, want: "false"},
+		{pred: ir.FloatOeq, want: "oeq"},
+		{pred: ir.FloatOgt, want: "ogt"},
+		{pred: ir.FloatOge, want: "oge"},
+		{pred: ir.FloatOlt, want: "olt"},
+		{pred: ir.FloatOle, want: "ole"},
+		{pred: ir.FloatOne, want: "one"},
+		{pred: ir.FloatOrd, want: "ord"},
+		{pred: ir.FloatUeq, want: "ueq"},
+		{pred: ir.FloatUgt, want: "ugt"},
+		{pred: ir.FloatUge, want: "uge"},
+		{pred: ir.FloatUlt, want: "ult"},
+		{pred: ir.FloatUle, want: "ule"},
+		{pred: ir.FloatUne, want: "une"},
+		{pred: ir.FloatUno, want: "uno"},
+		{pred: ir.FloatTrue, want: "true"},
+	}
+
+	for i, g := range golden {
+		got := g.pred.String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %q, got %q", i, g.want, got)
+		}
+		pred, err := ir.ParseFloatPredicate(got)
+		if err != nil {
+			t.Errorf("i=%d: unexpected error; %v", i, err)
+			continue
+		}
+		if pred != g.pred {
+			t.Errorf("i=%d: predicate mismatch; expected %v, got %v", i, g.pred, pred)
+		}
+	}
+
+	const want = `invalid floating point predicate "slt"`
+	if _, err := ir.ParseFloatPredicate("slt"); !sameError(err, want) {
+		t.Errorf("error mismatch; expected %v, got %v", want, err)
+	}
+	a := &local{name: "a", typ: f32Typ}
+	b := &local{name: "b", typ: f32Typ}
+	inst := &ir.FcmpInst{Pred: ir.FloatOne, Type: f32Typ, Op1: a, Op2: b}
+	inst.Name = "y"
+	if got, want := inst.String(), "%y = fcmp one float %a, %b"; got != want {
+		t.Errorf("string mismatch; expected %q, got %q", want, got)
+	}
+	inst.FastMath = ir.FastNoNaNs
+	if got, want := inst.String(), "%y = fcmp nnan one float %a, %b"; got != want {
+		t.Errorf("string mismatch; expected %q, got %q", want, got)
+	}
+}
+
+func TestAtomicOrderingString(t *testing.T) {
+	golden := []struct {
+		ordering ir.AtomicOrdering
+		want     string
+	}{
+		{ordering: ir.AtomicUnordered, want: "unordered"},
+		{ordering: ir.AtomicMonotonic, want: "monotonic"},
+		{ordering: ir.AtomicAcquire, want: "acquire"},
+		{ordering: ir.AtomicRelease, want: "release"},
+		{ordering: ir.AtomicAcqRel, want: "acq_rel"},
+		{ordering: ir.AtomicSeqCst, want: "seq_cst"},
+	}
+
+	for i, g := range golden {
+		got := g.ordering.String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %q, got %q", i, g.want, got)
+		}
+		ordering, err := ir.ParseAtomicOrdering(got)
+		if err != nil {
+			t.Errorf("i=%d: unexpected error; %v", i, err)
+			continue
+		}
+		if ordering != g.ordering {
+			t.Errorf("i=%d: ordering mismatch; expected %v, got %v", i, g.ordering, ordering)
+		}
+	}
+
+	// The none ordering of non-atomic instructions has no keyword.
+	for _, s := range []string{"none", "acquire_release"} {
+		want := fmt.Sprintf("invalid atomic ordering %q", s)
+		if _, err := ir.ParseAtomicOrdering(s); !sameError(err, want) {
+			t.Errorf("error mismatch; expected %v, got %v", want, err)
+		}
+	}
+}
+
+func TestOpcode(t *testing.T) {
+	golden := []struct {
+		inst ir.Instruction
+		want string
+	}{
+		// i=0
+		{inst: &ir.AddInst{}, want: "add"},
+		// i=1
+		{inst: &ir.FaddInst{}, want: "fadd"},
+		// i=2
+		{inst: &ir.SubInst{}, want: "sub"},
+		// i=3
+		{inst: &ir.FsubInst{}, want: "fsub"},
+		// i=4
+		{inst: &ir.MulInst{}, want: "mul"},
+		// i=5
+		{inst: &ir.FmulInst{}, want: "fmul"},
+		// i=6
+		{inst: &ir.UdivInst{}, want: "udiv"},
+		// i=7
+		{inst: &ir.SdivInst{}, want: "sdiv"},
+		// i=8
+		{inst: &ir.FdivInst{}, want: "fdiv"},
+		// i=9
+		{inst: &ir.UremInst{}, want: "urem"},
+		// i=10
+		{inst: &ir.SremInst{}, want: "srem"},
+		// i=11
+		{inst: &ir.FremInst{}, want: "frem"},
+		// i=12
+		{inst: &ir.ShlInst{}, want: "shl"},
+		// i=13
+		{inst: &ir.LshrInst{}, want: "lshr"},
+		// i=14
+		{inst: &ir.AshrInst{}, want: "ashr"},
+		// i=15
+		{inst: &ir.AndInst{}, want: "and"},
+		// i=16
+		{inst: &ir.OrInst{}, want: "or"},
+		// i=17
+		{inst: &ir.XorInst{}, want: "xor"},
+		// i=18
+		{inst: &ir.ExtractvalueInst{}, want: "extractvalue"},
+		// i=19
+		{inst: &ir.InsertvalueInst{}, want: "insertvalue"},
+		// i=20
+		{inst: &ir.AllocaInst{}, want: "alloca"},
+		// i=21
+		{inst: &ir.LoadInst{}, want: "load"},
+		// i=22
+		{inst: &ir.StoreInst{}, want: "store"},
+		// i=23
+		{inst: &ir.FenceInst{}, want: "fence"},
+		// i=24
+		{inst: &ir.GetelementptrInst{}, want: "getelementptr"},
+		// i=25
+		{inst: &ir.TruncInst{}, want: "trunc"},
+		// i=26
+		{inst: &ir.ZextInst{}, want: "zext"},
+		// i=27
+		{inst: &ir.SextInst{}, want: "sext"},
+		// i=28
+		{inst: &ir.FptruncInst{}, want: "fptrunc"},
+		// i=29
+		{inst: &ir.FpextInst{}, want: "fpext"},
+		// i=30
+		{inst: &ir.FptouiInst{}, want: "fptoui"},
+		// i=31
+		{inst: &ir.FptosiInst{}, want: "fptosi"},
+		// i=32
+		{inst: &ir.UitofpInst{}, want: "uitofp"},
+		// i=33
+		{inst: &ir.SitofpInst{}, want: "sitofp"},
+		// i=34
+		{inst: &ir.PtrtointInst{}, want: "ptrtoint"},
+		// i=35
+		{inst: &ir.InttoptrInst{}, want: "inttoptr"},
+		// i=36
+		{inst: &ir.BitcastInst{}, want: "bitcast"},
+		// i=37
+		{inst: &ir.AddrspacecastInst{}, want: "addrspacecast"},
+		// i=38
+		{inst: &ir.IcmpInst{}, want: "icmp"},
+		// i=39
+		{inst: &ir.FcmpInst{}, want: "fcmp"},
+		// i=40
+		{inst: &ir.PhiInst{}, want: "phi"},
+		// i=41
+		{inst: &ir.SelectInst{}, want: "select"},
+		// i=42
+		{inst: &ir.CallInst{}, want: "call"},
+		// i=43
+		{inst: &ir.LandingpadInst{}, want: "landingpad"},
+	}
+
+	for i, g := range golden {
+		if got := ir.Opcode(g.inst); got != g.want {
+			t.Errorf("i=%d: opcode mismatch; expected %q, got %q", i, g.want, got)
+		}
+	}
+}
+
+func TestTermOpcode(t *testing.T) {
+	golden := []struct {
+		term ir.Terminator
+		want string
+	}{
+		// i=0
+		{term: &ir.ReturnInst{}, want: "ret"},
+		// i=1
+		{term: &ir.CondBranchInst{}, want: "br"},
+		// i=2
+		{term: &ir.BranchInst{}, want: "br"},
+		// i=3
+		{term: &ir.SwitchInst{}, want: "switch"},
+		// i=4
+		{term: &ir.ResumeInst{}, want: "resume"},
+		// i=5
+		{term: &ir.IndirectbrInst{}, want: "indirectbr"},
+		// i=6
+		{term: &ir.UnreachableInst{}, want: "unreachable"},
+		// i=7
+		{term: &ir.InvokeInst{}, want: "invoke"},
+	}
+
+	for i, g := range golden {
+		if got := ir.TermOpcode(g.term); got != g.want {
+			t.Errorf("i=%d: opcode mismatch; expected %q, got %q", i, g.want, got)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	golden := []struct {
+		inst                                ir.Instruction
+		binary, bitwise, memory, conversion bool
+	}{
+		// i=0
+		{inst: &ir.AddInst{}, binary: true},
+		// i=1
+		{inst: &ir.XorInst{}, bitwise: true},
+		// i=2
+		{inst: &ir.LoadInst{}, memory: true},
+		// i=3
+		{inst: &ir.TruncInst{}, conversion: true},
+		// i=4
+		{inst: &ir.CallInst{}},
+	}
+
+	for i, g := range golden {
+		if got := ir.IsBinaryOp(g.inst); got != g.binary {
+			t.Errorf("i=%d: binary operation mismatch; expected %v, got %v", i, g.binary, got)
+		}
+		if got := ir.IsBitwiseOp(g.inst); got != g.bitwise {
+			t.Errorf("i=%d: bitwise operation mismatch; expected %v, got %v", i, g.bitwise, got)
+		}
+		if got := ir.IsMemoryOp(g.inst); got != g.memory {
+			t.Errorf("i=%d: memory operation mismatch; expected %v, got %v", i, g.memory, got)
+		}
+		if got := ir.IsConversion(g.inst); got != g.conversion {
+			t.Errorf("i=%d: conversion mismatch; expected %v, got %v", i, g.conversion, got)
+		}
+	}
+
+	if !ir.IsTerminator(&ir.UnreachableInst{}) {
+		t.Errorf("expected unreachable to be a terminator")
+	}
+	if ir.IsTerminator(nil) {
+		t.Errorf("expected nil not to be a terminator")
+	}
+}
+
+func TestBuildUseGraph(t *testing.T) {
+	//    %1 = add i32 %x, 42
+	//    %2 = add i32 %1, %1
+	//    ret i32 %2
+	add1 := &ir.AddInst{LocalIdent: ir.LocalIdent{Name: "1"}, Type: i32Typ, Op1: i32X, Op2: i32FortyTwo}
+	one, err := ir.NewLocal(add1, i32Typ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	add2 := &ir.AddInst{LocalIdent: ir.LocalIdent{Name: "2"}, Type: i32Typ, Op1: one, Op2: one}
+	two, err := ir.NewLocal(add2, i32Typ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := &ir.BasicBlock{Name: "0"}
+	entry.AppendInst(add1)
+	entry.AppendInst(add2)
+	entry.SetTerm(ir.NewRet(two))
+	f := &ir.Function{Name: "f"}
+	f.AppendBlock(entry)
+
+	g := ir.BuildUseGraph(f)
+	users := g.Users(one)
+	if len(users) != 1 || users[0] != add2 {
+		t.Fatalf("users mismatch; expected [%p], got %v", add2, users)
+	}
+	ops := g.Operands(users[0])
+	if len(ops) != 2 || ops[0] != one || ops[1] != one {
+		t.Errorf("operands mismatch; expected [%v, %v], got %v", one, one, ops)
+	}
+	if def := g.Defs(one); def != add1 {
+		t.Errorf("definition mismatch; expected %p, got %p", add1, def)
+	}
+	if def := g.Defs(i32X); def != nil {
+		t.Errorf("definition mismatch; expected nil, got %p", def)
+	}
+	if users := g.ResultUsers(add1); len(users) != 1 || users[0] != add2 {
+		t.Errorf("result users mismatch; expected [%p], got %v", add2, users)
+	}
+	// Locals are resolved by name; a separately created local referring to the
+	// result of add1 has the same users.
+	if users := g.Users(values.NewLocal("1", i32Typ)); len(users) != 1 || users[0] != add2 {
+		t.Errorf("users mismatch; expected [%p], got %v", add2, users)
+	}
+}
+
+func TestBuildUseGraphSeparateLocal(t *testing.T) {
+	// Each use refers to the result through a separate *values.Local.
+	//    %1 = add i32 %x, 42
+	//    %2 = mul i32 %1, 42
+	//    %3 = sub i32 %1, %x
+	//    ret i32 %3
+	add := &ir.AddInst{LocalIdent: ir.LocalIdent{Name: "1"}, Type: i32Typ, Op1: i32X, Op2: i32FortyTwo}
+	mul := &ir.MulInst{LocalIdent: ir.LocalIdent{Name: "2"}, Type: i32Typ, Op1: values.NewLocal("1", i32Typ), Op2: i32FortyTwo}
+	sub := &ir.SubInst{LocalIdent: ir.LocalIdent{Name: "3"}, Type: i32Typ, Op1: values.NewLocal("1", i32Typ), Op2: i32X}
+	entry := &ir.BasicBlock{Name: "0"}
+	entry.AppendInst(add)
+	entry.AppendInst(mul)
+	entry.AppendInst(sub)
+	entry.SetTerm(ir.NewRet(values.NewLocal("3", i32Typ)))
+	f := &ir.Function{Name: "f"}
+	f.AppendBlock(entry)
+
+	g := ir.BuildUseGraph(f)
+	users := g.ResultUsers(add)
+	if len(users) != 2 || users[0] != mul || users[1] != sub {
+		t.Errorf("result users mismatch; expected [%p %p], got %v", mul, sub, users)
+	}
+	if def := g.Defs(values.NewLocal("1", i32Typ)); def != add {
+		t.Errorf("definition mismatch; expected %p, got %p", add, def)
+	}
+	if users := g.ResultUsers(mul); len(users) != 0 {
+		t.Errorf("result users mismatch; expected none, got %v", users)
+	}
+}
+
+func TestComputeRanks(t *testing.T) {
+	//    define i32 @f(i32 %a, i32 %b) {
+	//    entry:
+	//       %1 = add i32 %a, 42
+	//       store i32 %1, i32* %q
+	//       %2 = mul i32 %1, %b
+	//       ret i32 %2
+	//    }
+	sig, err := types.NewFunc(i32Typ, []types.Type{i32Typ, i32Typ}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := values.NewParam("a", i32Typ), values.NewParam("b", i32Typ)
+	one, two := values.NewLocal("1", i32Typ), values.NewLocal("2", i32Typ)
+	entry := &ir.BasicBlock{Name: "entry"}
+	entry.AppendInst(&ir.AddInst{LocalIdent: ir.LocalIdent{Name: "1"}, Type: i32Typ, Op1: a, Op2: i32FortyTwo})
+	entry.AppendInst(&ir.StoreInst{Type: i32Typ, Val: one, Addr: i32PtrQ})
+	entry.AppendInst(&ir.MulInst{LocalIdent: ir.LocalIdent{Name: "2"}, Type: i32Typ, Op1: one, Op2: b})
+	entry.SetTerm(ir.NewRet(two))
+	f := &ir.Function{Name: "f", Sig: sig, Params: []*values.Param{a, b}}
+	f.AppendBlock(entry)
+
+	r := ir.ComputeRanks(f)
+	golden := []struct {
+		v    values.Value
+		want uint64
+	}{
+		// i=0
+		{v: i32FortyTwo, want: 0},
+		// i=1
+		{v: funcF, want: 0},
+		// i=2
+		{v: a, want: 1},
+		// i=3
+		{v: values.NewLocal("b", i32Typ), want: 2},
+		// i=4
+		{v: one, want: 3},
+		// i=5
+		{v: two, want: 4},
+		// i=6
+		{v: values.NewLocal("undefined", i32Typ), want: 0},
+	}
+	for i, g := range golden {
+		if got := r.Rank(g.v); got != g.want {
+			t.Errorf("i=%d: rank mismatch of %v; expected %d, got %d", i, g.v.Ident(), g.want, got)
+		}
+	}
+}
+
+func TestDeadCodeElim(t *testing.T) {
+	//    %a = add i32 %x, 42
+	//    %b = add i32 %a, %a
+	//    %c = mul i32 %x, 42
+	//    store i32 %x, i32* %q
+	//    ret i32 %c
+	deadA := &ir.AddInst{LocalIdent: ir.LocalIdent{Name: "a"}, Type: i32Typ, Op1: i32X, Op2: i32FortyTwo}
+	a, err := ir.NewLocal(deadA, i32Typ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadB := &ir.AddInst{LocalIdent: ir.LocalIdent{Name: "b"}, Type: i32Typ, Op1: a, Op2: a}
+	mul := &ir.MulInst{LocalIdent: ir.LocalIdent{Name: "c"}, Type: i32Typ, Op1: i32X, Op2: i32FortyTwo}
+	c, err := ir.NewLocal(mul, i32Typ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &ir.StoreInst{Type: i32Typ, Val: i32X, Addr: i32PtrQ}
+	entry := &ir.BasicBlock{Name: "entry"}
+	entry.AppendInst(deadA)
+	entry.AppendInst(deadB)
+	entry.AppendInst(mul)
+	entry.AppendInst(store)
+	entry.SetTerm(ir.NewRet(c))
+	f := &ir.Function{Name: "f"}
+	f.AppendBlock(entry)
+
+	if got, want := ir.DeadCodeElim(f), 2; got != want {
+		t.Errorf("removed instruction count mismatch; expected %d, got %d", want, got)
+	}
+	if len(entry.Insts) != 2 || entry.Insts[0] != mul || entry.Insts[1] != store {
+		t.Errorf("instructions mismatch; expected [%p %p], got %v", mul, store, entry.Insts)
+	}
+	if entry.Term == nil {
+		t.Errorf("terminator removed")
+	}
+	if got := ir.DeadCodeElim(f); got != 0 {
+		t.Errorf("removed instruction count mismatch; expected 0, got %d", got)
+	}
+}
+
+func TestDeadCodeElimSharedName(t *testing.T) {
+	// Two instructions share the name %a; the return refers to the closest
+	// preceding definition, rendering the first one dead.
+	//    %a = add i32 %x, 42
+	//    %a = mul i32 %x, 42
+	//    ret i32 %a
+	dead := &ir.AddInst{LocalIdent: ir.LocalIdent{Name: "a"}, Type: i32Typ, Op1: i32X, Op2: i32FortyTwo}
+	mul := &ir.MulInst{LocalIdent: ir.LocalIdent{Name: "a"}, Type: i32Typ, Op1: i32X, Op2: i32FortyTwo}
+	entry := &ir.BasicBlock{Name: "entry"}
+	entry.AppendInst(dead)
+	entry.AppendInst(mul)
+	entry.SetTerm(ir.NewRet(values.NewLocal("a", i32Typ)))
+	f := &ir.Function{Name: "f"}
+	f.AppendBlock(entry)
+
+	if got, want := ir.DeadCodeElim(f), 1; got != want {
+		t.Errorf("removed instruction count mismatch; expected %d, got %d", want, got)
+	}
+	if len(entry.Insts) != 1 || entry.Insts[0] != mul {
+		t.Errorf("instructions mismatch; expected [%p], got %v", mul, entry.Insts)
+	}
+}
+
+func TestValidateSSA(t *testing.T) {
+	sig, err := types.NewFunc(i32Typ, []types.Type{i32Typ}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := []*values.Param{values.NewParam("x", i32Typ)}
+	y := values.NewLocal("y", i32Typ)
+	z := values.NewLocal("z", i32Typ)
+
+	// Use before definition.
+	//    %y = add i32 %z, 42
+	//    %z = add i32 %x, 42
+	//    ret i32 %y
+	useBeforeDef := &ir.BasicBlock{Name: "entry"}
+	useBeforeDef.AppendInst(&ir.AddInst{LocalIdent: ir.LocalIdent{Name: "y"}, Type: i32Typ, Op1: z, Op2: i32FortyTwo})
+	useBeforeDef.AppendInst(&ir.AddInst{LocalIdent: ir.LocalIdent{Name: "z"}, Type: i32Typ, Op1: i32X, Op2: i32FortyTwo})
+	useBeforeDef.SetTerm(ir.NewRet(y))
+	f := &ir.Function{Name: "f", Sig: sig, Params: params}
+	f.AppendBlock(useBeforeDef)
+
+	// Redefinition.
+	//    %y = add i32 %x, 42
+	//    %y = add i32 %x, %x
+	//    ret i32 %y
+	redef := &ir.BasicBlock{Name: "entry"}
+	redef.AppendInst(&ir.AddInst{LocalIdent: ir.LocalIdent{Name: "y"}, Type: i32Typ, Op1: i32X, Op2: i32FortyTwo})
+	redef.AppendInst(&ir.AddInst{LocalIdent: ir.LocalIdent{Name: "y"}, Type: i32Typ, Op1: i32X, Op2: i32X})
+	redef.SetTerm(ir.NewRet(y))
+	g := &ir.Function{Name: "g", Sig: sig, Params: params}
+	g.AppendBlock(redef)
+
+	// Loop with a phi instruction using a value defined in the loop body.
+	//    entry:
+	//       br label %loop
+	//    loop:
+	//       %y = phi i32 [ %x, %entry ], [ %z, %loop ]
+	//       %z = add i32 %y, 42
+	//       br label %loop
+	entry := &ir.BasicBlock{Name: "entry"}
+	loop := &ir.BasicBlock{Name: "loop"}
+	entry.SetTerm(ir.NewBr(loop))
+	phi := &ir.PhiInst{LocalIdent: ir.LocalIdent{Name: "y"}, Type: i32Typ}
+	phi.AddIncoming(entry, i32X)
+	phi.AddIncoming(loop, z)
+	loop.AppendInst(phi)
+	loop.AppendInst(&ir.AddInst{LocalIdent: ir.LocalIdent{Name: "z"}, Type: i32Typ, Op1: y, Op2: i32FortyTwo})
+	loop.SetTerm(ir.NewBr(loop))
+	h := &ir.Function{Name: "h", Sig: sig, Params: params}
+	h.AppendBlock(entry)
+	h.AppendBlock(loop)
+
+	golden := []struct {
+		f    *ir.Function
+		errs []string
+	}{
+		// i=0
+		{
+			f:    f,
+			errs: []string{`%entry: use of %z not dominated by its definition in "%y = add i32 %z, 42"`},
+		},
+		// i=1
+		{
+			f:    g,
+			errs: []string{`%entry: redefinition of %y by "%y = add i32 %x, %x"`},
+		},
+		// i=2
+		{
+			f: h,
+		},
+	}
+
+	for i, g := range golden {
+		errs := ir.ValidateSSA(g.f)
+		if len(errs) != len(g.errs) {
+			t.Errorf("i=%d: error count mismatch; expected %d, got %d (%v)", i, len(g.errs), len(errs), errs)
+			continue
+		}
+		for j, err := range errs {
+			if !sameError(err, g.errs[j]) {
+				t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.errs[j], err)
+			}
+		}
+	}
+}
+
+func TestInstString(t *testing.T) {
+	i8A := &local{name: "a", typ: i8Typ}
+	structPtrTyp, err := types.NewPointer(i8Ptri32StructTyp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	structPtrP := &local{name: "p", typ: structPtrTyp}
+	golden := []struct {
+		inst fmt.Stringer
+		want string
+	}{
+		// i=0
+		{
+			inst: &ir.TruncInst{LocalIdent: ir.LocalIdent{Name: "y"}, From: i32X, To: i8Typ},
+			want: "%y = trunc i32 %x to i8",
+		},
+		// i=1
+		{
+			inst: &ir.ZextInst{LocalIdent: ir.LocalIdent{Name: "y"}, From: i8A, To: i32Typ},
+			want: "%y = zext i8 %a to i32",
+		},
+		// i=2
+		{
+			inst: &ir.SextInst{LocalIdent: ir.LocalIdent{Name: "y"}, From: i8A, To: i32Typ},
+			want: "%y = sext i8 %a to i32",
+		},
+		// i=3
+		{
+			inst: &ir.FptruncInst{LocalIdent: ir.LocalIdent{Name: "y"}, From: f64Three, To: f32Typ},
+			want: "%y = fptrunc double 3.0 to float",
+		},
+		// i=4
+		{
+			inst: &ir.FpextInst{LocalIdent: ir.LocalIdent{Name: "y"}, From: f32Three, To: f64Typ},
+			want: "%y = fpext float 3.0 to double",
+		},
+		// i=5
+		{
+			inst: &ir.FptouiInst{LocalIdent: ir.LocalIdent{Name: "y"}, From: f64Three, To: i32Typ},
+			want: "%y = fptoui double 3.0 to i32",
+		},
+		// i=6
+		{
+			inst: &ir.FptosiInst{LocalIdent: ir.LocalIdent{Name: "y"}, From: f64Three, To: i32Typ},
+			want: "%y = fptosi double 3.0 to i32",
+		},
+		// i=7
+		{
+			inst: &ir.UitofpInst{LocalIdent: ir.LocalIdent{Name: "y"}, From: i32X, To: f64Typ},
+			want: "%y = uitofp i32 %x to double",
+		},
+		// i=8
+		{
+			inst: &ir.SitofpInst{LocalIdent: ir.LocalIdent{Name: "y"}, From: i32X, To: f64Typ},
+			want: "%y = sitofp i32 %x to double",
+		},
+		// i=9
+		{
+			inst: &ir.PtrtointInst{LocalIdent: ir.LocalIdent{Name: "y"}, From: i8PtrP, To: i64Typ},
+			want: "%y = ptrtoint i8* %p to i64",
+		},
+		// i=10
+		{
+			inst: &ir.InttoptrInst{LocalIdent: ir.LocalIdent{Name: "y"}, From: i64FortyTwo, To: i8PtrTyp},
+			want: "%y = inttoptr i64 42 to i8*",
+		},
+		// i=11
+		{
+			inst: &ir.BitcastInst{LocalIdent: ir.LocalIdent{Name: "y"}, From: i8PtrP, To: i32PtrTyp},
+			want: "%y = bitcast i8* %p to i32*",
+		},
+		// i=12
+		{
+			inst: &ir.AddrspacecastInst{LocalIdent: ir.LocalIdent{Name: "y"}, From: i8PtrP, To: i8Ptr1Typ},
+			want: "%y = addrspacecast i8* %p to i8 addrspace(1)*",
+		},
+		// i=13
+		{
+			inst: &ir.SelectInst{LocalIdent: ir.LocalIdent{Name: "y"}, Cond: i1Cond, Type: i32Typ, TrueValue: i32X, FalseValue: i32FortyTwo},
+			want: "%y = select i1 %cond, i32 %x, i32 42",
+		},
+		// i=14
+		{
+			inst: &ir.ExtractvalueInst{LocalIdent: ir.LocalIdent{Name: "y"}, Type: i8Typ, Aggregate: i8i8i32StructN, Indices: []int{0, 1}},
+			want: "%y = extractvalue {{i8, i8}, i32} %n, 0, 1",
+		},
+		// i=15
+		{
+			inst: &ir.InsertvalueInst{LocalIdent: ir.LocalIdent{Name: "y"}, Aggregate: i32f32StructS, Element: i32FortyTwo, Indices: []int{0}},
+			want: "%y = insertvalue {i32, float} %s, i32 42, 0",
+		},
+		// i=16
+		{
+			inst: &ir.GetelementptrInst{LocalIdent: ir.LocalIdent{Name: "y"}, Type: i8Ptri32StructTyp, Ptr: structPtrP, Indicies: []int{0, 1}},
+			want: "%y = getelementptr {i8*, i32}, {i8*, i32}* %p, i32 0, i32 1",
+		},
+		// i=17
+		{
+			inst: &ir.GetelementptrInst{LocalIdent: ir.LocalIdent{Name: "y"}, Type: i8Ptri32StructTyp, Ptr: structPtrP, Indicies: []int{0, 1}, InBounds: true},
+			want: "%y = getelementptr inbounds {i8*, i32}, {i8*, i32}* %p, i32 0, i32 1",
+		},
+		// i=18
+		{
+			inst: &ir.FenceInst{Ordering: ir.AtomicAcquire},
+			want: "fence acquire",
+		},
+		// i=19
+		{
+			inst: &ir.FenceInst{Ordering: ir.AtomicSeqCst, SyncScope: "singlethread"},
+			want: `fence syncscope("singlethread") seq_cst`,
+		},
+	}
+
+	for i, g := range golden {
+		got := g.inst.String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %q, got %q", i, g.want, got)
+		}
+	}
+}
+
+func TestBinaryString(t *testing.T) {
+	f32A := &local{name: "a", typ: f32Typ}
+	golden := []struct {
+		inst fmt.Stringer
+		want string
+	}{
+		// i=0
+		{
+			inst: &ir.AddInst{Type: i32Typ, Op1: i32X, Op2: i32FortyTwo},
+			want: "add i32 %x, 42",
+		},
+		// i=1
+		{
+			inst: &ir.FaddInst{Type: f32Typ, Op1: f32A, Op2: f32Three},
+			want: "fadd float %a, 3.0",
+		},
+		// i=2
+		{
+			inst: &ir.SubInst{Type: i32Typ, Op1: i32X, Op2: i32FortyTwo},
+			want: "sub i32 %x, 42",
+		},
+		// i=3
+		{
+			inst: &ir.FsubInst{Type: f32Typ, Op1: f32A, Op2: f32Three},
+			want: "fsub float %a, 3.0",
+		},
+		// i=4
+		{
+			inst: &ir.MulInst{Type: i32Typ, Op1: i32X, Op2: i32FortyTwo},
+			want: "mul i32 %x, 42",
+		},
+		// i=5
+		{
+			inst: &ir.FmulInst{Type: f32Typ, Op1: f32A, Op2: f32Three},
+			want: "fmul float %a, 3.0",
+		},
+		// i=6
+		{
+			inst: &ir.UdivInst{Type: i32Typ, Op1: i32X, Op2: i32FortyTwo},
+			want: "udiv i32 %x, 42",
+		},
+		// i=7
+		{
+			inst: &ir.SdivInst{Type: i32Typ, Op1: i32X, Op2: i32FortyTwo},
+			want: "sdiv i32 %x, 42",
+		},
+		// i=8
+		{
+			inst: &ir.FdivInst{Type: f32Typ, Op1: f32A, Op2: f32Three},
+			want: "fdiv float %a, 3.0",
+		},
+		// i=9
+		{
+			inst: &ir.UremInst{Type: i32Typ, Op1: i32X, Op2: i32FortyTwo},
+			want: "urem i32 %x, 42",
+		},
+		// i=10
+		{
+			inst: &ir.SremInst{Type: i32Typ, Op1: i32X, Op2: i32FortyTwo},
+			want: "srem i32 %x, 42",
+		},
+		// i=11
+		{
+			inst: &ir.FremInst{Type: f32Typ, Op1: f32A, Op2: f32Three},
+			want: "frem float %a, 3.0",
+		},
+		// i=12
+		{
+			inst: &ir.ShlInst{Type: i32Typ, Op1: i32X, Op2: i32FortyTwo},
+			want: "shl i32 %x, 42",
+		},
+		// i=13
+		{
+			inst: &ir.LshrInst{Type: i32Typ, Op1: i32X, Op2: i32FortyTwo},
+			want: "lshr i32 %x, 42",
+		},
+		// i=14
+		{
+			inst: &ir.AshrInst{Type: i32Typ, Op1: i32X, Op2: i32FortyTwo},
+			want: "ashr i32 %x, 42",
+		},
+		// i=15
+		{
+			inst: &ir.AndInst{Type: i32Typ, Op1: i32X, Op2: i32FortyTwo},
+			want: "and i32 %x, 42",
+		},
+		// i=16
+		{
+			inst: &ir.OrInst{Type: i32Typ, Op1: i32X, Op2: i32FortyTwo},
+			want: "or i32 %x, 42",
+		},
+		// i=17
+		{
+			inst: &ir.XorInst{Type: i32Typ, Op1: i32X, Op2: i32FortyTwo},
+			want: "xor i32 %x, 42",
+		},
+		// i=18
+		{
+			inst: &ir.AddInst{Type: i32x2VecTyp, Op1: i32x2VecThreeFortyTwo, Op2: i32x2VecThreeFortyTwo},
+			want: "add <2 x i32> <i32 3, i32 42>, <i32 3, i32 42>",
+		},
+	}
+
+	for i, g := range golden {
+		got := g.inst.String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %v, got %v", i, g.want, got)
+		}
+	}
+}
+
+func TestOverflowFlagsString(t *testing.T) {
+	golden := []struct {
+		inst fmt.Stringer
+		want string
+	}{
+		// i=0
+		{
+			inst: &ir.AddInst{Type: i32Typ, Op1: i32X, Op2: i32FortyTwo, NUW: true, NSW: true},
+			want: "add nuw nsw i32 %x, 42",
+		},
+		// i=1
+		{
+			inst: &ir.SubInst{Type: i32Typ, Op1: i32X, Op2: i32FortyTwo, NSW: true},
+			want: "sub nsw i32 %x, 42",
+		},
+		// i=2
+		{
+			inst: &ir.MulInst{LocalIdent: ir.LocalIdent{Name: "y"}, Type: i32Typ, Op1: i32X, Op2: i32FortyTwo, NUW: true},
+			want: "%y = mul nuw i32 %x, 42",
+		},
+		// i=3
+		{
+			inst: &ir.ShlInst{Type: i32x4VecTyp, Op1: i32x4VecA, Op2: i32x4VecB, NUW: true, NSW: true},
+			want: "shl nuw nsw <4 x i32> %a, %b",
+		},
+		// i=4
+		{
+			inst: &ir.AddInst{Type: i32Typ, Op1: i32X, Op2: i32FortyTwo},
+			want: "add i32 %x, 42",
+		},
+	}
+
+	for i, g := range golden {
+		got := g.inst.String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %q, got %q", i, g.want, got)
+		}
+	}
+}
+
+func TestSetOverflowFlags(t *testing.T) {
+	f32A := &local{name: "a", typ: f32Typ}
+	golden := []struct {
+		inst     ir.Instruction
+		nuw, nsw bool
+		want     string
+		err      string
+	}{
+		// i=0
+		{
+			inst: &ir.AddInst{Type: i32Typ, Op1: i32X, Op2: i32FortyTwo}, nuw: true, nsw: true,
+			want: "add nuw nsw i32 %x, 42",
+		},
+		// i=1
+		{
+			inst: &ir.ShlInst{Type: i32Typ, Op1: i32X, Op2: i32FortyTwo, NUW: true}, nsw: true,
+			want: "shl nsw i32 %x, 42",
+		},
+		// i=2
+		{
+			inst: &ir.FaddInst{Type: f32Typ, Op1: f32A, Op2: f32Three}, nuw: true,
+			err: "invalid overflow flags; fadd instruction does not support nuw and nsw",
+		},
+		// i=3
+		{
+			inst: &ir.UdivInst{Type: i32Typ, Op1: i32X, Op2: i32FortyTwo}, nsw: true,
+			err: "invalid overflow flags; udiv instruction does not support nuw and nsw",
+		},
+	}
+
+	for i, g := range golden {
+		err := ir.SetOverflowFlags(g.inst, g.nuw, g.nsw)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		got := g.inst.(fmt.Stringer).String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %q, got %q", i, g.want, got)
+		}
+	}
+}
+
+func TestExactFlagString(t *testing.T) {
+	golden := []struct {
+		inst fmt.Stringer
+		want string
+	}{
+		// i=0
+		{
+			inst: &ir.SdivInst{Type: i32Typ, Op1: i32X, Op2: i32FortyTwo, Exact: true},
+			want: "sdiv exact i32 %x, 42",
+		},
+		// i=1
+		{
+			inst: &ir.UdivInst{LocalIdent: ir.LocalIdent{Name: "y"}, Type: i32Typ, Op1: i32X, Op2: i32FortyTwo, Exact: true},
+			want: "%y = udiv exact i32 %x, 42",
+		},
+		// i=2
+		{
+			inst: &ir.LshrInst{Type: i32x4VecTyp, Op1: i32x4VecA, Op2: i32x4VecB, Exact: true},
+			want: "lshr exact <4 x i32> %a, %b",
+		},
+		// i=3
+		{
+			inst: &ir.AshrInst{Type: i32Typ, Op1: i32X, Op2: i32FortyTwo, Exact: true},
+			want: "ashr exact i32 %x, 42",
+		},
+		// i=4
+		{
+			inst: &ir.SdivInst{Type: i32Typ, Op1: i32X, Op2: i32FortyTwo},
+			want: "sdiv i32 %x, 42",
+		},
+		// i=5
+		{
+			// fdiv has no exact flag.
+			inst: &ir.FdivInst{Type: f32Typ, Op1: f32Three, Op2: f32Three},
+			want: "fdiv float 3.0, 3.0",
+		},
+	}
+
+	for i, g := range golden {
+		got := g.inst.String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %q, got %q", i, g.want, got)
+		}
+	}
+}
+
+func TestSetExact(t *testing.T) {
+	golden := []struct {
+		inst ir.Instruction
+		want string
+		err  string
+	}{
+		// i=0
+		{
+			inst: &ir.SdivInst{Type: i32Typ, Op1: i32X, Op2: i32FortyTwo},
+			want: "sdiv exact i32 %x, 42",
+		},
+		// i=1
+		{
+			inst: &ir.AshrInst{Type: i32Typ, Op1: i32X, Op2: i32FortyTwo},
+			want: "ashr exact i32 %x, 42",
+		},
+		// i=2
+		{
+			inst: &ir.FdivInst{Type: f32Typ, Op1: f32Three, Op2: f32Three},
+			err:  "invalid exact flag; fdiv instruction does not support exact",
+		},
+		// i=3
+		{
+			inst: &ir.ShlInst{Type: i32Typ, Op1: i32X, Op2: i32FortyTwo},
+			err:  "invalid exact flag; shl instruction does not support exact",
+		},
+	}
+
+	for i, g := range golden {
+		err := ir.SetExact(g.inst, true)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		got := g.inst.(fmt.Stringer).String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %q, got %q", i, g.want, got)
+		}
+	}
+}
+
+func TestFastMathFlagsString(t *testing.T) {
+	f32A := &local{name: "a", typ: f32Typ}
+	var nnanNinf ir.FastMathFlags
+	nnanNinf.Set(ir.FastNoInfs)
+	nnanNinf.Set(ir.FastNoNaNs)
+	golden := []struct {
+		inst fmt.Stringer
+		want string
+	}{
+		// i=0
+		{
+			inst: &ir.FaddInst{Type: f32Typ, Op1: f32A, Op2: f32Three, FastMath: ir.Fast},
+			want: "fadd fast float %a, 3.0",
+		},
+		// i=1
+		{
+			inst: &ir.FmulInst{LocalIdent: ir.LocalIdent{Name: "y"}, Type: f32Typ, Op1: f32A, Op2: f32Three, FastMath: nnanNinf},
+			want: "%y = fmul nnan ninf float %a, 3.0",
+		},
+		// i=2
+		{
+			inst: &ir.FdivInst{Type: f32Typ, Op1: f32A, Op2: f32Three, FastMath: ir.FastAllowRecip | ir.FastReassoc},
+			want: "fdiv reassoc arcp float %a, 3.0",
+		},
+		// i=3
+		{
+			inst: &ir.FsubInst{Type: f32Typ, Op1: f32A, Op2: f32Three, FastMath: ir.FastNoSignedZeros | ir.FastContract | ir.FastApproxFunc},
+			want: "fsub nsz contract afn float %a, 3.0",
+		},
+		// i=4
+		{
+			inst: &ir.FremInst{Type: f32Typ, Op1: f32A, Op2: f32Three},
+			want: "frem float %a, 3.0",
+		},
+	}
+
+	for i, g := range golden {
+		got := g.inst.String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %q, got %q", i, g.want, got)
+		}
+	}
+
+	if !ir.Fast.Has(nnanNinf) {
+		t.Errorf("expected %q to include %q", ir.Fast, nnanNinf)
+	}
+	if nnanNinf.Has(ir.FastNoNaNs | ir.FastNoSignedZeros) {
+		t.Errorf("expected %q to not include nsz", nnanNinf)
+	}
+}
+
+func TestBasicBlockAssignIDs(t *testing.T) {
+	named := &ir.AddInst{Type: i32Typ, Op1: i32X, Op2: i32FortyTwo}
+	named.Name = "sum"
+	add := &ir.AddInst{Type: i32Typ, Op1: i32X, Op2: i32FortyTwo}
+	sub := &ir.SubInst{Type: i32Typ, Op1: i32X, Op2: i32FortyTwo}
+	call, err := ir.NewCall(funcG, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mul := &ir.MulInst{Type: i32Typ, Op1: i32X, Op2: i32FortyTwo}
+	block := &ir.BasicBlock{Insts: []ir.Instruction{named, add, call, sub, mul}}
+	block.SetTerm(ir.NewRet(nil))
+
+	// Number the basic block and its unnamed instructions, skipping named and
+	// void instructions.
+	if got, want := block.AssignIDs(0), 4; got != want {
+		t.Errorf("next id mismatch; expected %d, got %d", want, got)
+	}
+	golden := []struct {
+		got, want string
+	}{
+		// i=0
+		{got: block.Name, want: "0"},
+		// i=1
+		{got: named.String(), want: "%sum = add i32 %x, 42"},
+		// i=2
+		{got: add.String(), want: "%1 = add i32 %x, 42"},
+		// i=3
+		{got: call.Name, want: ""},
+		// i=4
+		{got: sub.String(), want: "%2 = sub i32 %x, 42"},
+		// i=5
+		{got: mul.String(), want: "%3 = mul i32 %x, 42"},
+	}
+	for i, g := range golden {
+		if g.got != g.want {
+			t.Errorf("i=%d: name mismatch; expected %q, got %q", i, g.want, g.got)
+		}
+	}
+
+	// Numbering is stable; already named instructions are left untouched.
+	if got, want := block.AssignIDs(4), 4; got != want {
+		t.Errorf("next id mismatch; expected %d, got %d", want, got)
+	}
+	if got, want := add.Name, "1"; got != want {
+		t.Errorf("name mismatch; expected %q, got %q", want, got)
+	}
+}
+
+// sameError returns true if err is represented by the string s, and false
+// otherwise. Some error messages contains suffixes from external functions,
+// e.g. the strconv error in:
+//
+//    unable to parse integer constant "foo"; strconv.ParseInt: parsing "foo": invalid syntax`
+//
+// To avoid depending on the error of external functions, s matches the error if
+// it is a non-empty prefix of err.
+func sameError(err error, s string) bool {
+	t := ""
+	if err != nil {
+		if len(s) == 0 {
+			return false
+		}
+		t = err.Error()
+	}
+	return strings.HasPrefix(t, s)
+}
@@ -1,4 +1,272 @@
 package ir
 
-type Metadata struct {
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/llir/llvm/consts"
+	"github.com/llir/llvm/types"
+	"github.com/llir/llvm/values"
+)
+
+// A MetadataNode is a numbered metadata node, which holds a tuple of metadata
+// fields.
+//
+// Examples:
+//    !0 = !{i32 2, !"Dwarf Version", i32 4}
+//    !1 = !{!0, null}
+//    !2 = distinct !{!2}
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#metadata
+type MetadataNode struct {
+	// Metadata node ID.
+	ID int
+	// Metadata fields; values, metadata strings or metadata nodes. A nil field
+	// represents null.
+	Fields []values.Value
+	// Specifies if the metadata node is distinct, and thus not merged with
+	// metadata nodes of identical content.
+	Distinct bool
+}
+
+// Type returns the type of the metadata node, which is metadata.
+func (node *MetadataNode) Type() types.Type {
+	return types.NewMetadata()
+}
+
+// Ident returns the identifier associated with the metadata node, e.g.
+//
+//    !0
+func (node *MetadataNode) Ident() string {
+	return fmt.Sprintf("!%d", node.ID)
+}
+
+// String returns a string representation of the fields of the metadata node,
+// e.g.
+//
+//    !{i32 2, !"Dwarf Version", i32 4}
+func (node *MetadataNode) String() string {
+	buf := new(bytes.Buffer)
+	if node.Distinct {
+		buf.WriteString("distinct ")
+	}
+	buf.WriteString("!{")
+	for i, field := range node.Fields {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		if field == nil {
+			buf.WriteString("null")
+			continue
+		}
+		if _, ok := field.Type().(*types.Metadata); ok {
+			// Metadata operands are printed without type.
+			buf.WriteString(field.Ident())
+			continue
+		}
+		buf.WriteString(field.String())
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
+
+// A MetadataString is a metadata string, e.g.
+//
+//    !"foo"
+type MetadataString string
+
+// Type returns the type of the metadata string, which is metadata.
+func (s MetadataString) Type() types.Type {
+	return types.NewMetadata()
+}
+
+// Ident returns the identifier associated with the metadata string, e.g.
+//
+//    !"foo"
+func (s MetadataString) Ident() string {
+	return "!" + quote(string(s))
+}
+
+// String returns a string representation of the metadata string, e.g.
+//
+//    !"foo"
+func (s MetadataString) String() string {
+	return s.Ident()
+}
+
+// Metadata maps from metadata kind (e.g. "dbg") to the metadata node attached
+// to an instruction.
+type Metadata map[string]*MetadataNode
+
+// attach returns the string representation s of an instruction, followed by its
+// metadata attachments in alphabetical order of their kind, e.g.
+//
+//    store i32 42, i32* %x, !dbg !3
+func (md Metadata) attach(s string) string {
+	if len(md) == 0 {
+		return s
+	}
+	var kinds []string
+	for kind := range md {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	buf := bytes.NewBufferString(s)
+	for _, kind := range kinds {
+		fmt.Fprintf(buf, ", !%s %s", kind, md[kind].Ident())
+	}
+	return buf.String()
+}
+
+// clone returns a copy of the metadata attachments.
+func (md Metadata) clone() Metadata {
+	if md == nil {
+		return nil
+	}
+	c := make(Metadata, len(md))
+	for kind, node := range md {
+		c[kind] = node
+	}
+	return c
+}
+
+// NewRange returns a new range metadata node of the given ID, which states that
+// the value of a load or call instruction is within the half-open interval
+// [lo, hi), e.g.
+//
+//    !0 = !{i8 0, i8 2}
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#range-metadata
+func NewRange(id int, lo, hi *consts.Int) (*MetadataNode, error) {
+	node := &MetadataNode{ID: id, Fields: []values.Value{lo, hi}}
+	if err := ValidateRange(lo.Type(), node); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// AttachRange attaches the range metadata node to the given load instruction or
+// call instruction of integer result type, e.g.
+//
+//    %tag = load i8, i8* %p, !range !0
+func AttachRange(inst Instruction, node *MetadataNode) error {
+	var md *Metadata
+	var typ types.Type
+	switch inst := inst.(type) {
+	case *LoadInst:
+		md, typ = &inst.Metadata, inst.Type
+	case *CallInst:
+		md, typ = &inst.Metadata, inst.Type
+	default:
+		return fmt.Errorf("invalid instruction %q for range metadata; expected load or call", Opcode(inst))
+	}
+	if err := ValidateRange(typ, node); err != nil {
+		return err
+	}
+	if *md == nil {
+		*md = make(Metadata)
+	}
+	(*md)["range"] = node
+	return nil
+}
+
+// ValidateRange verifies that the metadata node is a valid range of values of
+// the integer type typ; the node must hold a lower and an upper bound of type
+// typ, and the lower bound must be less than the upper bound.
+func ValidateRange(typ types.Type, node *MetadataNode) error {
+	if _, ok := typ.(*types.Int); !ok {
+		return fmt.Errorf("invalid range metadata of type %q; expected integer type", typ)
+	}
+	if len(node.Fields) != 2 {
+		return fmt.Errorf("invalid number of range metadata fields; expected 2, got %d", len(node.Fields))
+	}
+	var bounds [2]*consts.Int
+	for i, field := range node.Fields {
+		bound, ok := field.(*consts.Int)
+		if !ok || !bound.Type().Equal(typ) {
+			return fmt.Errorf("invalid range metadata bound %v; expected constant of type %q", field, typ)
+		}
+		bounds[i] = bound
+	}
+	lo, hi := bounds[0], bounds[1]
+	if lo.BigInt().Cmp(hi.BigInt()) >= 0 {
+		return fmt.Errorf("invalid range metadata [%s, %s); expected lower bound less than upper bound", lo.Ident(), hi.Ident())
+	}
+	return nil
+}
+
+// AttachVectorizeHints attaches loop metadata with vectorization hints to the
+// latch branch of the loop with the given header basic block, and appends the
+// metadata nodes to the module, numbered after its last metadata node. The
+// vectorization width hint is omitted if width is 0, e.g.
+//
+//    br i1 %c, label %loop, label %exit, !llvm.loop !0
+//
+//    !0 = distinct !{!0, !1, !2}
+//    !1 = !{!"llvm.loop.vectorize.enable", i1 true}
+//    !2 = !{!"llvm.loop.vectorize.width", i32 4}
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#llvm-loop
+func (module *Module) AttachVectorizeHints(header *BasicBlock, enable bool, width int) (*MetadataNode, error) {
+	if width < 0 {
+		return nil, fmt.Errorf("invalid vectorization width (%d); expected >= 0", width)
+	}
+	latch, err := LoopLatch(header)
+	if err != nil {
+		return nil, err
+	}
+	var md *Metadata
+	switch term := latch.Term.(type) {
+	case *BranchInst:
+		md = &term.Metadata
+	case *CondBranchInst:
+		md = &term.Metadata
+	default:
+		return nil, fmt.Errorf("invalid terminator %q of loop latch %s; expected br", TermOpcode(term), latch.Ident())
+	}
+
+	id := 0
+	for _, node := range module.Metadata {
+		if node.ID >= id {
+			id = node.ID + 1
+		}
+	}
+	loop := &MetadataNode{ID: id, Distinct: true}
+	// The first field of loop metadata refers to itself.
+	loop.Fields = append(loop.Fields, loop)
+	nodes := []*MetadataNode{loop}
+	// hint appends a metadata node !{!"name", val} to the loop metadata.
+	hint := func(name string, val values.Value) {
+		id++
+		node := &MetadataNode{ID: id, Fields: []values.Value{MetadataString(name), val}}
+		loop.Fields = append(loop.Fields, node)
+		nodes = append(nodes, node)
+	}
+	b := int64(0)
+	if enable {
+		b = 1
+	}
+	enableVal, err := consts.NewIntFromInt64(types.I1, b)
+	if err != nil {
+		return nil, err
+	}
+	hint("llvm.loop.vectorize.enable", enableVal)
+	if width > 0 {
+		widthVal, err := consts.NewIntFromInt64(types.I32, int64(width))
+		if err != nil {
+			return nil, err
+		}
+		hint("llvm.loop.vectorize.width", widthVal)
+	}
+
+	if *md == nil {
+		*md = make(Metadata)
+	}
+	(*md)["llvm.loop"] = loop
+	module.Metadata = append(module.Metadata, nodes...)
+	return loop, nil
 }
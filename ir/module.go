@@ -3,13 +3,15 @@ package ir
 import (
 	"bytes"
 	"fmt"
+	"sort"
+	"strings"
 
+	"github.com/llir/llvm/consts"
 	"github.com/llir/llvm/types"
-	"github.com/llir/llvm/values"
 )
 
-// TODO: Use map from Global/Local to *Function, Value, types.Type and *Metadata
-// instead of slice.
+// TODO: Use map from Global/Local to *Function, Value, types.Type and
+// *MetadataNode instead of slice.
 
 // A Module contains top-level function definitions, external function
 // declarations, global variables, type definitions and metadata.
@@ -17,21 +19,22 @@ import (
 // References:
 //    http://llvm.org/docs/LangRef.html#module-structure
 type Module struct {
-	// layout specifies how data is laid out in memory as a list of
+	// DataLayout specifies how data is laid out in memory as a list of
 	// specifications separated by the minus sign character (-). When
 	// constructing the data layout for a given target, LLVM starts with a
 	// default set of specifications which are then overridden by the
-	// specifications of layout.
+	// specifications of DataLayout. The data layout may be parsed using
+	// types.ParseDataLayout.
 	//
 	// Examples:
 	//    target datalayout = "e-m:e-i64:64-f80:128-n8:16:32:64-S128"
 	//
 	// References:
 	//    http://llvm.org/docs/LangRef.html#data-layout
-	layout string
-	// target describes the target host as a series of identifiers delimited by
-	// the minus sign character (-). The canonical forms for target triple
-	// strings are:
+	DataLayout string
+	// TargetTriple describes the target host as a series of identifiers
+	// delimited by the minus sign character (-). The canonical forms for target
+	// triple strings are:
 	//    ARCHITECTURE-VENDOR-OPERATING_SYSTEM
 	//    ARCHITECTURE-VENDOR-OPERATING_SYSTEM-ENVIRONMENT
 	//
@@ -40,33 +43,173 @@ type Module struct {
 	//
 	// References:
 	//    http://llvm.org/docs/LangRef.html#target-triple
-	target string
-	// Type definitions.
-	types []types.Type
+	TargetTriple string
+	// Type definitions, mapping from type name to type. Identified structures
+	// are stored by their name.
+	TypeDefs map[string]types.Type
 	// Global variables.
-	globals []values.Value
+	Globals []*Global
 	// Function definitions and external function declarations (Blocks is nil).
-	funcs []*Function
-	// Metadata.
-	metadata []*Metadata
+	Funcs []*Function
+	// Named metadata, mapping from metadata name (e.g. "llvm.module.flags") to
+	// a list of metadata nodes.
+	NamedMetadata map[string][]*MetadataNode
+	// Metadata nodes, ordered by ID.
+	Metadata []*MetadataNode
+	// Source comments of instructions and terminators, mapping from instruction
+	// (or terminator) to comment text without the leading semicolon; or nil if
+	// not present. The comments are printed at the end of the line of their
+	// instruction, e.g.
+	//
+	//    %x = add i32 %a, %b ; x = a + b
+	//
+	// The "; preds = %a, %b" comments of basic block labels are regenerated
+	// when printing the module, and are thus not stored in the side table.
+	Comments map[interface{}]string
+}
+
+// AddTypeDef adds the given identified structure type to the type definitions
+// of the module.
+func (module *Module) AddTypeDef(t *types.IdentifiedStruct) {
+	if module.TypeDefs == nil {
+		module.TypeDefs = make(map[string]types.Type)
+	}
+	module.TypeDefs[t.Name] = t
+}
+
+// AppendGlobal appends the given global variable to the module.
+func (module *Module) AppendGlobal(global *Global) {
+	module.Globals = append(module.Globals, global)
+}
+
+// AddGlobalString adds a private constant global variable of the given name to
+// the module, which holds the NUL-terminated string s, e.g.
+//
+//    @.str = private constant [6 x i8] c"hello\00"
+func (module *Module) AddGlobalString(name, s string) *Global {
+	str := consts.NewCString(s)
+	global := &Global{
+		Name:    name,
+		Content: str.Type(),
+		Init:    str,
+		IsConst: true,
+		Linkage: LinkagePrivate,
+	}
+	module.AppendGlobal(global)
+	return global
+}
+
+// AppendFunc appends the given function definition or external function
+// declaration to the module.
+func (module *Module) AppendFunc(f *Function) {
+	module.Funcs = append(module.Funcs, f)
 }
 
+// String returns the LLVM IR assembly representation of the module, as
+// accepted by llvm-as of LLVM 15. The data layout and target triple are
+// printed first, followed by type definitions, global variables, external
+// function declarations and function definitions; each section separated by a
+// blank line. Type definitions are printed in alphabetical order, while global
+// variables and functions are printed in the order they were added to the
+// module. The labels of basic blocks are annotated with their predecessors,
+// and instructions are followed by their source comments (if any).
 func (module *Module) String() string {
 	buf := new(bytes.Buffer)
+	// sep separates non-empty sections of the module by a blank line.
+	sep := func() {
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+	}
+
 	// Data layout.
-	if len(module.layout) > 0 {
+	if len(module.DataLayout) > 0 {
 		// target datalayout = "e-m:e-i64:64-f80:128-n8:16:32:64-S128"
-		fmt.Fprintf(buf, "target datalayout = %q\n", module.layout)
+		fmt.Fprintf(buf, "target datalayout = %q\n", module.DataLayout)
 	}
 	// Target triple.
-	if len(module.target) > 0 {
+	if len(module.TargetTriple) > 0 {
 		// target triple = "x86_64-unknown-linux-gnu"
-		fmt.Fprintf(buf, "target triple = %q\n", module.target)
+		fmt.Fprintf(buf, "target triple = %q\n", module.TargetTriple)
+	}
+
+	// Type definitions.
+	if len(module.TypeDefs) > 0 {
+		sep()
+		var names []string
+		for name := range module.TypeDefs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			// %foo = type {i32, i8*}
+			typ := module.TypeDefs[name]
+			if t, ok := typ.(*types.IdentifiedStruct); ok {
+				// %node = type {i32, %node*}
+				fmt.Fprintf(buf, "%%%s = type %s\n", name, t.Def())
+				continue
+			}
+			fmt.Fprintf(buf, "%%%s = type %v\n", name, typ)
+		}
+	}
+
+	// Global variables.
+	if len(module.Globals) > 0 {
+		sep()
+		for _, global := range module.Globals {
+			fmt.Fprintf(buf, "%v\n", global)
+		}
+	}
+
+	// External function declarations.
+	first := true
+	for _, f := range module.Funcs {
+		if len(f.Blocks) > 0 {
+			continue
+		}
+		if first {
+			sep()
+			first = false
+		}
+		// declare i32 @printf(i8*, ...)
+		fmt.Fprintf(buf, "%v\n", f)
+	}
+
+	// Function definitions.
+	for _, f := range module.Funcs {
+		if len(f.Blocks) == 0 {
+			continue
+		}
+		sep()
+		// loop:                                             ; preds = %entry, %loop
+		fmt.Fprintf(buf, "%s\n", f.format(true, module.Comments))
+	}
+
+	// Named metadata.
+	if len(module.NamedMetadata) > 0 {
+		sep()
+		var names []string
+		for name := range module.NamedMetadata {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			// !llvm.module.flags = !{!0, !1}
+			var ids []string
+			for _, node := range module.NamedMetadata[name] {
+				ids = append(ids, node.Ident())
+			}
+			fmt.Fprintf(buf, "!%s = !{%s}\n", name, strings.Join(ids, ", "))
+		}
+	}
+
+	// Metadata.
+	if len(module.Metadata) > 0 {
+		sep()
+		for _, node := range module.Metadata {
+			// !0 = !{i32 2, !"Dwarf Version", i32 4}
+			fmt.Fprintf(buf, "%s = %v\n", node.Ident(), node)
+		}
 	}
-	// TODO: Print types.
-	// TODO: Print global variables.
-	// TODO: Print functions.
-	// TODO: Print named metadata.
-	// TODO: Print metadata.
-	panic("not yet implemented.")
+	return buf.String()
 }
@@ -0,0 +1,191 @@
+package ir
+
+import "fmt"
+
+// Opcode returns the mnemonic of the given non-terminator instruction, e.g.
+// "add", "load" or "getelementptr".
+func Opcode(inst Instruction) string {
+	switch inst.(type) {
+	// Binary operations.
+	case *AddInst:
+		return "add"
+	case *FaddInst:
+		return "fadd"
+	case *SubInst:
+		return "sub"
+	case *FsubInst:
+		return "fsub"
+	case *MulInst:
+		return "mul"
+	case *FmulInst:
+		return "fmul"
+	case *UdivInst:
+		return "udiv"
+	case *SdivInst:
+		return "sdiv"
+	case *FdivInst:
+		return "fdiv"
+	case *UremInst:
+		return "urem"
+	case *SremInst:
+		return "srem"
+	case *FremInst:
+		return "frem"
+
+	// Bitwise binary operations.
+	case *ShlInst:
+		return "shl"
+	case *LshrInst:
+		return "lshr"
+	case *AshrInst:
+		return "ashr"
+	case *AndInst:
+		return "and"
+	case *OrInst:
+		return "or"
+	case *XorInst:
+		return "xor"
+
+	// Aggregate operations.
+	case *ExtractvalueInst:
+		return "extractvalue"
+	case *InsertvalueInst:
+		return "insertvalue"
+
+	// Memory access and addressing operations.
+	case *AllocaInst:
+		return "alloca"
+	case *LoadInst:
+		return "load"
+	case *StoreInst:
+		return "store"
+	case *FenceInst:
+		return "fence"
+	case *GetelementptrInst:
+		return "getelementptr"
+
+	// Conversion operations.
+	case *TruncInst:
+		return "trunc"
+	case *ZextInst:
+		return "zext"
+	case *SextInst:
+		return "sext"
+	case *FptruncInst:
+		return "fptrunc"
+	case *FpextInst:
+		return "fpext"
+	case *FptouiInst:
+		return "fptoui"
+	case *FptosiInst:
+		return "fptosi"
+	case *UitofpInst:
+		return "uitofp"
+	case *SitofpInst:
+		return "sitofp"
+	case *PtrtointInst:
+		return "ptrtoint"
+	case *InttoptrInst:
+		return "inttoptr"
+	case *BitcastInst:
+		return "bitcast"
+	case *AddrspacecastInst:
+		return "addrspacecast"
+
+	// Other operations.
+	case *IcmpInst:
+		return "icmp"
+	case *FcmpInst:
+		return "fcmp"
+	case *PhiInst:
+		return "phi"
+	case *SelectInst:
+		return "select"
+	case *CallInst:
+		return "call"
+	case *LandingpadInst:
+		return "landingpad"
+	}
+	panic(fmt.Sprintf("support for instruction %T not yet implemented", inst))
+}
+
+// TermOpcode returns the mnemonic of the given terminator instruction, e.g.
+// "ret", "br" or "switch".
+func TermOpcode(term Terminator) string {
+	switch term.(type) {
+	case *ReturnInst:
+		return "ret"
+	case *CondBranchInst, *BranchInst:
+		return "br"
+	case *SwitchInst:
+		return "switch"
+	case *ResumeInst:
+		return "resume"
+	case *IndirectbrInst:
+		return "indirectbr"
+	case *UnreachableInst:
+		return "unreachable"
+	case *InvokeInst:
+		return "invoke"
+	}
+	panic(fmt.Sprintf("support for terminator %T not yet implemented", term))
+}
+
+// IsBinaryOp returns true if inst is a binary operation (e.g. add, fdiv, …),
+// and false otherwise.
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#binaryops
+func IsBinaryOp(inst Instruction) bool {
+	switch inst.(type) {
+	case *AddInst, *FaddInst, *SubInst, *FsubInst, *MulInst, *FmulInst, *UdivInst, *SdivInst, *FdivInst, *UremInst, *SremInst, *FremInst:
+		return true
+	}
+	return false
+}
+
+// IsBitwiseOp returns true if inst is a bitwise binary operation (e.g. shl,
+// xor, …), and false otherwise.
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#bitwiseops
+func IsBitwiseOp(inst Instruction) bool {
+	switch inst.(type) {
+	case *ShlInst, *LshrInst, *AshrInst, *AndInst, *OrInst, *XorInst:
+		return true
+	}
+	return false
+}
+
+// IsMemoryOp returns true if inst is a memory access or addressing operation
+// (e.g. load, getelementptr, …), and false otherwise.
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#memoryops
+func IsMemoryOp(inst Instruction) bool {
+	switch inst.(type) {
+	case *AllocaInst, *LoadInst, *StoreInst, *FenceInst, *GetelementptrInst:
+		return true
+	}
+	return false
+}
+
+// IsConversion returns true if inst is a conversion operation (e.g. trunc,
+// bitcast, …), and false otherwise.
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#conversion-operations
+func IsConversion(inst Instruction) bool {
+	switch inst.(type) {
+	case *TruncInst, *ZextInst, *SextInst, *FptruncInst, *FpextInst, *FptouiInst, *FptosiInst, *UitofpInst, *SitofpInst, *PtrtointInst, *InttoptrInst, *BitcastInst, *AddrspacecastInst:
+		return true
+	}
+	return false
+}
+
+// IsTerminator returns true if term is a terminator instruction, and false if
+// it is nil. As only terminator instructions may be assigned to the Terminator
+// interface, this is the case for every non-nil value.
+func IsTerminator(term Terminator) bool {
+	return term != nil
+}
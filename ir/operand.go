@@ -0,0 +1,731 @@
+package ir
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/llir/llvm/values"
+)
+
+// Operands returns the value operands of the add instruction; Op1 and Op2.
+func (inst *AddInst) Operands() []values.Value {
+	return []values.Value{inst.Op1, inst.Op2}
+}
+
+// Operands returns the value operands of the fadd instruction; Op1 and Op2.
+func (inst *FaddInst) Operands() []values.Value {
+	return []values.Value{inst.Op1, inst.Op2}
+}
+
+// Operands returns the value operands of the sub instruction; Op1 and Op2.
+func (inst *SubInst) Operands() []values.Value {
+	return []values.Value{inst.Op1, inst.Op2}
+}
+
+// Operands returns the value operands of the fsub instruction; Op1 and Op2.
+func (inst *FsubInst) Operands() []values.Value {
+	return []values.Value{inst.Op1, inst.Op2}
+}
+
+// Operands returns the value operands of the mul instruction; Op1 and Op2.
+func (inst *MulInst) Operands() []values.Value {
+	return []values.Value{inst.Op1, inst.Op2}
+}
+
+// Operands returns the value operands of the fmul instruction; Op1 and Op2.
+func (inst *FmulInst) Operands() []values.Value {
+	return []values.Value{inst.Op1, inst.Op2}
+}
+
+// Operands returns the value operands of the udiv instruction; Op1 and Op2.
+func (inst *UdivInst) Operands() []values.Value {
+	return []values.Value{inst.Op1, inst.Op2}
+}
+
+// Operands returns the value operands of the sdiv instruction; Op1 and Op2.
+func (inst *SdivInst) Operands() []values.Value {
+	return []values.Value{inst.Op1, inst.Op2}
+}
+
+// Operands returns the value operands of the fdiv instruction; Op1 and Op2.
+func (inst *FdivInst) Operands() []values.Value {
+	return []values.Value{inst.Op1, inst.Op2}
+}
+
+// Operands returns the value operands of the urem instruction; Op1 and Op2.
+func (inst *UremInst) Operands() []values.Value {
+	return []values.Value{inst.Op1, inst.Op2}
+}
+
+// Operands returns the value operands of the srem instruction; Op1 and Op2.
+func (inst *SremInst) Operands() []values.Value {
+	return []values.Value{inst.Op1, inst.Op2}
+}
+
+// Operands returns the value operands of the frem instruction; Op1 and Op2.
+func (inst *FremInst) Operands() []values.Value {
+	return []values.Value{inst.Op1, inst.Op2}
+}
+
+// Operands returns the value operands of the shl instruction; Op1 and Op2.
+func (inst *ShlInst) Operands() []values.Value {
+	return []values.Value{inst.Op1, inst.Op2}
+}
+
+// Operands returns the value operands of the lshr instruction; Op1 and Op2.
+func (inst *LshrInst) Operands() []values.Value {
+	return []values.Value{inst.Op1, inst.Op2}
+}
+
+// Operands returns the value operands of the ashr instruction; Op1 and Op2.
+func (inst *AshrInst) Operands() []values.Value {
+	return []values.Value{inst.Op1, inst.Op2}
+}
+
+// Operands returns the value operands of the and instruction; Op1 and Op2.
+func (inst *AndInst) Operands() []values.Value {
+	return []values.Value{inst.Op1, inst.Op2}
+}
+
+// Operands returns the value operands of the or instruction; Op1 and Op2.
+func (inst *OrInst) Operands() []values.Value {
+	return []values.Value{inst.Op1, inst.Op2}
+}
+
+// Operands returns the value operands of the xor instruction; Op1 and Op2.
+func (inst *XorInst) Operands() []values.Value {
+	return []values.Value{inst.Op1, inst.Op2}
+}
+
+// Operands returns the value operands of the extractvalue instruction; the
+// aggregate value.
+func (inst *ExtractvalueInst) Operands() []values.Value {
+	return []values.Value{inst.Aggregate}
+}
+
+// Operands returns the value operands of the insertvalue instruction; the
+// aggregate value followed by the inserted element.
+func (inst *InsertvalueInst) Operands() []values.Value {
+	return []values.Value{inst.Aggregate, inst.Element}
+}
+
+// Operands returns the value operands of the alloca instruction, which has
+// none.
+func (inst *AllocaInst) Operands() []values.Value {
+	return nil
+}
+
+// Operands returns the value operands of the load instruction; the memory
+// address.
+func (inst *LoadInst) Operands() []values.Value {
+	return []values.Value{inst.Addr}
+}
+
+// Operands returns the value operands of the store instruction; the stored
+// value followed by the memory address.
+func (inst *StoreInst) Operands() []values.Value {
+	return []values.Value{inst.Val, inst.Addr}
+}
+
+// Operands returns the value operands of the fence instruction, which has none.
+func (inst *FenceInst) Operands() []values.Value {
+	return nil
+}
+
+// Operands returns the value operands of the getelementptr instruction; the
+// pointer value; the constant indices are not included.
+func (inst *GetelementptrInst) Operands() []values.Value {
+	return []values.Value{inst.Ptr}
+}
+
+// Operands returns the value operands of the trunc instruction; the value to
+// convert.
+func (inst *TruncInst) Operands() []values.Value {
+	return []values.Value{inst.From}
+}
+
+// Operands returns the value operands of the zext instruction; the value to
+// convert.
+func (inst *ZextInst) Operands() []values.Value {
+	return []values.Value{inst.From}
+}
+
+// Operands returns the value operands of the sext instruction; the value to
+// convert.
+func (inst *SextInst) Operands() []values.Value {
+	return []values.Value{inst.From}
+}
+
+// Operands returns the value operands of the fptrunc instruction; the value to
+// convert.
+func (inst *FptruncInst) Operands() []values.Value {
+	return []values.Value{inst.From}
+}
+
+// Operands returns the value operands of the fpext instruction; the value to
+// convert.
+func (inst *FpextInst) Operands() []values.Value {
+	return []values.Value{inst.From}
+}
+
+// Operands returns the value operands of the fptoui instruction; the value to
+// convert.
+func (inst *FptouiInst) Operands() []values.Value {
+	return []values.Value{inst.From}
+}
+
+// Operands returns the value operands of the fptosi instruction; the value to
+// convert.
+func (inst *FptosiInst) Operands() []values.Value {
+	return []values.Value{inst.From}
+}
+
+// Operands returns the value operands of the uitofp instruction; the value to
+// convert.
+func (inst *UitofpInst) Operands() []values.Value {
+	return []values.Value{inst.From}
+}
+
+// Operands returns the value operands of the sitofp instruction; the value to
+// convert.
+func (inst *SitofpInst) Operands() []values.Value {
+	return []values.Value{inst.From}
+}
+
+// Operands returns the value operands of the ptrtoint instruction; the value to
+// convert.
+func (inst *PtrtointInst) Operands() []values.Value {
+	return []values.Value{inst.From}
+}
+
+// Operands returns the value operands of the inttoptr instruction; the value to
+// convert.
+func (inst *InttoptrInst) Operands() []values.Value {
+	return []values.Value{inst.From}
+}
+
+// Operands returns the value operands of the bitcast instruction; the value to
+// convert.
+func (inst *BitcastInst) Operands() []values.Value {
+	return []values.Value{inst.From}
+}
+
+// Operands returns the value operands of the addrspacecast instruction; the
+// value to convert.
+func (inst *AddrspacecastInst) Operands() []values.Value {
+	return []values.Value{inst.From}
+}
+
+// Operands returns the value operands of the icmp instruction; Op1 and Op2.
+func (inst *IcmpInst) Operands() []values.Value {
+	return []values.Value{inst.Op1, inst.Op2}
+}
+
+// Operands returns the value operands of the fcmp instruction; Op1 and Op2.
+func (inst *FcmpInst) Operands() []values.Value {
+	return []values.Value{inst.Op1, inst.Op2}
+}
+
+// Operands returns the value operands of the phi instruction; the incoming
+// values, in the order of the predecessor basic blocks added using AddIncoming,
+// followed by the remaining ones sorted by name.
+func (inst *PhiInst) Operands() []values.Value {
+	var ops []values.Value
+	for _, pred := range inst.predNames() {
+		ops = append(ops, inst.Preds[pred])
+	}
+	return ops
+}
+
+// Operands returns the value operands of the select instruction; the condition
+// followed by the true and false values.
+func (inst *SelectInst) Operands() []values.Value {
+	return []values.Value{inst.Cond, inst.TrueValue, inst.FalseValue}
+}
+
+// Operands returns the value operands of the call instruction; the callee
+// followed by the function arguments.
+func (inst *CallInst) Operands() []values.Value {
+	return append([]values.Value{inst.Callee}, inst.Args...)
+}
+
+// Operands returns the value operands of the landingpad instruction; the values
+// of the clauses.
+func (inst *LandingpadInst) Operands() []values.Value {
+	var ops []values.Value
+	for _, clause := range inst.Clauses {
+		ops = append(ops, clause.Val)
+	}
+	return ops
+}
+
+// SetOperand sets the i:th value operand of the add instruction to v, using the
+// operand order of Operands. An error is returned if the operand index is out
+// of range.
+func (inst *AddInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.Op1, &inst.Op2)
+}
+
+// SetOperand sets the i:th value operand of the fadd instruction to v, using
+// the operand order of Operands. An error is returned if the operand index is
+// out of range.
+func (inst *FaddInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.Op1, &inst.Op2)
+}
+
+// SetOperand sets the i:th value operand of the sub instruction to v, using the
+// operand order of Operands. An error is returned if the operand index is out
+// of range.
+func (inst *SubInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.Op1, &inst.Op2)
+}
+
+// SetOperand sets the i:th value operand of the fsub instruction to v, using
+// the operand order of Operands. An error is returned if the operand index is
+// out of range.
+func (inst *FsubInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.Op1, &inst.Op2)
+}
+
+// SetOperand sets the i:th value operand of the mul instruction to v, using the
+// operand order of Operands. An error is returned if the operand index is out
+// of range.
+func (inst *MulInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.Op1, &inst.Op2)
+}
+
+// SetOperand sets the i:th value operand of the fmul instruction to v, using
+// the operand order of Operands. An error is returned if the operand index is
+// out of range.
+func (inst *FmulInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.Op1, &inst.Op2)
+}
+
+// SetOperand sets the i:th value operand of the udiv instruction to v, using
+// the operand order of Operands. An error is returned if the operand index is
+// out of range.
+func (inst *UdivInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.Op1, &inst.Op2)
+}
+
+// SetOperand sets the i:th value operand of the sdiv instruction to v, using
+// the operand order of Operands. An error is returned if the operand index is
+// out of range.
+func (inst *SdivInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.Op1, &inst.Op2)
+}
+
+// SetOperand sets the i:th value operand of the fdiv instruction to v, using
+// the operand order of Operands. An error is returned if the operand index is
+// out of range.
+func (inst *FdivInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.Op1, &inst.Op2)
+}
+
+// SetOperand sets the i:th value operand of the urem instruction to v, using
+// the operand order of Operands. An error is returned if the operand index is
+// out of range.
+func (inst *UremInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.Op1, &inst.Op2)
+}
+
+// SetOperand sets the i:th value operand of the srem instruction to v, using
+// the operand order of Operands. An error is returned if the operand index is
+// out of range.
+func (inst *SremInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.Op1, &inst.Op2)
+}
+
+// SetOperand sets the i:th value operand of the frem instruction to v, using
+// the operand order of Operands. An error is returned if the operand index is
+// out of range.
+func (inst *FremInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.Op1, &inst.Op2)
+}
+
+// SetOperand sets the i:th value operand of the shl instruction to v, using the
+// operand order of Operands. An error is returned if the operand index is out
+// of range.
+func (inst *ShlInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.Op1, &inst.Op2)
+}
+
+// SetOperand sets the i:th value operand of the lshr instruction to v, using
+// the operand order of Operands. An error is returned if the operand index is
+// out of range.
+func (inst *LshrInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.Op1, &inst.Op2)
+}
+
+// SetOperand sets the i:th value operand of the ashr instruction to v, using
+// the operand order of Operands. An error is returned if the operand index is
+// out of range.
+func (inst *AshrInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.Op1, &inst.Op2)
+}
+
+// SetOperand sets the i:th value operand of the and instruction to v, using the
+// operand order of Operands. An error is returned if the operand index is out
+// of range.
+func (inst *AndInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.Op1, &inst.Op2)
+}
+
+// SetOperand sets the i:th value operand of the or instruction to v, using the
+// operand order of Operands. An error is returned if the operand index is out
+// of range.
+func (inst *OrInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.Op1, &inst.Op2)
+}
+
+// SetOperand sets the i:th value operand of the xor instruction to v, using the
+// operand order of Operands. An error is returned if the operand index is out
+// of range.
+func (inst *XorInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.Op1, &inst.Op2)
+}
+
+// SetOperand sets the i:th value operand of the extractvalue instruction to v,
+// using the operand order of Operands. An error is returned if the operand
+// index is out of range.
+func (inst *ExtractvalueInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.Aggregate)
+}
+
+// SetOperand sets the i:th value operand of the insertvalue instruction to v,
+// using the operand order of Operands. An error is returned if the operand
+// index is out of range.
+func (inst *InsertvalueInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.Aggregate, &inst.Element)
+}
+
+// SetOperand sets the i:th value operand of the alloca instruction to v. As the
+// instruction has no value operands, an error is always returned.
+func (inst *AllocaInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v)
+}
+
+// SetOperand sets the i:th value operand of the load instruction to v, using
+// the operand order of Operands. An error is returned if the operand index is
+// out of range.
+func (inst *LoadInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.Addr)
+}
+
+// SetOperand sets the i:th value operand of the store instruction to v, using
+// the operand order of Operands. An error is returned if the operand index is
+// out of range.
+func (inst *StoreInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.Val, &inst.Addr)
+}
+
+// SetOperand sets the i:th value operand of the fence instruction to v. As the
+// instruction has no value operands, an error is always returned.
+func (inst *FenceInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v)
+}
+
+// SetOperand sets the i:th value operand of the getelementptr instruction to v,
+// using the operand order of Operands. An error is returned if the operand
+// index is out of range.
+func (inst *GetelementptrInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.Ptr)
+}
+
+// SetOperand sets the i:th value operand of the trunc instruction to v, using
+// the operand order of Operands. An error is returned if the operand index is
+// out of range.
+func (inst *TruncInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.From)
+}
+
+// SetOperand sets the i:th value operand of the zext instruction to v, using
+// the operand order of Operands. An error is returned if the operand index is
+// out of range.
+func (inst *ZextInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.From)
+}
+
+// SetOperand sets the i:th value operand of the sext instruction to v, using
+// the operand order of Operands. An error is returned if the operand index is
+// out of range.
+func (inst *SextInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.From)
+}
+
+// SetOperand sets the i:th value operand of the fptrunc instruction to v, using
+// the operand order of Operands. An error is returned if the operand index is
+// out of range.
+func (inst *FptruncInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.From)
+}
+
+// SetOperand sets the i:th value operand of the fpext instruction to v, using
+// the operand order of Operands. An error is returned if the operand index is
+// out of range.
+func (inst *FpextInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.From)
+}
+
+// SetOperand sets the i:th value operand of the fptoui instruction to v, using
+// the operand order of Operands. An error is returned if the operand index is
+// out of range.
+func (inst *FptouiInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.From)
+}
+
+// SetOperand sets the i:th value operand of the fptosi instruction to v, using
+// the operand order of Operands. An error is returned if the operand index is
+// out of range.
+func (inst *FptosiInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.From)
+}
+
+// SetOperand sets the i:th value operand of the uitofp instruction to v, using
+// the operand order of Operands. An error is returned if the operand index is
+// out of range.
+func (inst *UitofpInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.From)
+}
+
+// SetOperand sets the i:th value operand of the sitofp instruction to v, using
+// the operand order of Operands. An error is returned if the operand index is
+// out of range.
+func (inst *SitofpInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.From)
+}
+
+// SetOperand sets the i:th value operand of the ptrtoint instruction to v,
+// using the operand order of Operands. An error is returned if the operand
+// index is out of range.
+func (inst *PtrtointInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.From)
+}
+
+// SetOperand sets the i:th value operand of the inttoptr instruction to v,
+// using the operand order of Operands. An error is returned if the operand
+// index is out of range.
+func (inst *InttoptrInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.From)
+}
+
+// SetOperand sets the i:th value operand of the bitcast instruction to v, using
+// the operand order of Operands. An error is returned if the operand index is
+// out of range.
+func (inst *BitcastInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.From)
+}
+
+// SetOperand sets the i:th value operand of the addrspacecast instruction to v,
+// using the operand order of Operands. An error is returned if the operand
+// index is out of range.
+func (inst *AddrspacecastInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.From)
+}
+
+// SetOperand sets the i:th value operand of the icmp instruction to v, using
+// the operand order of Operands. An error is returned if the operand index is
+// out of range.
+func (inst *IcmpInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.Op1, &inst.Op2)
+}
+
+// SetOperand sets the i:th value operand of the fcmp instruction to v, using
+// the operand order of Operands. An error is returned if the operand index is
+// out of range.
+func (inst *FcmpInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.Op1, &inst.Op2)
+}
+
+// SetOperand sets the i:th value operand of the phi instruction to v, using the
+// operand order of Operands. An error is returned if the operand index is out
+// of range.
+func (inst *PhiInst) SetOperand(i int, v values.Value) error {
+	preds := inst.predNames()
+	if i < 0 || i >= len(preds) {
+		return operandRangeError(i, len(preds))
+	}
+	inst.Preds[preds[i]] = v
+	return nil
+}
+
+// SetOperand sets the i:th value operand of the select instruction to v, using
+// the operand order of Operands. An error is returned if the operand index is
+// out of range.
+func (inst *SelectInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &inst.Cond, &inst.TrueValue, &inst.FalseValue)
+}
+
+// SetOperand sets the i:th value operand of the call instruction to v, using
+// the operand order of Operands. An error is returned if the operand index is
+// out of range.
+func (inst *CallInst) SetOperand(i int, v values.Value) error {
+	ops := []*values.Value{&inst.Callee}
+	for j := range inst.Args {
+		ops = append(ops, &inst.Args[j])
+	}
+	return setOperand(i, v, ops...)
+}
+
+// SetOperand sets the i:th value operand of the landingpad instruction to v,
+// using the operand order of Operands. An error is returned if the operand
+// index is out of range.
+func (inst *LandingpadInst) SetOperand(i int, v values.Value) error {
+	var ops []*values.Value
+	for j := range inst.Clauses {
+		ops = append(ops, &inst.Clauses[j].Val)
+	}
+	return setOperand(i, v, ops...)
+}
+
+// Operands returns the value operands of the ret terminator; the return value,
+// or none in case of a void return.
+func (term *ReturnInst) Operands() []values.Value {
+	if term.Val == nil {
+		// Void return.
+		return nil
+	}
+	return []values.Value{term.Val}
+}
+
+// Operands returns the value operands of the conditional br terminator; the
+// branching condition; the target basic blocks are not included.
+func (term *CondBranchInst) Operands() []values.Value {
+	return []values.Value{term.Cond}
+}
+
+// Operands returns the value operands of the br terminator, which has none.
+func (term *BranchInst) Operands() []values.Value {
+	return nil
+}
+
+// Operands returns the value operands of the switch terminator; the controlling
+// value; the case values and target basic blocks are not included.
+func (term *SwitchInst) Operands() []values.Value {
+	return []values.Value{term.Val}
+}
+
+// Operands returns the value operands of the indirectbr terminator; the target
+// address; the possible target basic blocks are not included.
+func (term *IndirectbrInst) Operands() []values.Value {
+	return []values.Value{term.Addr}
+}
+
+// Operands returns the value operands of the invoke terminator; the callee
+// followed by the function arguments; the normal and unwind target basic blocks
+// are not included.
+func (term *InvokeInst) Operands() []values.Value {
+	return append([]values.Value{term.Callee}, term.Args...)
+}
+
+// Operands returns the value operands of the resume terminator; the exception
+// value.
+func (term *ResumeInst) Operands() []values.Value {
+	return []values.Value{term.Val}
+}
+
+// Operands returns the value operands of the unreachable terminator, which has
+// none.
+func (term *UnreachableInst) Operands() []values.Value {
+	return nil
+}
+
+// SetOperand sets the i:th value operand of the ret terminator to v, using the
+// operand order of Operands. An error is returned if the operand index is out
+// of range.
+func (term *ReturnInst) SetOperand(i int, v values.Value) error {
+	if term.Val == nil {
+		// Void return.
+		return setOperand(i, v)
+	}
+	return setOperand(i, v, &term.Val)
+}
+
+// SetOperand sets the i:th value operand of the conditional br terminator to v,
+// using the operand order of Operands. An error is returned if the operand
+// index is out of range.
+func (term *CondBranchInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &term.Cond)
+}
+
+// SetOperand sets the i:th value operand of the br terminator to v. As the
+// terminator has no value operands, an error is always returned.
+func (term *BranchInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v)
+}
+
+// SetOperand sets the i:th value operand of the switch terminator to v, using
+// the operand order of Operands. An error is returned if the operand index is
+// out of range.
+func (term *SwitchInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &term.Val)
+}
+
+// SetOperand sets the i:th value operand of the indirectbr terminator to v,
+// using the operand order of Operands. An error is returned if the operand
+// index is out of range.
+func (term *IndirectbrInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &term.Addr)
+}
+
+// SetOperand sets the i:th value operand of the invoke terminator to v, using
+// the operand order of Operands. An error is returned if the operand index is
+// out of range.
+func (term *InvokeInst) SetOperand(i int, v values.Value) error {
+	ops := []*values.Value{&term.Callee}
+	for j := range term.Args {
+		ops = append(ops, &term.Args[j])
+	}
+	return setOperand(i, v, ops...)
+}
+
+// SetOperand sets the i:th value operand of the resume terminator to v, using
+// the operand order of Operands. An error is returned if the operand index is
+// out of range.
+func (term *ResumeInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v, &term.Val)
+}
+
+// SetOperand sets the i:th value operand of the unreachable terminator to v. As
+// the terminator has no value operands, an error is always returned.
+func (term *UnreachableInst) SetOperand(i int, v values.Value) error {
+	return setOperand(i, v)
+}
+
+// setOperand sets the i:th operand of ops to v. An error is returned if the
+// operand index is out of range.
+func setOperand(i int, v values.Value, ops ...*values.Value) error {
+	if i < 0 || i >= len(ops) {
+		return operandRangeError(i, len(ops))
+	}
+	*ops[i] = v
+	return nil
+}
+
+// operandRangeError returns an error for the out of range operand index i of an
+// instruction with n operands.
+func operandRangeError(i, n int) error {
+	return fmt.Errorf("invalid operand index (%d); instruction has %d operands", i, n)
+}
+
+// predNames returns the predecessor basic block names of the phi instruction;
+// those added using AddIncoming in insertion order, followed by the remaining
+// ones in sorted order.
+func (inst *PhiInst) predNames() []string {
+	var preds []string
+	seen := make(map[string]bool)
+	for _, pred := range inst.order {
+		if _, ok := inst.Preds[pred]; ok && !seen[pred] {
+			preds = append(preds, pred)
+			seen[pred] = true
+		}
+	}
+	var rest []string
+	for pred := range inst.Preds {
+		if !seen[pred] {
+			rest = append(rest, pred)
+		}
+	}
+	sort.Strings(rest)
+	return append(preds, rest...)
+}
@@ -0,0 +1,57 @@
+package ir
+
+import "github.com/llir/llvm/values"
+
+// A Ranking records a deterministic total order over the values of a function;
+// constants (and global values) rank lowest, followed by the function
+// parameters by index, followed by the results of instructions by their
+// position in the function.
+type Ranking struct {
+	// Rank of each function parameter and named instruction result, mapping from
+	// local name to rank.
+	ranks map[string]uint64
+}
+
+// ComputeRanks computes the ranking of the values of the function f. The
+// results of instructions (and terminators, such as invoke) are ranked by
+// their position in program order. Should several instructions define the same
+// name, the first definition determines the rank.
+func ComputeRanks(f *Function) *Ranking {
+	r := &Ranking{ranks: make(map[string]uint64)}
+	rank := uint64(1)
+	define := func(name string) {
+		if _, ok := r.ranks[name]; !ok && len(name) > 0 {
+			r.ranks[name] = rank
+		}
+		rank++
+	}
+	for _, param := range f.Params {
+		define(param.Name)
+	}
+	for _, block := range f.Blocks {
+		for _, inst := range block.Insts {
+			if res, ok := inst.(result); ok && !isVoid(inst) {
+				define(res.name())
+			}
+		}
+		if res, ok := block.Term.(result); ok && !isVoid(block.Term) {
+			define(res.name())
+		}
+	}
+	return r
+}
+
+// Rank returns the rank of the value v. Constants and global values have rank
+// 0, the function parameter at index i has rank i+1, and the results of
+// instructions have ranks following the last function parameter. Local
+// variables are resolved by name; local variables not defined in the function
+// have rank 0.
+func (r *Ranking) Rank(v values.Value) uint64 {
+	switch v := v.(type) {
+	case *values.Local:
+		return r.ranks[v.Name]
+	case *values.Param:
+		return r.ranks[v.Name]
+	}
+	return 0
+}
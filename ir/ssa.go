@@ -0,0 +1,119 @@
+package ir
+
+import (
+	"fmt"
+
+	"github.com/llir/llvm/values"
+)
+
+// ValidateSSA verifies that the function f is in static single assignment
+// form, by checking that every local variable is defined exactly once, and that
+// every use of a local variable is dominated by its definition. The incoming
+// values of phi instructions must be defined in, or in a dominator of, their
+// corresponding predecessor basic block. Uses within basic blocks unreachable
+// from the entry basic block are not checked. All violations are reported,
+// rather than only the first.
+func ValidateSSA(f *Function) []error {
+	// def records the definition of a local variable; the basic block is nil
+	// for function parameters.
+	type def struct {
+		block *BasicBlock
+		index int
+	}
+
+	// Locate the definition of each local variable.
+	var errs []error
+	defs := make(map[string]def)
+	for _, param := range f.Params {
+		if _, ok := defs[param.Name]; ok {
+			errs = append(errs, fmt.Errorf("redefinition of function parameter %s", param.Ident()))
+			continue
+		}
+		defs[param.Name] = def{}
+	}
+	for _, block := range f.Blocks {
+		for i, inst := range block.Insts {
+			res, ok := inst.(result)
+			if !ok || len(res.name()) == 0 || isVoid(inst) {
+				continue
+			}
+			if _, ok := defs[res.name()]; ok {
+				errs = append(errs, fmt.Errorf("%s: redefinition of %%%s by %q", block.Ident(), res.name(), instString(inst)))
+				continue
+			}
+			defs[res.name()] = def{block: block, index: i}
+		}
+		if res, ok := block.Term.(result); ok && len(res.name()) > 0 && !isVoid(block.Term) {
+			if _, ok := defs[res.name()]; ok {
+				errs = append(errs, fmt.Errorf("%s: redefinition of %%%s by %q", block.Ident(), res.name(), instString(block.Term)))
+			} else {
+				defs[res.name()] = def{block: block, index: len(block.Insts)}
+			}
+		}
+	}
+
+	// Verify that each use is dominated by its definition. The terminator of a
+	// basic block is located at the index following its last instruction.
+	dt := ComputeDominators(f)
+	blocks := make(map[string]*BasicBlock)
+	for _, block := range f.Blocks {
+		blocks[block.Name] = block
+	}
+	check := func(block *BasicBlock, index int, inst interface{}, op values.Value) {
+		local, ok := op.(*values.Local)
+		if !ok {
+			return
+		}
+		d, ok := defs[local.Name]
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Errorf("%s: use of undefined value %s in %q", block.Ident(), local.Ident(), instString(inst)))
+		case d.block == nil:
+			// Function parameters dominate every use.
+		case d.block == block && d.index >= index, d.block != block && !dt.Dominates(d.block, block):
+			errs = append(errs, fmt.Errorf("%s: use of %s not dominated by its definition in %q", block.Ident(), local.Ident(), instString(inst)))
+		}
+	}
+	for _, block := range f.Blocks {
+		if !dt.Dominates(block, block) {
+			// Skip unreachable basic blocks.
+			continue
+		}
+		for i, inst := range block.Insts {
+			phi, ok := inst.(*PhiInst)
+			if !ok {
+				for _, op := range inst.Operands() {
+					check(block, i, inst, op)
+				}
+				continue
+			}
+			// Incoming values must be available at the end of their predecessor
+			// basic block.
+			for _, name := range phi.predNames() {
+				if pred, ok := blocks[name]; ok && dt.Dominates(pred, pred) {
+					check(pred, len(pred.Insts)+1, inst, phi.Preds[name])
+				}
+			}
+		}
+		if block.Term != nil {
+			for _, op := range block.Term.Operands() {
+				check(block, len(block.Insts), block.Term, op)
+			}
+		}
+	}
+	return errs
+}
+
+// instString returns a string representation of the given instruction or
+// terminator, or its mnemonic if it has none.
+func instString(inst interface{}) string {
+	switch inst := inst.(type) {
+	case fmt.Stringer:
+		return inst.String()
+	case Instruction:
+		return Opcode(inst)
+	case Terminator:
+		return TermOpcode(inst)
+	}
+	return fmt.Sprintf("%T", inst)
+}
@@ -1,6 +1,10 @@
 package ir
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+
 	"github.com/llir/llvm/consts"
 	"github.com/llir/llvm/types"
 	"github.com/llir/llvm/values"
@@ -12,6 +16,10 @@ import (
 // References:
 //    http://llvm.org/docs/LangRef.html#terminator-instructions
 type Terminator interface {
+	// Operands returns the value operands of the terminator.
+	Operands() []values.Value
+	// SetOperand sets the i:th value operand of the terminator to v.
+	SetOperand(i int, v values.Value) error
 	// isTerm ensures that only terminator instructions can be assigned to the
 	// Terminator interface.
 	isTerm()
@@ -41,6 +49,28 @@ type ReturnInst struct {
 	Type types.Type
 	// Return value; or nil in case of a void return.
 	Val values.Value
+	// Metadata attachments.
+	Metadata
+}
+
+// NewRet returns a new ret instruction which returns control flow (and
+// optionally the value val) to the caller. A nil val produces a void return.
+func NewRet(val values.Value) *ReturnInst {
+	if val == nil {
+		return &ReturnInst{Type: types.NewVoid()}
+	}
+	return &ReturnInst{Type: val.Type(), Val: val}
+}
+
+// String returns a string representation of the ret instruction, e.g.
+//
+//    ret i32 42
+//    ret void
+func (term *ReturnInst) String() string {
+	if term.Val == nil {
+		return term.attach("ret void")
+	}
+	return term.attach(fmt.Sprintf("ret %v", term.Val))
 }
 
 // The CondBranchInst transfers control flow to one of two basic blocks in the
@@ -61,6 +91,26 @@ type CondBranchInst struct {
 	True *BasicBlock
 	// Target branch when the condition evaluates to false.
 	False *BasicBlock
+	// Metadata attachments.
+	Metadata
+}
+
+// NewCondBr returns a new conditional br instruction which transfers control
+// flow to the basic block trueBranch if cond evaluates to true, and to
+// falseBranch otherwise.
+func NewCondBr(cond values.Value, trueBranch, falseBranch *BasicBlock) (*CondBranchInst, error) {
+	if typ, ok := cond.Type().(*types.Int); !ok || typ.Size() != 1 {
+		return nil, fmt.Errorf("invalid branching condition; expected i1, got %q", cond.Type())
+	}
+	return &CondBranchInst{Cond: cond, True: trueBranch, False: falseBranch}, nil
+}
+
+// String returns a string representation of the conditional br instruction,
+// e.g.
+//
+//    br i1 %cond, label %true, label %false
+func (term *CondBranchInst) String() string {
+	return term.attach(fmt.Sprintf("br %v, label %s, label %s", term.Cond, term.True.Ident(), term.False.Ident()))
 }
 
 // The BranchInst transfers control flow to a basic block in the current
@@ -77,6 +127,22 @@ type CondBranchInst struct {
 type BranchInst struct {
 	// Target branch.
 	Target *BasicBlock
+	// Metadata attachments.
+	Metadata
+}
+
+// NewBr returns a new unconditional br instruction which transfers control flow
+// to the basic block target.
+func NewBr(target *BasicBlock) *BranchInst {
+	return &BranchInst{Target: target}
+}
+
+// String returns a string representation of the unconditional br instruction,
+// e.g.
+//
+//    br label %next
+func (term *BranchInst) String() string {
+	return term.attach(fmt.Sprintf("br label %s", term.Target.Ident()))
 }
 
 // The SwitchInst transfers control flow to one of several basic blocks in the
@@ -105,19 +171,206 @@ type SwitchInst struct {
 	// Default target.
 	Default *BasicBlock
 	// Switch cases.
-	Cases []struct {
-		// Case value.
-		Val consts.Constant
-		// Case target.
-		Target *BasicBlock
+	Cases []SwitchCase
+	// Metadata attachments.
+	Metadata
+}
+
+// A SwitchCase is a case of a switch instruction, which transfers control flow
+// to Target if the comparison value is equal to Val.
+type SwitchCase struct {
+	// Case value.
+	Val consts.Constant
+	// Case target.
+	Target *BasicBlock
+}
+
+// NewSwitch returns a new switch instruction which transfers control flow to
+// the target of the case matching val, or to the basic block def if no case
+// matches.
+func NewSwitch(val values.Value, def *BasicBlock, cases []SwitchCase) (*SwitchInst, error) {
+	typ := val.Type()
+	if !types.IsInt(typ) {
+		return nil, fmt.Errorf("invalid switch comparison value; expected integer, got %q", typ)
+	}
+	seen := make(map[string]bool)
+	for _, c := range cases {
+		if !typ.Equal(c.Val.Type()) {
+			return nil, fmt.Errorf("invalid switch case %q; type mismatch; expected %q, got %q", c.Val, typ, c.Val.Type())
+		}
+		key := c.Val.String()
+		if seen[key] {
+			return nil, fmt.Errorf("invalid switch case %q; duplicate case value", c.Val)
+		}
+		seen[key] = true
+	}
+	return &SwitchInst{Type: typ, Val: val, Default: def, Cases: cases}, nil
+}
+
+// String returns a string representation of the switch instruction, e.g.
+//
+//    switch i32 %x, label %default [ i32 0, label %zero i32 1, label %one ]
+func (term *SwitchInst) String() string {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "switch %v, label %s [", term.Val, term.Default.Ident())
+	for _, c := range term.Cases {
+		fmt.Fprintf(buf, " %v, label %s", c.Val, c.Target.Ident())
+	}
+	buf.WriteString(" ]")
+	return term.attach(buf.String())
+}
+
+// The IndirectbrInst transfers control flow to a basic block in the current
+// function, whose address is specified by Addr.
+//
+// Syntax:
+//    indirectbr <Type> <Addr>, [ label <Target1>, label <Target2>, ... ]
+//
+// Semantics:
+//    goto *Addr; // where Addr is the address of one of Targets.
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#i-indirectbr
+type IndirectbrInst struct {
+	// Target address.
+	Addr values.Value
+	// Possible destinations of the target address.
+	Targets []*BasicBlock
+	// Metadata attachments.
+	Metadata
+}
+
+// NewIndirectbr returns a new indirectbr instruction which transfers control
+// flow to the basic block at address addr, which is one of targets.
+func NewIndirectbr(addr values.Value, targets []*BasicBlock) (*IndirectbrInst, error) {
+	if !types.IsPointer(addr.Type()) {
+		return nil, fmt.Errorf("invalid indirectbr address; expected pointer, got %q", addr.Type())
+	}
+	if len(targets) == 0 {
+		return nil, errors.New("invalid indirectbr; expected at least one target")
 	}
+	return &IndirectbrInst{Addr: addr, Targets: targets}, nil
 }
 
-// TODO(u): Add the following terminator instructions:
-//    - indirectbr
-//    - invoke
-//    - resume
-//    - unreachable
+// String returns a string representation of the indirectbr instruction, e.g.
+//
+//    indirectbr i8* %addr, [label %a, label %b]
+func (term *IndirectbrInst) String() string {
+	buf := new(bytes.Buffer)
+	for i, target := range term.Targets {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		fmt.Fprintf(buf, "label %s", target.Ident())
+	}
+	return term.attach(fmt.Sprintf("indirectbr %v, [%s]", term.Addr, buf))
+}
+
+// The InvokeInst transfers control flow to a specified function, with the
+// possibility of control flow transfer to either the Normal or the Unwind basic
+// block.
+//
+// Syntax:
+//    <Result> = invoke <Type> <Callee>(<Args>) to label <Normal> unwind label <Unwind>
+//
+// Semantics:
+//    Result = Callee(Args...); // goto Normal on return, goto Unwind on exception.
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#i-invoke
+type InvokeInst struct {
+	// Name of the local variable holding the result.
+	LocalIdent
+	// Result type.
+	Type types.Type
+	// Callee; a function or a pointer to a function.
+	Callee values.Value
+	// Function arguments.
+	Args []values.Value
+	// Target branch when the callee returns.
+	Normal *BasicBlock
+	// Target branch when the callee raises an exception.
+	Unwind *BasicBlock
+	// Metadata attachments.
+	Metadata
+}
+
+// NewInvoke returns a new invoke instruction which invokes callee with the
+// given function arguments, and transfers control flow to normal when the
+// callee returns or to unwind when the callee raises an exception.
+func NewInvoke(callee values.Value, args []values.Value, normal, unwind *BasicBlock) (*InvokeInst, error) {
+	sig, err := checkCall(callee, args)
+	if err != nil {
+		return nil, err
+	}
+	if normal == nil {
+		return nil, errors.New("invalid invoke; missing normal destination")
+	}
+	if unwind == nil {
+		return nil, errors.New("invalid invoke; missing unwind destination")
+	}
+	return &InvokeInst{Type: sig.Result(), Callee: callee, Args: args, Normal: normal, Unwind: unwind}, nil
+}
+
+// Validate verifies that the callee of the invoke instruction is a function (or
+// pointer to function) which may be invoked with its function arguments, that
+// the result type of the invoke matches the signature of the callee, and that
+// both the normal and the unwind destinations are present.
+func (term *InvokeInst) Validate() error {
+	if term.Callee == nil {
+		return errors.New("invalid invoke; missing callee")
+	}
+	sig, err := checkCall(term.Callee, term.Args)
+	if err != nil {
+		return err
+	}
+	if !sig.Result().Equal(term.Type) {
+		return fmt.Errorf("invalid invoke result type; expected %q, got %q", sig.Result(), term.Type)
+	}
+	if term.Normal == nil {
+		return errors.New("invalid invoke; missing normal destination")
+	}
+	if term.Unwind == nil {
+		return errors.New("invalid invoke; missing unwind destination")
+	}
+	return nil
+}
+
+// String returns a string representation of the invoke instruction, e.g.
+//
+//    %x = invoke i32 @f(i32 42) to label %normal unwind label %unwind
+//    invoke void @g() to label %normal unwind label %unwind
+func (term *InvokeInst) String() string {
+	s := fmt.Sprintf("invoke %s to label %s unwind label %s", callString(term.Type, term.Callee, term.Args), term.Normal.Ident(), term.Unwind.Ident())
+	if isVoid(term) {
+		return term.attach(s)
+	}
+	return term.attach(term.assign(s))
+}
+
+// The ResumeInst resumes propagation of an existing (in-flight) exception.
+//
+// Syntax:
+//    resume <Type> <Val>
+//
+// Semantics:
+//    throw Val; // with Val being the result of a landingpad instruction.
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#i-resume
+type ResumeInst struct {
+	// Exception value to resume; the result of a landingpad instruction.
+	Val values.Value
+	// Metadata attachments.
+	Metadata
+}
+
+// String returns a string representation of the resume instruction, e.g.
+//
+//    resume {i8*, i32} %lp
+func (term *ResumeInst) String() string {
+	return term.attach(fmt.Sprintf("resume %v", term.Val))
+}
 
 // The UnreachableInst indicates that a particular portion of the code is not
 // reachable (e.g. code after a no-return function).
@@ -131,11 +384,22 @@ type SwitchInst struct {
 // References:
 //    http://llvm.org/docs/LangRef.html#i-unreachable
 type UnreachableInst struct {
+	// Metadata attachments.
+	Metadata
+}
+
+// String returns a string representation of the unreachable instruction.
+func (term *UnreachableInst) String() string {
+	return term.attach("unreachable")
 }
 
 // isTerm ensures that only terminator instructions can be assigned to the
 // Terminator interface.
-func (ReturnInst) isTerm()     {}
-func (CondBranchInst) isTerm() {}
-func (BranchInst) isTerm()     {}
-func (SwitchInst) isTerm()     {}
+func (ReturnInst) isTerm()      {}
+func (CondBranchInst) isTerm()  {}
+func (BranchInst) isTerm()      {}
+func (SwitchInst) isTerm()      {}
+func (ResumeInst) isTerm()      {}
+func (IndirectbrInst) isTerm()  {}
+func (UnreachableInst) isTerm() {}
+func (InvokeInst) isTerm()      {}
@@ -0,0 +1,175 @@
+package ir
+
+import "github.com/llir/llvm/values"
+
+// A UseGraph records the use-def relation between the values and the
+// non-terminator instructions of a function.
+//
+// Local variable operands are resolved by name to the instruction defining
+// them, so that a use is recorded regardless of which *values.Local refers to
+// the result. Should several instructions define the same name, a use refers
+// to the closest preceding definition in program order, or to the first
+// definition if none precedes it (e.g. a phi incoming value from a back edge).
+type UseGraph struct {
+	// Instructions using the result of each instruction, in program order.
+	instUsers map[Instruction][]Instruction
+	// Number of terminators using the result of each instruction.
+	termUses map[Instruction]int
+	// Instructions using each value which is not the result of an instruction
+	// of the function, in program order. Local variables referring to function
+	// parameters are keyed by the parameter; other values are compared by
+	// identity.
+	users map[values.Value][]Instruction
+	// Value operands of each instruction.
+	operands map[Instruction][]values.Value
+	// Definitions of each named result in program order, mapping from local
+	// name to definitions.
+	defs map[string][]def
+	// Function parameters, mapping from parameter name to parameter.
+	params map[string]*values.Param
+}
+
+// A def is the definition of a named result.
+type def struct {
+	// Defining instruction.
+	inst Instruction
+	// Position of the instruction in program order.
+	pos int
+}
+
+// BuildUseGraph builds the use-def graph of the non-terminator instructions of
+// the function f.
+//
+// Local variables are resolved by name rather than by pointer identity, as the
+// operands of instructions are distinct *values.Local values referring to the
+// named results of other instructions. Should several instructions define the
+// same name, Users and Defs resolve the name to the first definition only; use
+// ResultUsers to query the users of a specific instruction.
+func BuildUseGraph(f *Function) *UseGraph {
+	g := &UseGraph{
+		instUsers: make(map[Instruction][]Instruction),
+		termUses:  make(map[Instruction]int),
+		users:     make(map[values.Value][]Instruction),
+		operands:  make(map[Instruction][]values.Value),
+		defs:      make(map[string][]def),
+		params:    make(map[string]*values.Param),
+	}
+	for _, param := range f.Params {
+		g.params[param.Name] = param
+	}
+	pos := 0
+	for _, block := range f.Blocks {
+		for _, inst := range block.Insts {
+			if res, ok := inst.(result); ok && len(res.name()) > 0 && !isVoid(inst) {
+				g.defs[res.name()] = append(g.defs[res.name()], def{inst: inst, pos: pos})
+			}
+			pos++
+		}
+		pos++
+	}
+
+	pos = 0
+	for _, block := range f.Blocks {
+		for _, inst := range block.Insts {
+			ops := inst.Operands()
+			g.operands[inst] = ops
+			for _, op := range ops {
+				if op == nil {
+					continue
+				}
+				if d := g.resolve(op, pos); d != nil {
+					g.instUsers[d] = appendUser(g.instUsers[d], inst)
+					continue
+				}
+				key := op
+				if op, ok := op.(*values.Local); ok {
+					if param, ok := g.params[op.Name]; ok {
+						key = param
+					}
+				}
+				g.users[key] = appendUser(g.users[key], inst)
+			}
+			pos++
+		}
+		if block.Term != nil {
+			for _, op := range block.Term.Operands() {
+				if d := g.resolve(op, pos); d != nil {
+					g.termUses[d]++
+				}
+			}
+		}
+		pos++
+	}
+	return g
+}
+
+// resolve returns the instruction defining the local variable v, as used at
+// the given position in program order, or nil if v is not the result of an
+// instruction of the function.
+func (g *UseGraph) resolve(v values.Value, pos int) Instruction {
+	local, ok := v.(*values.Local)
+	if !ok {
+		return nil
+	}
+	defs := g.defs[local.Name]
+	if len(defs) == 0 {
+		return nil
+	}
+	d := defs[0]
+	for _, def := range defs[1:] {
+		if def.pos >= pos {
+			break
+		}
+		d = def
+	}
+	return d.inst
+}
+
+// appendUser appends the instruction inst to the given users, unless it is
+// already the last user. Each user is thereby recorded only once, even if the
+// value occupies several of its operand slots.
+func appendUser(users []Instruction, inst Instruction) []Instruction {
+	if n := len(users); n > 0 && users[n-1] == inst {
+		return users
+	}
+	return append(users, inst)
+}
+
+// Users returns the instructions using the value v, in program order. A local
+// variable is resolved by name to its first defining instruction or to the
+// function parameter of the same name; other values are compared by identity.
+func (g *UseGraph) Users(v values.Value) []Instruction {
+	if d := g.Defs(v); d != nil {
+		return g.instUsers[d]
+	}
+	if local, ok := v.(*values.Local); ok {
+		if param, ok := g.params[local.Name]; ok {
+			return g.users[param]
+		}
+	}
+	return g.users[v]
+}
+
+// ResultUsers returns the instructions using the result of the instruction
+// inst, in program order.
+func (g *UseGraph) ResultUsers(inst Instruction) []Instruction {
+	return g.instUsers[inst]
+}
+
+// Operands returns the value operands of the instruction inst, one for each
+// operand slot.
+func (g *UseGraph) Operands(inst Instruction) []values.Value {
+	return g.operands[inst]
+}
+
+// Defs returns the first instruction defining the local variable v, or nil if v
+// is not the result of an instruction of the function (e.g. a function
+// parameter or a constant).
+func (g *UseGraph) Defs(v values.Value) Instruction {
+	if v, ok := v.(*values.Local); ok {
+		if defs := g.defs[v.Name]; len(defs) > 0 {
+			return defs[0].inst
+		}
+	}
+	return nil
+}
@@ -0,0 +1,440 @@
+// Package verify implements verification of the type correctness and structure
+// of LLVM IR functions.
+package verify
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/types"
+	"github.com/llir/llvm/values"
+)
+
+// Verify verifies the function f, by checking the operand types of each
+// instruction against its declared types (including the operand kinds of binary
+// and comparison instructions, the function arguments of calls and the indices
+// of aggregate accesses), ensuring that every basic block is terminated, and
+// ensuring that the incoming values of phi instructions correspond to the
+// predecessor basic blocks in the control flow graph. Memory may not be accessed
+// through pointers to opaque structure types, and functions containing
+// landingpad instructions must have a personality function. All problems are
+// reported, rather than only the first.
+func Verify(f *ir.Function) []error {
+	blocks := make(map[string]bool)
+	preds := make(map[string][]string)
+	for _, block := range f.Blocks {
+		blocks[block.Name] = true
+		for _, succ := range block.Successors() {
+			preds[succ.Name] = append(preds[succ.Name], block.Name)
+		}
+	}
+
+	var errs []error
+	for _, block := range f.Blocks {
+		for _, inst := range block.Insts {
+			if err := checkInst(inst, blocks); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %v", block.Ident(), err))
+				continue
+			}
+			switch inst := inst.(type) {
+			case *ir.PhiInst:
+				if err := inst.Validate(preds[block.Name]); err != nil {
+					errs = append(errs, fmt.Errorf("%s: %v", block.Ident(), err))
+				}
+			case *ir.LandingpadInst:
+				if f.Personality == nil {
+					errs = append(errs, fmt.Errorf("%s: landingpad in function %s without personality", block.Ident(), f.Ident()))
+				}
+			}
+		}
+		if block.Term == nil {
+			errs = append(errs, fmt.Errorf("%s: missing terminator", block.Ident()))
+			continue
+		}
+		if err := checkTerm(block.Term, f.Sig, blocks); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", block.Ident(), err))
+		}
+	}
+	return errs
+}
+
+// MustVerify verifies every function of the module m, and panics with the
+// reported problems if any function is invalid. It is intended for use in tests
+// and debug builds, directly after a transformation.
+func MustVerify(m *ir.Module) {
+	var msgs []string
+	for _, f := range m.Funcs {
+		for _, err := range Verify(f) {
+			msgs = append(msgs, fmt.Sprintf("%s: %v", f.Ident(), err))
+		}
+	}
+	if len(msgs) > 0 {
+		panic(fmt.Sprintf("invalid module;\n%s", strings.Join(msgs, "\n")))
+	}
+}
+
+// checkInst verifies the operand types of the given instruction. The names of
+// the basic blocks of the function are used to verify phi instructions.
+func checkInst(inst ir.Instruction, blocks map[string]bool) error {
+	switch inst := inst.(type) {
+	// Binary instructions.
+	case *ir.AddInst:
+		return checkBinary(inst, inst.Type, inst.Op1, inst.Op2)
+	case *ir.FaddInst:
+		return checkBinary(inst, inst.Type, inst.Op1, inst.Op2)
+	case *ir.SubInst:
+		return checkBinary(inst, inst.Type, inst.Op1, inst.Op2)
+	case *ir.FsubInst:
+		return checkBinary(inst, inst.Type, inst.Op1, inst.Op2)
+	case *ir.MulInst:
+		return checkBinary(inst, inst.Type, inst.Op1, inst.Op2)
+	case *ir.FmulInst:
+		return checkBinary(inst, inst.Type, inst.Op1, inst.Op2)
+	case *ir.UdivInst:
+		return checkBinary(inst, inst.Type, inst.Op1, inst.Op2)
+	case *ir.SdivInst:
+		return checkBinary(inst, inst.Type, inst.Op1, inst.Op2)
+	case *ir.FdivInst:
+		return checkBinary(inst, inst.Type, inst.Op1, inst.Op2)
+	case *ir.UremInst:
+		return checkBinary(inst, inst.Type, inst.Op1, inst.Op2)
+	case *ir.SremInst:
+		return checkBinary(inst, inst.Type, inst.Op1, inst.Op2)
+	case *ir.FremInst:
+		return checkBinary(inst, inst.Type, inst.Op1, inst.Op2)
+
+	// Bitwise binary instructions.
+	case *ir.ShlInst:
+		return checkBinary(inst, inst.Type, inst.Op1, inst.Op2)
+	case *ir.LshrInst:
+		return checkBinary(inst, inst.Type, inst.Op1, inst.Op2)
+	case *ir.AshrInst:
+		return checkBinary(inst, inst.Type, inst.Op1, inst.Op2)
+	case *ir.AndInst:
+		return checkBinary(inst, inst.Type, inst.Op1, inst.Op2)
+	case *ir.OrInst:
+		return checkBinary(inst, inst.Type, inst.Op1, inst.Op2)
+	case *ir.XorInst:
+		return checkBinary(inst, inst.Type, inst.Op1, inst.Op2)
+
+	// Memory instructions.
+	case *ir.LoadInst:
+		if err := checkRange(inst.Type, inst.Metadata); err != nil {
+			return err
+		}
+		return checkAddr("load", inst.Type, inst.Addr)
+	case *ir.StoreInst:
+		if err := checkType("store", "value", inst.Type, inst.Val); err != nil {
+			return err
+		}
+		return checkAddr("store", inst.Type, inst.Addr)
+	case *ir.GetelementptrInst:
+		if err := checkAddr("getelementptr", inst.Type, inst.Ptr); err != nil {
+			return err
+		}
+		// Constant indices into structures are bounds checked by ResultType.
+		if _, err := inst.ResultType(); err != nil {
+			return err
+		}
+		if inst.InBounds {
+			return checkInBounds(inst)
+		}
+
+	// Conversion instructions.
+	case *ir.TruncInst:
+		if err := checkConv("trunc", inst.From, inst.To); err != nil {
+			return err
+		}
+		_, err := ir.NewTrunc(inst.From, inst.To)
+		return err
+	case *ir.ZextInst:
+		if err := checkConv("zext", inst.From, inst.To); err != nil {
+			return err
+		}
+		_, err := ir.NewZext(inst.From, inst.To)
+		return err
+	case *ir.SextInst:
+		if err := checkConv("sext", inst.From, inst.To); err != nil {
+			return err
+		}
+		_, err := ir.NewSext(inst.From, inst.To)
+		return err
+	case *ir.FptruncInst:
+		if err := checkConv("fptrunc", inst.From, inst.To); err != nil {
+			return err
+		}
+		_, err := ir.NewFptrunc(inst.From, inst.To)
+		return err
+	case *ir.FpextInst:
+		if err := checkConv("fpext", inst.From, inst.To); err != nil {
+			return err
+		}
+		_, err := ir.NewFpext(inst.From, inst.To)
+		return err
+	case *ir.FptouiInst:
+		if err := checkConv("fptoui", inst.From, inst.To); err != nil {
+			return err
+		}
+		_, err := ir.NewFptoui(inst.From, inst.To)
+		return err
+	case *ir.FptosiInst:
+		if err := checkConv("fptosi", inst.From, inst.To); err != nil {
+			return err
+		}
+		_, err := ir.NewFptosi(inst.From, inst.To)
+		return err
+	case *ir.UitofpInst:
+		if err := checkConv("uitofp", inst.From, inst.To); err != nil {
+			return err
+		}
+		_, err := ir.NewUitofp(inst.From, inst.To)
+		return err
+	case *ir.SitofpInst:
+		if err := checkConv("sitofp", inst.From, inst.To); err != nil {
+			return err
+		}
+		_, err := ir.NewSitofp(inst.From, inst.To)
+		return err
+	case *ir.PtrtointInst:
+		if err := checkConv("ptrtoint", inst.From, inst.To); err != nil {
+			return err
+		}
+		_, err := ir.NewPtrtoint(inst.From, inst.To)
+		return err
+	case *ir.InttoptrInst:
+		if err := checkConv("inttoptr", inst.From, inst.To); err != nil {
+			return err
+		}
+		_, err := ir.NewInttoptr(inst.From, inst.To)
+		return err
+	case *ir.BitcastInst:
+		if err := checkConv("bitcast", inst.From, inst.To); err != nil {
+			return err
+		}
+		_, err := ir.NewBitcast(inst.From, inst.To)
+		return err
+	case *ir.AddrspacecastInst:
+		if err := checkConv("addrspacecast", inst.From, inst.To); err != nil {
+			return err
+		}
+		_, err := ir.NewAddrspacecast(inst.From, inst.To)
+		return err
+
+	// Aggregate instructions.
+	case *ir.ExtractvalueInst:
+		if inst.Aggregate == nil {
+			return errors.New("extractvalue aggregate missing")
+		}
+		elem, err := ir.NewExtractvalue(inst.Aggregate, inst.Indices)
+		if err != nil {
+			return err
+		}
+		if !elem.Type.Equal(inst.Type) {
+			return fmt.Errorf("extractvalue result type mismatch; expected %q, got %q", elem.Type, inst.Type)
+		}
+	case *ir.InsertvalueInst:
+		if inst.Aggregate == nil {
+			return errors.New("insertvalue aggregate missing")
+		}
+		if inst.Element == nil {
+			return errors.New("insertvalue element missing")
+		}
+		_, err := ir.NewInsertvalue(inst.Aggregate, inst.Element, inst.Indices)
+		return err
+
+	// Other instructions.
+	case *ir.IcmpInst:
+		return checkBinary(inst, inst.Type, inst.Op1, inst.Op2)
+	case *ir.FcmpInst:
+		return checkBinary(inst, inst.Type, inst.Op1, inst.Op2)
+	case *ir.PhiInst:
+		for _, pred := range sortedPreds(inst.Preds) {
+			if !blocks[pred] {
+				return fmt.Errorf("invalid phi predecessor; basic block %q does not exist", pred)
+			}
+			if err := checkType("phi", "incoming value", inst.Type, inst.Preds[pred]); err != nil {
+				return err
+			}
+		}
+	case *ir.SelectInst:
+		switch {
+		case inst.Cond == nil:
+			return errors.New("select condition missing")
+		case inst.TrueValue == nil:
+			return errors.New("select true value missing")
+		case inst.FalseValue == nil:
+			return errors.New("select false value missing")
+		}
+		if !inst.TrueValue.Type().Equal(inst.FalseValue.Type()) {
+			return fmt.Errorf("select operand type mismatch; %q and %q", inst.TrueValue.Type(), inst.FalseValue.Type())
+		}
+	case *ir.CallInst:
+		if err := checkRange(inst.Type, inst.Metadata); err != nil {
+			return err
+		}
+		return inst.Validate()
+	}
+	return nil
+}
+
+// checkRange verifies the range metadata (if any) attached to an instruction of
+// the given result type.
+func checkRange(typ types.Type, md ir.Metadata) error {
+	node, ok := md["range"]
+	if !ok {
+		return nil
+	}
+	return ir.ValidateRange(typ, node)
+}
+
+// checkTerm verifies the operand types of the given terminator of a function
+// with the signature sig. The names of the basic blocks of the function are used
+// to verify the targets of indirectbr instructions.
+func checkTerm(term ir.Terminator, sig *types.Func, blocks map[string]bool) error {
+	switch term := term.(type) {
+	case *ir.ReturnInst:
+		if sig == nil {
+			return nil
+		}
+		if term.Val == nil {
+			if _, ok := sig.Result().(*types.Void); !ok {
+				return fmt.Errorf("ret type mismatch; expected %q, got void", sig.Result())
+			}
+			return nil
+		}
+		return checkType("ret", "value", sig.Result(), term.Val)
+	case *ir.CondBranchInst:
+		if term.Cond == nil {
+			return errors.New("br condition missing")
+		}
+		if typ, ok := term.Cond.Type().(*types.Int); !ok || typ.Size() != 1 {
+			return fmt.Errorf("br condition type mismatch; expected i1, got %q", term.Cond.Type())
+		}
+		if term.True == nil || term.False == nil {
+			return errors.New("br target missing")
+		}
+	case *ir.SwitchInst:
+		return checkType("switch", "comparison value", term.Type, term.Val)
+	case *ir.IndirectbrInst:
+		if term.Addr == nil {
+			return errors.New("indirectbr address missing")
+		}
+		if _, err := ir.NewIndirectbr(term.Addr, term.Targets); err != nil {
+			return err
+		}
+		for _, target := range term.Targets {
+			if target == nil {
+				return errors.New("indirectbr target missing")
+			}
+			if !blocks[target.Name] {
+				return fmt.Errorf("invalid indirectbr target; basic block %q does not exist", target.Name)
+			}
+		}
+	case *ir.ResumeInst:
+		if term.Val == nil {
+			return errors.New("resume value missing")
+		}
+	case *ir.InvokeInst:
+		return term.Validate()
+	}
+	return nil
+}
+
+// checkBinary verifies that the operands of the given binary (or comparison)
+// instruction are of the declared operand type, and that they are of the kind
+// accepted by the instruction (e.g. integers for add, and floating points for
+// fadd).
+func checkBinary(inst ir.Instruction, typ types.Type, op1, op2 values.Value) error {
+	mnem := ir.Opcode(inst)
+	if err := checkType(mnem, "first operand", typ, op1); err != nil {
+		return err
+	}
+	if err := checkType(mnem, "second operand", typ, op2); err != nil {
+		return err
+	}
+	// The operand kinds are verified by type inference.
+	_, err := ir.InferType(inst)
+	return err
+}
+
+// checkType verifies that the value v of the given instruction is of the
+// declared type.
+func checkType(mnem, desc string, typ types.Type, v values.Value) error {
+	if v == nil {
+		return fmt.Errorf("%s %s missing", mnem, desc)
+	}
+	if !v.Type().Equal(typ) {
+		return fmt.Errorf("%s %s type mismatch; expected %q, got %q", mnem, desc, typ, v.Type())
+	}
+	return nil
+}
+
+// checkConv verifies that the original value and the target type of the given
+// conversion instruction are present.
+func checkConv(mnem string, from values.Value, to types.Type) error {
+	if from == nil {
+		return errors.New(mnem + " value missing")
+	}
+	if to == nil {
+		return errors.New(mnem + " target type missing")
+	}
+	return nil
+}
+
+// checkInBounds verifies that the constant indices into arrays of the given
+// inbounds getelementptr instruction are within bounds. The last index may
+// address the element one past the end of the array.
+func checkInBounds(inst *ir.GetelementptrInst) error {
+	t := inst.Type
+	for i := 1; i < len(inst.Indicies); i++ {
+		index := inst.Indicies[i]
+		switch typ := t.(type) {
+		case *types.Array:
+			n := typ.Len()
+			if i == len(inst.Indicies)-1 {
+				n++
+			}
+			if index < 0 || index >= n {
+				return fmt.Errorf("invalid inbounds getelementptr; index (%d) out of range for %q", index, typ)
+			}
+			t = typ.Elem()
+		case *types.Struct:
+			t, _ = typ.FieldAt(index)
+		case *types.IdentifiedStruct:
+			t, _ = typ.FieldAt(index)
+		case *types.Vector:
+			t = typ.Elem()
+		}
+	}
+	return nil
+}
+
+// checkAddr verifies that the address operand of the given memory instruction
+// is a pointer to the declared type, which must not be an opaque structure.
+func checkAddr(mnem string, typ types.Type, addr values.Value) error {
+	if addr == nil {
+		return errors.New(mnem + " address missing")
+	}
+	ptr, ok := addr.Type().(*types.Pointer)
+	if !ok || !ptr.Elem().Equal(typ) {
+		return fmt.Errorf("%s address type mismatch; expected pointer to %q, got %q", mnem, typ, addr.Type())
+	}
+	if t, ok := typ.(*types.IdentifiedStruct); ok && t.IsOpaque() {
+		return fmt.Errorf("%s through pointer to opaque type %q", mnem, typ)
+	}
+	return nil
+}
+
+// sortedPreds returns the predecessor basic block names of the given phi
+// incoming values in sorted order.
+func sortedPreds(preds map[string]values.Value) []string {
+	var names []string
+	for name := range preds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
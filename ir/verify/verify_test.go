@@ -0,0 +1,637 @@
+package verify_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/llir/llvm/consts"
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/verify"
+	"github.com/llir/llvm/types"
+	"github.com/llir/llvm/values"
+)
+
+func TestVerify(t *testing.T) {
+	sig, err := types.NewFunc(types.I32, []types.Type{types.I32}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := values.NewParam("x", types.I32)
+	i32FortyTwo, err := consts.NewInt(types.I32, "42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	i64FortyTwo, err := consts.NewInt(types.I64, "42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// %handle = type opaque
+	handle := types.NewIdentifiedStruct("handle")
+	handlePtr, err := types.NewPointer(handle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := values.NewLocal("h", handlePtr)
+	v := values.NewLocal("v", handle)
+	// {i8*, i32} %lp
+	i8Ptr, err := types.NewPointer(types.I8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lpTyp, err := types.NewStruct([]types.Type{i8Ptr, types.I32}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lp := values.NewLocal("lp", lpTyp)
+	personality := values.NewLocal("personality", i8Ptr)
+	// i32 (i32)* %g
+	gTyp, err := types.NewPointer(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := values.NewLocal("g", gTyp)
+	// {i8*, i32}* %s
+	lpPtr, err := types.NewPointer(lpTyp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := values.NewLocal("s", lpPtr)
+	agg := values.NewLocal("agg", lpTyp)
+	// [4 x i32]* %a
+	arrTyp, err := types.NewArray(types.I32, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	arrPtr, err := types.NewPointer(arrTyp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := values.NewLocal("a", arrPtr)
+	y := values.NewLocal("y", types.F32)
+	// i32* %p
+	i32Ptr, err := types.NewPointer(types.I32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := values.NewLocal("p", i32Ptr)
+	i32Zero, err := consts.NewInt(types.I32, "0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// newLoad returns a load of %p with the given range metadata attached.
+	newLoad := func(lo, hi values.Value) *ir.LoadInst {
+		node := &ir.MetadataNode{ID: 0, Fields: []values.Value{lo, hi}}
+		return &ir.LoadInst{LocalIdent: ir.LocalIdent{Name: "r"}, Type: types.I32, Addr: p, Metadata: ir.Metadata{"range": node}}
+	}
+	exit := &ir.BasicBlock{Name: "exit", Term: ir.NewRet(x)}
+
+	golden := []struct {
+		blocks      []*ir.BasicBlock
+		personality values.Value
+		want        []string
+	}{
+		// i=0
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name:  "entry",
+					Insts: []ir.Instruction{&ir.AddInst{Type: types.I32, Op1: x, Op2: i32FortyTwo}},
+					Term:  ir.NewRet(x),
+				},
+			},
+			want: nil,
+		},
+		// i=1
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name:  "entry",
+					Insts: []ir.Instruction{&ir.AddInst{Type: types.I32, Op1: x, Op2: i32FortyTwo}},
+				},
+			},
+			want: []string{"%entry: missing terminator"},
+		},
+		// i=2
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name:  "entry",
+					Insts: []ir.Instruction{&ir.AddInst{Type: types.I32, Op1: x, Op2: i64FortyTwo}},
+					Term:  ir.NewRet(x),
+				},
+			},
+			want: []string{`%entry: add second operand type mismatch; expected "i32", got "i64"`},
+		},
+		// i=3
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name:  "entry",
+					Insts: []ir.Instruction{&ir.AddInst{Type: types.I32, Op1: x, Op2: i64FortyTwo}},
+				},
+				{
+					Name:  "exit",
+					Insts: []ir.Instruction{&ir.PhiInst{Type: types.I32, Preds: map[string]values.Value{"foo": x}}},
+					Term:  ir.NewRet(i64FortyTwo),
+				},
+			},
+			want: []string{
+				`%entry: add second operand type mismatch; expected "i32", got "i64"`,
+				"%entry: missing terminator",
+				`%exit: invalid phi predecessor; basic block "foo" does not exist`,
+				`%exit: ret value type mismatch; expected "i32", got "i64"`,
+			},
+		},
+		// i=4
+		{
+			blocks: diamond(&ir.PhiInst{Type: types.I32, Preds: map[string]values.Value{"true": x}}),
+			want:   []string{`%join: invalid phi; missing incoming value for predecessor basic block "false"`},
+		},
+		// i=5
+		{
+			blocks: diamond(&ir.PhiInst{Type: types.I32, Preds: map[string]values.Value{"true": x, "false": i32FortyTwo, "entry": x}}),
+			want:   []string{`%join: invalid phi incoming value for "entry"; not a predecessor basic block`},
+		},
+		// i=6
+		{
+			blocks: diamond(&ir.PhiInst{Type: types.I32, Preds: map[string]values.Value{"true": x, "false": i32FortyTwo}}),
+			want:   nil,
+		},
+		// i=7
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name: "entry",
+					Insts: []ir.Instruction{
+						&ir.LoadInst{Type: handle, Addr: h},
+						&ir.StoreInst{Type: handle, Val: v, Addr: h},
+					},
+					Term: ir.NewRet(x),
+				},
+			},
+			want: []string{
+				`%entry: load through pointer to opaque type "%handle"`,
+				`%entry: store through pointer to opaque type "%handle"`,
+			},
+		},
+		// i=8
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name:  "unwind",
+					Insts: []ir.Instruction{&ir.LandingpadInst{Type: lpTyp, Cleanup: true}},
+					Term:  &ir.ResumeInst{Val: lp},
+				},
+			},
+			want: []string{"%unwind: landingpad in function @f without personality"},
+		},
+		// i=9
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name:  "unwind",
+					Insts: []ir.Instruction{&ir.LandingpadInst{Type: lpTyp, Cleanup: true}},
+					Term:  &ir.ResumeInst{Val: lp},
+				},
+			},
+			personality: personality,
+			want:        nil,
+		},
+		// i=10
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name:  "entry",
+					Insts: []ir.Instruction{&ir.CallInst{Type: types.I32, Callee: g, Args: []values.Value{i64FortyTwo}}},
+					Term:  ir.NewRet(x),
+				},
+				exit,
+			},
+			want: []string{`%entry: invalid function argument 0; expected "i32", got "i64"`},
+		},
+		// i=11
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name:  "entry",
+					Insts: []ir.Instruction{&ir.CallInst{Type: types.I64, Callee: g, Args: []values.Value{x}}},
+					Term:  ir.NewRet(x),
+				},
+				exit,
+			},
+			want: []string{`%entry: invalid call result type; expected "i32", got "i64"`},
+		},
+		// i=12
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name:  "entry",
+					Insts: []ir.Instruction{&ir.TruncInst{From: x, To: types.I64}},
+					Term:  ir.NewRet(x),
+				},
+				exit,
+			},
+			want: []string{"%entry: invalid integer truncation; target size (64) not smaller than original size (32)"},
+		},
+		// i=13
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name:  "entry",
+					Insts: []ir.Instruction{&ir.ZextInst{To: types.I64}},
+					Term:  ir.NewRet(x),
+				},
+				exit,
+			},
+			want: []string{"%entry: zext value missing"},
+		},
+		// i=14
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name:  "entry",
+					Insts: []ir.Instruction{&ir.GetelementptrInst{Type: lpTyp, Ptr: s, Indicies: []int{0, 2}}},
+					Term:  ir.NewRet(x),
+				},
+				exit,
+			},
+			want: []string{`%entry: invalid getelementptr; index (2) out of range for "{i8*, i32}"`},
+		},
+		// i=15
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name:  "entry",
+					Insts: []ir.Instruction{&ir.GetelementptrInst{Type: types.I32, Ptr: s, Indicies: []int{0}}},
+					Term:  ir.NewRet(x),
+				},
+				exit,
+			},
+			want: []string{`%entry: getelementptr address type mismatch; expected pointer to "i32", got "{i8*, i32}*"`},
+		},
+		// i=16
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name:  "entry",
+					Insts: []ir.Instruction{&ir.ExtractvalueInst{Type: types.I32, Aggregate: agg, Indices: []int{2}}},
+					Term:  ir.NewRet(x),
+				},
+				exit,
+			},
+			want: []string{`%entry: invalid extractvalue; index (2) out of range for "{i8*, i32}"`},
+		},
+		// i=17
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name:  "entry",
+					Insts: []ir.Instruction{&ir.ExtractvalueInst{Type: types.I64, Aggregate: agg, Indices: []int{1}}},
+					Term:  ir.NewRet(x),
+				},
+				exit,
+			},
+			want: []string{`%entry: extractvalue result type mismatch; expected "i32", got "i64"`},
+		},
+		// i=18
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name:  "entry",
+					Insts: []ir.Instruction{&ir.InsertvalueInst{Aggregate: agg, Element: i64FortyTwo, Indices: []int{1}}},
+					Term:  ir.NewRet(x),
+				},
+				exit,
+			},
+			want: []string{`%entry: invalid insertvalue; element type mismatch; expected "i32", got "i64"`},
+		},
+		// i=19
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name:  "entry",
+					Insts: []ir.Instruction{&ir.SelectInst{Type: types.I32, TrueValue: x, FalseValue: x}},
+					Term:  ir.NewRet(x),
+				},
+				exit,
+			},
+			want: []string{"%entry: select condition missing"},
+		},
+		// i=20
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name: "entry",
+					Term: &ir.CondBranchInst{True: exit, False: exit},
+				},
+				exit,
+			},
+			want: []string{"%entry: br condition missing"},
+		},
+		// i=21
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name: "entry",
+					Term: &ir.IndirectbrInst{Addr: personality, Targets: []*ir.BasicBlock{{Name: "foo"}}},
+				},
+				exit,
+			},
+			want: []string{`%entry: invalid indirectbr target; basic block "foo" does not exist`},
+		},
+		// i=22
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name: "entry",
+					Term: &ir.IndirectbrInst{Addr: x, Targets: []*ir.BasicBlock{exit}},
+				},
+				exit,
+			},
+			want: []string{`%entry: invalid indirectbr address; expected pointer, got "i32"`},
+		},
+		// i=23
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name: "entry",
+					Term: &ir.ResumeInst{},
+				},
+				exit,
+			},
+			want: []string{"%entry: resume value missing"},
+		},
+		// i=24
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name: "entry",
+					Term: &ir.InvokeInst{Type: types.I32, Callee: g, Normal: exit, Unwind: exit},
+				},
+				exit,
+			},
+			want: []string{"%entry: invalid number of function arguments; expected 1, got 0"},
+		},
+		// i=25
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name:  "entry",
+					Insts: []ir.Instruction{&ir.GetelementptrInst{Type: arrTyp, Ptr: a, Indicies: []int{0, 5}, InBounds: true}},
+					Term:  ir.NewRet(x),
+				},
+			},
+			want: []string{`%entry: invalid inbounds getelementptr; index (5) out of range for "[4 x i32]"`},
+		},
+		// i=26
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name:  "entry",
+					Insts: []ir.Instruction{&ir.GetelementptrInst{Type: arrTyp, Ptr: a, Indicies: []int{0, 4}, InBounds: true}},
+					Term:  ir.NewRet(x),
+				},
+			},
+			want: nil,
+		},
+		// i=27
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name:  "entry",
+					Insts: []ir.Instruction{&ir.GetelementptrInst{Type: arrTyp, Ptr: a, Indicies: []int{0, 5}}},
+					Term:  ir.NewRet(x),
+				},
+			},
+			want: nil,
+		},
+		// i=28
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name:  "entry",
+					Insts: []ir.Instruction{&ir.AddInst{Type: types.F32, Op1: y, Op2: y}},
+					Term:  ir.NewRet(x),
+				},
+			},
+			want: []string{`%entry: invalid add operand type; expected integer (or vector of integers), got "float"`},
+		},
+		// i=29
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name:  "entry",
+					Insts: []ir.Instruction{&ir.SdivInst{Type: types.F32, Op1: y, Op2: y}},
+					Term:  ir.NewRet(x),
+				},
+			},
+			want: []string{`%entry: invalid sdiv operand type; expected integer (or vector of integers), got "float"`},
+		},
+		// i=30
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name:  "entry",
+					Insts: []ir.Instruction{&ir.ShlInst{Type: types.F32, Op1: y, Op2: y}},
+					Term:  ir.NewRet(x),
+				},
+			},
+			want: []string{`%entry: invalid shl operand type; expected integer (or vector of integers), got "float"`},
+		},
+		// i=31
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name:  "entry",
+					Insts: []ir.Instruction{&ir.XorInst{Type: types.F32, Op1: y, Op2: y}},
+					Term:  ir.NewRet(x),
+				},
+			},
+			want: []string{`%entry: invalid xor operand type; expected integer (or vector of integers), got "float"`},
+		},
+		// i=32
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name:  "entry",
+					Insts: []ir.Instruction{&ir.FaddInst{Type: types.I32, Op1: x, Op2: i32FortyTwo}},
+					Term:  ir.NewRet(x),
+				},
+			},
+			want: []string{`%entry: invalid fadd operand type; expected floating point (or vector of floating points), got "i32"`},
+		},
+		// i=33
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name:  "entry",
+					Insts: []ir.Instruction{&ir.FremInst{Type: types.I32, Op1: x, Op2: i32FortyTwo}},
+					Term:  ir.NewRet(x),
+				},
+			},
+			want: []string{`%entry: invalid frem operand type; expected floating point (or vector of floating points), got "i32"`},
+		},
+		// i=34
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name:  "entry",
+					Insts: []ir.Instruction{&ir.IcmpInst{Pred: ir.IntEq, Type: types.F32, Op1: y, Op2: y}},
+					Term:  ir.NewRet(x),
+				},
+			},
+			want: []string{`%entry: invalid icmp operand type; expected integer or pointer (or vector thereof), got "float"`},
+		},
+		// i=35
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name:  "entry",
+					Insts: []ir.Instruction{&ir.FcmpInst{Pred: ir.FloatOeq, Type: types.I32, Op1: x, Op2: i32FortyTwo}},
+					Term:  ir.NewRet(x),
+				},
+			},
+			want: []string{`%entry: invalid fcmp operand type; expected floating point (or vector of floating points), got "i32"`},
+		},
+		// i=36
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name:  "entry",
+					Insts: []ir.Instruction{newLoad(i32Zero, i32FortyTwo)},
+					Term:  ir.NewRet(x),
+				},
+			},
+		},
+		// i=37
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name:  "entry",
+					Insts: []ir.Instruction{newLoad(i32FortyTwo, i32Zero)},
+					Term:  ir.NewRet(x),
+				},
+			},
+			want: []string{"%entry: invalid range metadata [42, 0); expected lower bound less than upper bound"},
+		},
+		// i=38
+		{
+			blocks: []*ir.BasicBlock{
+				{
+					Name:  "entry",
+					Insts: []ir.Instruction{newLoad(i32Zero, i64FortyTwo)},
+					Term:  ir.NewRet(x),
+				},
+			},
+			want: []string{`%entry: invalid range metadata bound i64 42; expected constant of type "i32"`},
+		},
+	}
+
+	for i, g := range golden {
+		f := &ir.Function{Name: "f", Sig: sig, Blocks: g.blocks, Personality: g.personality}
+		errs := verify.Verify(f)
+		var got []string
+		for _, err := range errs {
+			got = append(got, err.Error())
+		}
+		if strings.Join(got, "\n") != strings.Join(g.want, "\n") {
+			t.Errorf("i=%d: errors mismatch; expected %q, got %q", i, g.want, got)
+		}
+	}
+}
+
+func TestMustVerify(t *testing.T) {
+	sig, err := types.NewFunc(types.I32, []types.Type{types.I32}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := values.NewParam("x", types.I32)
+	valid := &ir.Function{Name: "f", Sig: sig, Params: []*values.Param{x}}
+	valid.AppendBlock(&ir.BasicBlock{Name: "entry", Term: ir.NewRet(x)})
+	invalid := &ir.Function{Name: "g", Sig: sig, Params: []*values.Param{x}}
+	invalid.AppendBlock(&ir.BasicBlock{Name: "entry"})
+	decl := &ir.Function{Name: "h", Sig: sig}
+
+	golden := []struct {
+		funcs []*ir.Function
+		want  string
+	}{
+		// i=0
+		{
+			funcs: []*ir.Function{valid, decl},
+		},
+		// i=1
+		{
+			funcs: []*ir.Function{valid, invalid},
+			want:  "invalid module;\n@g: %entry: missing terminator",
+		},
+	}
+
+	for i, g := range golden {
+		m := &ir.Module{Funcs: g.funcs}
+		got := func() (msg string) {
+			defer func() {
+				if e := recover(); e != nil {
+					msg = fmt.Sprint(e)
+				}
+			}()
+			verify.MustVerify(m)
+			return ""
+		}()
+		if got != g.want {
+			t.Errorf("i=%d: panic mismatch; expected %q, got %q", i, g.want, got)
+		}
+	}
+}
+
+func TestBuilderIcmp(t *testing.T) {
+	sig, err := types.NewFunc(types.I32, []types.Type{types.I32}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := values.NewParam("x", types.I32)
+	i32FortyTwo, err := consts.NewInt(types.I32, "42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := &ir.BasicBlock{Name: "entry"}
+	less := &ir.BasicBlock{Name: "less", Term: ir.NewRet(x)}
+	other := &ir.BasicBlock{Name: "other", Term: ir.NewRet(i32FortyTwo)}
+	f := &ir.Function{Name: "f", Sig: sig, Params: []*values.Param{x}}
+	f.AppendBlock(entry)
+	f.AppendBlock(less)
+	f.AppendBlock(other)
+
+	// The result of icmp is of type i1, and may therefore be used as the
+	// condition of br.
+	b := ir.NewBuilder(entry)
+	cond := b.CreateIcmp(ir.IntSlt, x, i32FortyTwo)
+	if !cond.Type().Equal(types.I1) {
+		t.Errorf("type mismatch; expected %v, got %v", types.I1, cond.Type())
+	}
+	b.CreateCondBr(cond, less, other)
+	if errs := verify.Verify(f); len(errs) != 0 {
+		t.Errorf("unexpected errors; %v", errs)
+	}
+}
+
+// diamond returns the basic blocks of an if/else diamond, where the join basic
+// block starts with the given phi instruction.
+func diamond(phi *ir.PhiInst) []*ir.BasicBlock {
+	entry := &ir.BasicBlock{Name: "entry"}
+	t := &ir.BasicBlock{Name: "true"}
+	f := &ir.BasicBlock{Name: "false"}
+	join := &ir.BasicBlock{Name: "join", Insts: []ir.Instruction{phi}, Term: ir.NewRet(phi.Preds["true"])}
+	cond, err := consts.NewInt(types.I1, "true")
+	if err != nil {
+		panic(err)
+	}
+	entry.Term, err = ir.NewCondBr(cond, t, f)
+	if err != nil {
+		panic(err)
+	}
+	t.Term = ir.NewBr(join)
+	f.Term = ir.NewBr(join)
+	return []*ir.BasicBlock{entry, t, f, join}
+}
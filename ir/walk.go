@@ -0,0 +1,30 @@
+package ir
+
+// A Visitor visits the instructions of a module.
+type Visitor interface {
+	// Visit is invoked for each visited instruction.
+	Visit(inst Instruction)
+}
+
+// VisitorFunc is an adapter which allows the use of ordinary functions as
+// visitors. If f is a function with the appropriate signature, VisitorFunc(f)
+// is a Visitor that calls f.
+type VisitorFunc func(inst Instruction)
+
+// Visit calls f(inst).
+func (f VisitorFunc) Visit(inst Instruction) {
+	f(inst)
+}
+
+// Walk visits every non-terminator instruction in every basic block of every
+// function of the module in program order. External function declarations have
+// no basic blocks and are therefore skipped.
+func Walk(m *Module, v Visitor) {
+	for _, f := range m.Funcs {
+		for _, block := range f.Blocks {
+			for _, inst := range block.Insts {
+				v.Visit(inst)
+			}
+		}
+	}
+}
@@ -11,9 +11,12 @@ import "fmt"
 //    http://llvm.org/docs/LangRef.html#void-type
 type Void struct{}
 
-// NewVoid returns a void type.
+// void is the void type; void types have no state and are therefore shared.
+var void = &Void{}
+
+// NewVoid returns the void type.
 func NewVoid() *Void {
-	return &Void{}
+	return void
 }
 
 // Equal returns true if the given types are equal, and false otherwise.
@@ -39,6 +42,16 @@ type Int struct {
 	size int
 }
 
+// Predefined integer types.
+var (
+	I1   = &Int{size: 1}   // i1
+	I8   = &Int{size: 8}   // i8
+	I16  = &Int{size: 16}  // i16
+	I32  = &Int{size: 32}  // i32
+	I64  = &Int{size: 64}  // i64
+	I128 = &Int{size: 128} // i128
+)
+
 // NewInt returns an integer type with the specified number of bits.
 func NewInt(size int) (*Int, error) {
 	// Validate size (from 1 bit to 2^23-1 bits)
@@ -81,6 +94,16 @@ type Float struct {
 	kind FloatKind
 }
 
+// Predefined floating point types.
+var (
+	F16      = &Float{kind: Float16}      // half
+	F32      = &Float{kind: Float32}      // float
+	F64      = &Float{kind: Float64}      // double
+	F128     = &Float{kind: Float128}     // fp128
+	F80_x86  = &Float{kind: Float80_x86}  // x86_fp80
+	F128_PPC = &Float{kind: Float128_PPC} // ppc_fp128
+)
+
 // NewFloat returns a floating point type of the given kind.
 func NewFloat(kind FloatKind) (*Float, error) {
 	switch kind {
@@ -202,9 +225,13 @@ func (*MMX) String() string {
 //    http://llvm.org/docs/LangRef.html#label-type
 type Label struct{}
 
-// NewLabel returns a label type.
+// label is the label type; label types have no state and are therefore
+// shared.
+var label = &Label{}
+
+// NewLabel returns the label type.
 func NewLabel() *Label {
-	return &Label{}
+	return label
 }
 
 // Equal returns true if the given types are equal, and false otherwise.
@@ -30,7 +30,7 @@ func NewFunc(result Type, params []Type, variadic bool) (*Func, error) {
 	// Validate result parameter type (any type except label, metadata and
 	// function).
 	switch result.(type) {
-	case *Void, *Int, *Float, *MMX, *Pointer, *Vector, *Array, *Struct:
+	case *Void, *Int, *Float, *MMX, *Pointer, *Vector, *Array, *Struct, *IdentifiedStruct:
 		// valid type
 	default:
 		return nil, fmt.Errorf("invalid result parameter type %q", result)
@@ -39,7 +39,7 @@ func NewFunc(result Type, params []Type, variadic bool) (*Func, error) {
 	// Validate function parameter types (any type except void and function).
 	for _, param := range params {
 		switch param.(type) {
-		case *Int, *Float, *MMX, *Label, *Metadata, *Pointer, *Vector, *Array, *Struct:
+		case *Int, *Float, *MMX, *Label, *Metadata, *Pointer, *Vector, *Array, *Struct, *IdentifiedStruct:
 			// valid type
 		case *Void:
 			return nil, errors.New("invalid function parameter type; void type only allowed for function results")
@@ -69,16 +69,22 @@ func (t *Func) IsVariadic() bool {
 
 // Equal returns true if the given types are equal, and false otherwise.
 func (t *Func) Equal(u Type) bool {
+	return Equal(t, u)
+}
+
+// equal returns true if the given types are equal, and false otherwise. The
+// pairs of identified structures already being compared are tracked by seen.
+func (t *Func) equal(u Type, seen map[structPair]bool) bool {
 	switch u := u.(type) {
 	case *Func:
-		if !t.result.Equal(u.result) {
+		if !equal(t.result, u.result, seen) {
 			return false
 		}
 		if len(t.params) != len(u.params) {
 			return false
 		}
 		for i := range t.params {
-			if !t.params[i].Equal(u.params[i]) {
+			if !equal(t.params[i], u.params[i], seen) {
 				return false
 			}
 		}
@@ -112,19 +118,29 @@ func (t *Func) String() string {
 //
 // Examples:
 //    int32*
+//    i8 addrspace(1)*
 //
 // References:
 //    http://llvm.org/docs/LangRef.html#pointer-type
 type Pointer struct {
 	// Element type.
 	elem Type
+	// Address space.
+	space int
 }
 
-// NewPointer returns a pointer type for the given element type.
+// NewPointer returns a pointer type for the given element type in the default
+// address space (0).
 func NewPointer(elem Type) (*Pointer, error) {
+	return NewPointerInAddrSpace(elem, 0)
+}
+
+// NewPointerInAddrSpace returns a pointer type for the given element type in
+// the specified address space.
+func NewPointerInAddrSpace(elem Type, space int) (*Pointer, error) {
 	// Validate element type (any type except void, label and metadata).
 	switch elem.(type) {
-	case *Int, *Float, *MMX, *Func, *Pointer, *Vector, *Array, *Struct:
+	case *Int, *Float, *MMX, *Func, *Pointer, *Vector, *Array, *Struct, *IdentifiedStruct:
 		// valid type
 	case *Void:
 		return nil, errors.New(`invalid pointer to "void"; use i8* instead`)
@@ -132,7 +148,12 @@ func NewPointer(elem Type) (*Pointer, error) {
 		return nil, fmt.Errorf("invalid pointer to %q", elem)
 	}
 
-	return &Pointer{elem: elem}, nil
+	// Validate address space (24-bit value).
+	if space < 0 || space >= 1<<24 {
+		return nil, fmt.Errorf("invalid pointer address space (%d)", space)
+	}
+
+	return &Pointer{elem: elem, space: space}, nil
 }
 
 // Elem returns the element type of the pointer.
@@ -140,17 +161,32 @@ func (t *Pointer) Elem() Type {
 	return t.elem
 }
 
+// AddrSpace returns the address space of the pointer.
+func (t *Pointer) AddrSpace() int {
+	return t.space
+}
+
 // Equal returns true if the given types are equal, and false otherwise.
 func (t *Pointer) Equal(u Type) bool {
+	return Equal(t, u)
+}
+
+// equal returns true if the given types are equal, and false otherwise. The
+// pairs of identified structures already being compared are tracked by seen.
+func (t *Pointer) equal(u Type, seen map[structPair]bool) bool {
 	switch u := u.(type) {
 	case *Pointer:
-		return t.elem.Equal(u.elem)
+		return equal(t.elem, u.elem, seen) && t.space == u.space
 	}
 	return false
 }
 
 // String returns a string representation of the pointer type.
 func (t *Pointer) String() string {
+	if t.space != 0 {
+		// i8 addrspace(1)*
+		return fmt.Sprintf("%v addrspace(%d)*", t.Elem(), t.space)
+	}
 	// i32*
 	return fmt.Sprintf("%v*", t.Elem())
 }
@@ -201,11 +237,29 @@ func (t *Vector) Len() int {
 	return t.n
 }
 
+// Size returns the size of t in number of bits. The boolean return value is
+// false for vectors of pointers, as the size of pointers is target dependent.
+func (t *Vector) Size() (int, bool) {
+	switch elem := t.elem.(type) {
+	case *Int:
+		return elem.Size() * t.n, true
+	case *Float:
+		return elem.Size() * t.n, true
+	}
+	return 0, false
+}
+
 // Equal returns true if the given types are equal, and false otherwise.
 func (t *Vector) Equal(u Type) bool {
+	return Equal(t, u)
+}
+
+// equal returns true if the given types are equal, and false otherwise. The
+// pairs of identified structures already being compared are tracked by seen.
+func (t *Vector) equal(u Type, seen map[structPair]bool) bool {
 	switch u := u.(type) {
 	case *Vector:
-		return t.elem.Equal(u.elem) && t.n == u.n
+		return equal(t.elem, u.elem, seen) && t.n == u.n
 	}
 	return false
 }
@@ -236,7 +290,7 @@ func NewArray(elem Type, n int) (*Array, error) {
 	// Validate element type (any type except void, label, metadata and
 	// function).
 	switch elem.(type) {
-	case *Int, *Float, *MMX, *Pointer, *Vector, *Array, *Struct:
+	case *Int, *Float, *MMX, *Pointer, *Vector, *Array, *Struct, *IdentifiedStruct:
 		// valid type
 	case *Void:
 		return nil, errors.New("invalid array element type; void type only allowed for function results")
@@ -262,11 +316,26 @@ func (t *Array) Len() int {
 	return t.n
 }
 
+// ElemAt returns the element type at the given index of the array, or an
+// error if the index is out of range.
+func (t *Array) ElemAt(index int) (Type, error) {
+	if index < 0 || index >= t.n {
+		return nil, fmt.Errorf("index (%d) out of range for %q", index, t)
+	}
+	return t.elem, nil
+}
+
 // Equal returns true if the given types are equal, and false otherwise.
 func (t *Array) Equal(u Type) bool {
+	return Equal(t, u)
+}
+
+// equal returns true if the given types are equal, and false otherwise. The
+// pairs of identified structures already being compared are tracked by seen.
+func (t *Array) equal(u Type, seen map[structPair]bool) bool {
 	switch u := u.(type) {
 	case *Array:
-		return t.elem.Equal(u.elem) && t.n == u.n
+		return equal(t.elem, u.elem, seen) && t.n == u.n
 	}
 	return false
 }
@@ -292,8 +361,6 @@ type Struct struct {
 	packed bool
 }
 
-// TODO: Implement support for named structures with recursive references.
-//
 // Notes from http://blog.llvm.org/2011/11/llvm-30-type-system-rewrite.html:
 //
 //    Basically, instead of creating an opaque type and replacing it later, you
@@ -339,7 +406,7 @@ func NewStruct(fields []Type, packed bool) (*Struct, error) {
 	// Validate field types (any type except void, label, metadata and function).
 	for _, field := range fields {
 		switch field.(type) {
-		case *Int, *Float, *MMX, *Pointer, *Vector, *Array, *Struct:
+		case *Int, *Float, *MMX, *Pointer, *Vector, *Array, *Struct, *IdentifiedStruct:
 			// valid type
 		case *Void:
 			return nil, errors.New("invalid structure field type; void type only allowed for function results")
@@ -356,6 +423,15 @@ func (t *Struct) Fields() []Type {
 	return t.fields
 }
 
+// FieldAt returns the field type at the given index of the structure, or an
+// error if the index is out of range.
+func (t *Struct) FieldAt(index int) (Type, error) {
+	if index < 0 || index >= len(t.fields) {
+		return nil, fmt.Errorf("index (%d) out of range for %q", index, t)
+	}
+	return t.fields[index], nil
+}
+
 // IsPacked returns true if the structure is 1 byte aligned.
 func (t *Struct) IsPacked() bool {
 	return t.packed
@@ -363,13 +439,19 @@ func (t *Struct) IsPacked() bool {
 
 // Equal returns true if the given types are equal, and false otherwise.
 func (t *Struct) Equal(u Type) bool {
+	return Equal(t, u)
+}
+
+// equal returns true if the given types are equal, and false otherwise. The
+// pairs of identified structures already being compared are tracked by seen.
+func (t *Struct) equal(u Type, seen map[structPair]bool) bool {
 	switch u := u.(type) {
 	case *Struct:
 		if len(t.fields) != len(u.fields) {
 			return false
 		}
 		for i := range t.fields {
-			if !t.fields[i].Equal(u.fields[i]) {
+			if !equal(t.fields[i], u.fields[i], seen) {
 				return false
 			}
 		}
@@ -395,3 +477,121 @@ func (t *Struct) String() string {
 	}
 	return fmt.Sprintf("{%s}", buf)
 }
+
+// IdentifiedStruct represents an identified structure type, which is referred
+// to by name. The body of an identified structure may be set after its
+// creation, which allows for recursive references to the structure itself.
+// Until then, the structure is opaque.
+//
+// Examples:
+//    %node = type {i32, %node*}   ; Type definition of a linked list node.
+//    %handle = type opaque        ; Opaque structure with unknown body.
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#structure-type
+type IdentifiedStruct struct {
+	// Structure name.
+	Name string
+	// Structure body; or nil if opaque.
+	body *Struct
+}
+
+// NewIdentifiedStruct returns an opaque identified structure type of the given
+// name. The body of the structure is set using SetBody.
+func NewIdentifiedStruct(name string) *IdentifiedStruct {
+	return &IdentifiedStruct{Name: name}
+}
+
+// SetBody sets the body of the identified structure to the given field types.
+// The structure is 1 byte aligned if packed is true.
+func (t *IdentifiedStruct) SetBody(fields []Type, packed bool) error {
+	body, err := NewStruct(fields, packed)
+	if err != nil {
+		return err
+	}
+	t.body = body
+	return nil
+}
+
+// Body returns the body of the identified structure; or nil if opaque.
+func (t *IdentifiedStruct) Body() *Struct {
+	return t.body
+}
+
+// IsOpaque returns true if the body of the identified structure has not been
+// set, and false otherwise.
+func (t *IdentifiedStruct) IsOpaque() bool {
+	return t.body == nil
+}
+
+// Fields returns the field types of the structure.
+func (t *IdentifiedStruct) Fields() []Type {
+	if t.body == nil {
+		return nil
+	}
+	return t.body.Fields()
+}
+
+// FieldAt returns the field type at the given index of the structure, or an
+// error if the index is out of range.
+func (t *IdentifiedStruct) FieldAt(index int) (Type, error) {
+	if index < 0 || index >= len(t.Fields()) {
+		return nil, fmt.Errorf("index (%d) out of range for %q", index, t)
+	}
+	return t.body.fields[index], nil
+}
+
+// IsPacked returns true if the structure is 1 byte aligned.
+func (t *IdentifiedStruct) IsPacked() bool {
+	return t.body != nil && t.body.IsPacked()
+}
+
+// Equal returns true if the given types are equal, and false otherwise.
+// Identified structures are equal if their bodies are structurally equal,
+// regardless of their names. Opaque structures are only equal to themselves.
+func (t *IdentifiedStruct) Equal(u Type) bool {
+	return Equal(t, u)
+}
+
+// equal returns true if the given types are equal, and false otherwise. The
+// pairs of identified structures already being compared are tracked by seen.
+func (t *IdentifiedStruct) equal(u Type, seen map[structPair]bool) bool {
+	v, ok := u.(*IdentifiedStruct)
+	if !ok {
+		return false
+	}
+	if t == v {
+		return true
+	}
+	if t.IsOpaque() || v.IsOpaque() {
+		return false
+	}
+	// Assume that the structures are equal while comparing their bodies, to
+	// terminate on recursive references.
+	pair := structPair{t: t, u: v}
+	if seen[pair] {
+		return true
+	}
+	seen[pair] = true
+	return t.body.equal(v.body, seen)
+}
+
+// String returns a string representation of a reference to the identified
+// structure type, e.g.
+//
+//    %node
+func (t *IdentifiedStruct) String() string {
+	return "%" + t.Name
+}
+
+// Def returns the string representation of the body of the identified structure
+// type, as used in type definitions, e.g.
+//
+//    {i32, %node*}
+//    opaque
+func (t *IdentifiedStruct) Def() string {
+	if t.IsOpaque() {
+		return "opaque"
+	}
+	return t.body.String()
+}
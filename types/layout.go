@@ -0,0 +1,75 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A DataLayout describes how data is laid out in memory on a given target.
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#data-layout
+type DataLayout struct {
+	// BigEndian specifies whether data is laid out in big-endian form; or
+	// little-endian form if false.
+	BigEndian bool
+	// Size in bits of pointers in the default address space.
+	PointerSize int
+	// ABI alignment in bits of pointers in the default address space.
+	PointerAlign int
+}
+
+// ParseDataLayout parses the given data layout string, which is a list of
+// specifications separated by the minus sign character (-). Specifications not
+// mentioned in layout default to little-endian form and 64-bit pointers, e.g.
+//
+//    e-m:e-p:64:64-i64:64-f80:128-n8:16:32:64-S128
+func ParseDataLayout(layout string) (*DataLayout, error) {
+	l := &DataLayout{PointerSize: 64, PointerAlign: 64}
+	if len(layout) == 0 {
+		return l, nil
+	}
+	for _, spec := range strings.Split(layout, "-") {
+		switch {
+		case spec == "e":
+			l.BigEndian = false
+		case spec == "E":
+			l.BigEndian = true
+		case strings.HasPrefix(spec, "p"):
+			// p[n]:<size>:<abi>[:<pref>]
+			parts := strings.Split(spec, ":")
+			if len(parts) < 3 {
+				return nil, fmt.Errorf("invalid data layout specification %q; expected p[n]:<size>:<abi>", spec)
+			}
+			space := 0
+			if len(parts[0]) > 1 {
+				n, err := strconv.Atoi(parts[0][1:])
+				if err != nil {
+					return nil, fmt.Errorf("invalid data layout specification %q; %v", spec, err)
+				}
+				space = n
+			}
+			size, err := strconv.Atoi(parts[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid data layout specification %q; %v", spec, err)
+			}
+			align, err := strconv.Atoi(parts[2])
+			if err != nil {
+				return nil, fmt.Errorf("invalid data layout specification %q; %v", spec, err)
+			}
+			if space != 0 {
+				// Only the default address space is tracked.
+				continue
+			}
+			l.PointerSize, l.PointerAlign = size, align
+		case len(spec) == 0:
+			return nil, fmt.Errorf("invalid data layout %q; empty specification", layout)
+		default:
+			// TODO: Parse integer, vector, floating point and aggregate
+			// alignments, native integer widths, stack alignment and name
+			// mangling.
+		}
+	}
+	return l, nil
+}
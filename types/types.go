@@ -1,6 +1,3 @@
-// TODO: Add support for named types. Implement type equality checks and make
-// sure to consider the impact of named types.
-
 // Package types declares the data types of LLVM IR.
 package types
 
@@ -20,6 +17,7 @@ import "fmt"
 //    *types.Vector
 //    *types.Array
 //    *types.Struct
+//    *types.IdentifiedStruct
 //
 // References:
 //    http://llvm.org/docs/LangRef.html#typesystem
@@ -29,9 +27,28 @@ type Type interface {
 	Equal(b Type) bool
 }
 
-// Equal returns true if the given types are equal, and false otherwise.
+// Equal returns true if the given types are equal, and false otherwise. Types
+// are compared structurally, and recursive references of identified structures
+// are handled by tracking the pairs of identified structures being compared.
 func Equal(t, u Type) bool {
-	// TODO: Implement type equality checks for named types.
+	return equal(t, u, make(map[structPair]bool))
+}
+
+// A structPair is a pair of identified structures being compared for equality.
+type structPair struct {
+	t, u *IdentifiedStruct
+}
+
+// equal returns true if the given types are equal, and false otherwise. The
+// pairs of identified structures already being compared are tracked by seen.
+func equal(t, u Type, seen map[structPair]bool) bool {
+	// Derived types may contain identified structures.
+	type equaler interface {
+		equal(u Type, seen map[structPair]bool) bool
+	}
+	if t, ok := t.(equaler); ok {
+		return t.equal(u, seen)
+	}
 	return t.Equal(u)
 }
 
@@ -65,6 +82,21 @@ func IsFloats(t Type) bool {
 	return IsFloat(t)
 }
 
+// IsPointer returns true if t is a pointer type, and false otherwise.
+func IsPointer(t Type) bool {
+	_, ok := t.(*Pointer)
+	return ok
+}
+
+// IsPointers returns true if t is a pointer type or a vector of pointers type,
+// and false otherwise.
+func IsPointers(t Type) bool {
+	if t, ok := t.(*Vector); ok {
+		return IsPointer(t.Elem())
+	}
+	return IsPointer(t)
+}
+
 // SameLength returns true if both types are vectors or arrays of the same
 // length or if both types are distinct from vectors and arrays, and false
 // otherwise.
@@ -83,3 +115,61 @@ func SameLength(a, b Type) bool {
 	// Verify that both types are distinct from vectors and arrays.
 	return !ok1 && !ok2
 }
+
+// NaturalAlign returns the natural alignment in bytes of values of type t, based
+// on the default data layout of a 64-bit target. The boolean return value is
+// false for unsized types (e.g. void, label and function types). Explicit
+// alignments equal to the natural alignment may be omitted from the LLVM IR
+// assembly.
+func NaturalAlign(t Type) (int, bool) {
+	switch t := t.(type) {
+	case *Int:
+		return pow2Bytes(t.Size()), true
+	case *Float:
+		return pow2Bytes(t.Size()), true
+	case *MMX:
+		return 8, true
+	case *Pointer:
+		return 8, true
+	case *Vector:
+		size, ok := t.Size()
+		if !ok {
+			// Vector of pointers.
+			size = 64 * t.Len()
+		}
+		return pow2Bytes(size), true
+	case *Array:
+		return NaturalAlign(t.Elem())
+	case *IdentifiedStruct:
+		if t.IsOpaque() {
+			return 0, false
+		}
+		return NaturalAlign(t.Body())
+	case *Struct:
+		if t.IsPacked() {
+			return 1, true
+		}
+		align := 1
+		for _, field := range t.Fields() {
+			a, ok := NaturalAlign(field)
+			if !ok {
+				return 0, false
+			}
+			if a > align {
+				align = a
+			}
+		}
+		return align, true
+	}
+	return 0, false
+}
+
+// pow2Bytes returns the smallest power of two number of bytes which holds the
+// given number of bits.
+func pow2Bytes(bits int) int {
+	n := 1
+	for n*8 < bits {
+		n *= 2
+	}
+	return n
+}
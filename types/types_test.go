@@ -356,6 +356,27 @@ func TestVoidString(t *testing.T) {
 	}
 }
 
+func TestVoidLabelShared(t *testing.T) {
+	if types.NewVoid() != types.NewVoid() {
+		t.Errorf("expected void types to be shared")
+	}
+	if types.NewLabel() != types.NewLabel() {
+		t.Errorf("expected label types to be shared")
+	}
+	if !voidTyp.Equal(types.NewVoid()) {
+		t.Errorf("expected %v to be equal to %v", voidTyp, types.NewVoid())
+	}
+	if !labelTyp.Equal(types.NewLabel()) {
+		t.Errorf("expected %v to be equal to %v", labelTyp, types.NewLabel())
+	}
+	if voidTyp.Equal(labelTyp) {
+		t.Errorf("expected %v to differ from %v", voidTyp, labelTyp)
+	}
+	if labelTyp.Equal(voidTyp) {
+		t.Errorf("expected %v to differ from %v", labelTyp, voidTyp)
+	}
+}
+
 func TestIntString(t *testing.T) {
 	golden := []struct {
 		n    int
@@ -410,6 +431,39 @@ func TestIntString(t *testing.T) {
 	}
 }
 
+func TestIntPredefined(t *testing.T) {
+	golden := []struct {
+		typ  *types.Int
+		n    int
+		want string
+	}{
+		{typ: types.I1, n: 1, want: "i1"},       // i=0
+		{typ: types.I8, n: 8, want: "i8"},       // i=1
+		{typ: types.I16, n: 16, want: "i16"},    // i=2
+		{typ: types.I32, n: 32, want: "i32"},    // i=3
+		{typ: types.I64, n: 64, want: "i64"},    // i=4
+		{typ: types.I128, n: 128, want: "i128"}, // i=5
+	}
+
+	for i, g := range golden {
+		typ, err := types.NewInt(g.n)
+		if err != nil {
+			t.Errorf("i=%d: unexpected error; %v", i, err)
+			continue
+		}
+		if !g.typ.Equal(typ) {
+			t.Errorf("i=%d: expected %v to be equal to %v", i, g.typ, typ)
+		}
+		got := g.typ.String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %v, got %v", i, g.want, got)
+		}
+	}
+	if types.I32.Equal(types.I64) {
+		t.Errorf("expected %v to differ from %v", types.I32, types.I64)
+	}
+}
+
 func TestFloatSize(t *testing.T) {
 	golden := []struct {
 		kind types.FloatKind
@@ -519,6 +573,43 @@ func TestFloatString(t *testing.T) {
 	}
 }
 
+func TestFloatPredefined(t *testing.T) {
+	golden := []struct {
+		typ  *types.Float
+		kind types.FloatKind
+		size int
+		want string
+	}{
+		{typ: types.F16, kind: types.Float16, size: 16, want: "half"},                 // i=0
+		{typ: types.F32, kind: types.Float32, size: 32, want: "float"},                // i=1
+		{typ: types.F64, kind: types.Float64, size: 64, want: "double"},               // i=2
+		{typ: types.F128, kind: types.Float128, size: 128, want: "fp128"},             // i=3
+		{typ: types.F80_x86, kind: types.Float80_x86, size: 80, want: "x86_fp80"},     // i=4
+		{typ: types.F128_PPC, kind: types.Float128_PPC, size: 128, want: "ppc_fp128"}, // i=5
+	}
+
+	for i, g := range golden {
+		typ, err := types.NewFloat(g.kind)
+		if err != nil {
+			t.Errorf("i=%d: unexpected error; %v", i, err)
+			continue
+		}
+		if !g.typ.Equal(typ) {
+			t.Errorf("i=%d: expected %v to be equal to %v", i, g.typ, typ)
+		}
+		if size := g.typ.Size(); size != g.size {
+			t.Errorf("i=%d: size mismatch; expected %d, got %d", i, g.size, size)
+		}
+		got := g.typ.String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %v, got %v", i, g.want, got)
+		}
+	}
+	if types.F128.Equal(types.F128_PPC) {
+		t.Errorf("expected %v to differ from %v", types.F128, types.F128_PPC)
+	}
+}
+
 func TestMMXString(t *testing.T) {
 	const want = "x86_mmx"
 	typ := types.NewMMX()
@@ -607,6 +698,48 @@ func TestFuncString(t *testing.T) {
 	}
 }
 
+func TestFuncEqual(t *testing.T) {
+	// i32 (i8*, ...)
+	printf, err := types.NewFunc(i32Typ, []types.Type{i8PtrTyp}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// i32 (i8*, ...)
+	same, err := types.NewFunc(i32Typ, []types.Type{i8PtrTyp}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// i32 (i8*)
+	fixed, err := types.NewFunc(i32Typ, []types.Type{i8PtrTyp}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// void (...)
+	ellipsis, err := types.NewFunc(voidTyp, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden := []struct {
+		want bool
+		a, b *types.Func
+	}{
+		{want: true, a: printf, b: same},      // i=0
+		{want: false, a: printf, b: fixed},    // i=1
+		{want: false, a: printf, b: ellipsis}, // i=2
+	}
+
+	for i, g := range golden {
+		got := g.a.Equal(g.b)
+		if got != g.want {
+			t.Errorf("i=%d: expected %v and %v equality to be %v, got %v", i, g.a, g.b, g.want, got)
+		}
+	}
+	if got, want := ellipsis.String(), "void (...)"; got != want {
+		t.Errorf("string mismatch; expected %v, got %v", want, got)
+	}
+}
+
 func TestPointerString(t *testing.T) {
 	golden := []struct {
 		elem types.Type
@@ -661,6 +794,80 @@ func TestPointerString(t *testing.T) {
 	}
 }
 
+func TestPointerInAddrSpaceString(t *testing.T) {
+	golden := []struct {
+		elem  types.Type
+		space int
+		want  string
+		err   string
+	}{
+		// i=0
+		{
+			elem: i8Typ, space: 0,
+			want: "i8*",
+		},
+		// i=1
+		{
+			elem: i8Typ, space: 1,
+			want: "i8 addrspace(1)*",
+		},
+		// i=2
+		{
+			elem: i8PtrTyp, space: 3,
+			want: "i8* addrspace(3)*",
+		},
+		// i=3
+		{
+			elem: i8Typ, space: -1,
+			want: "", err: "invalid pointer address space (-1)",
+		},
+		// i=4
+		{
+			elem: i8Typ, space: 1 << 24,
+			want: "", err: "invalid pointer address space (16777216)",
+		},
+	}
+
+	for i, g := range golden {
+		typ, err := types.NewPointerInAddrSpace(g.elem, g.space)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		if space := typ.AddrSpace(); space != g.space {
+			t.Errorf("i=%d: address space mismatch; expected %d, got %d", i, g.space, space)
+		}
+		got := typ.String()
+		if got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %v, got %v", i, g.want, got)
+		}
+	}
+}
+
+func TestPointerInAddrSpaceEqual(t *testing.T) {
+	p0, err := types.NewPointer(i8Typ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q0, err := types.NewPointerInAddrSpace(i8Typ, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p1, err := types.NewPointerInAddrSpace(i8Typ, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p0.Equal(q0) {
+		t.Errorf("expected %v to be equal to %v", p0, q0)
+	}
+	if p0.Equal(p1) {
+		t.Errorf("expected %v to differ from %v", p0, p1)
+	}
+}
+
 func TestVectorString(t *testing.T) {
 	golden := []struct {
 		elem types.Type
@@ -731,6 +938,58 @@ func TestVectorString(t *testing.T) {
 	}
 }
 
+func TestVectorSize(t *testing.T) {
+	golden := []struct {
+		typ  *types.Vector
+		want int
+		ok   bool
+	}{
+		{typ: i8x1VecTyp, want: 8, ok: true},          // i=0
+		{typ: i32x2VecTyp, want: 64, ok: true},        // i=1
+		{typ: f16x3VecTyp, want: 48, ok: true},        // i=2
+		{typ: f32x4VecTyp, want: 128, ok: true},       // i=3
+		{typ: f80_x86x7VecTyp, want: 560, ok: true},   // i=4
+		{typ: f128_ppcx8VecTyp, want: 1024, ok: true}, // i=5
+		{typ: i8Ptrx9VecTyp, want: 0, ok: false},      // i=6
+	}
+
+	for i, g := range golden {
+		got, ok := g.typ.Size()
+		if ok != g.ok {
+			t.Errorf("i=%d: expected ok=%v for %v, got ok=%v", i, g.ok, g.typ, ok)
+			continue
+		}
+		if got != g.want {
+			t.Errorf("i=%d: size mismatch; expected %d, got %d", i, g.want, got)
+		}
+	}
+}
+
+func TestVectorEqual(t *testing.T) {
+	// <4 x float>
+	typ, err := types.NewVector(f32Typ, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := typ.String(), "<4 x float>"; got != want {
+		t.Errorf("string mismatch; expected %v, got %v", want, got)
+	}
+	if !typ.Equal(f32x4VecTyp) {
+		t.Errorf("expected %v to be equal to %v", typ, f32x4VecTyp)
+	}
+	if typ.Equal(f32x4ArrTyp) {
+		t.Errorf("expected %v to differ from %v", typ, f32x4ArrTyp)
+	}
+	// <4 x i32>
+	u, err := types.NewVector(i32Typ, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typ.Equal(u) {
+		t.Errorf("expected %v to differ from %v", typ, u)
+	}
+}
+
 func TestArrayString(t *testing.T) {
 	golden := []struct {
 		elem types.Type
@@ -801,6 +1060,73 @@ func TestArrayString(t *testing.T) {
 	}
 }
 
+func TestArrayElemAt(t *testing.T) {
+	// [2 x [3 x i32]]
+	typ, err := types.NewArray(i32x3ArrTyp, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := typ.String(), "[2 x [3 x i32]]"; got != want {
+		t.Errorf("string mismatch; expected %v, got %v", want, got)
+	}
+
+	golden := []struct {
+		typ   *types.Array
+		index int
+		want  types.Type
+		err   string
+	}{
+		// i=0
+		{
+			typ: typ, index: 0,
+			want: i32x3ArrTyp,
+		},
+		// i=1
+		{
+			typ: typ, index: 1,
+			want: i32x3ArrTyp,
+		},
+		// i=2
+		{
+			typ: typ, index: 2,
+			want: nil, err: `index (2) out of range for "[2 x [3 x i32]]"`,
+		},
+		// i=3
+		{
+			typ: typ, index: -1,
+			want: nil, err: `index (-1) out of range for "[2 x [3 x i32]]"`,
+		},
+		// i=4
+		{
+			typ: i32x3ArrTyp, index: 2,
+			want: i32Typ,
+		},
+	}
+
+	for i, g := range golden {
+		got, err := g.typ.ElemAt(g.index)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		if !got.Equal(g.want) {
+			t.Errorf("i=%d: element type mismatch; expected %v, got %v", i, g.want, got)
+		}
+	}
+
+	// Nested arrays are compared recursively.
+	u, err := types.NewArray(i32x2ArrTyp, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typ.Equal(u) {
+		t.Errorf("expected %v to differ from %v", typ, u)
+	}
+}
+
 func TestStructString(t *testing.T) {
 	golden := []struct {
 		fields []types.Type
@@ -867,6 +1193,199 @@ func TestStructString(t *testing.T) {
 	}
 }
 
+func TestStructFieldAt(t *testing.T) {
+	golden := []struct {
+		typ   *types.Struct
+		index int
+		want  types.Type
+		err   string
+	}{
+		// i=0
+		{
+			typ: i32i8structTyp, index: 0,
+			want: i32Typ,
+		},
+		// i=1
+		{
+			typ: i32i8structTyp, index: 1,
+			want: i8Typ,
+		},
+		// i=2
+		{
+			typ: i32i8structTyp, index: 2,
+			want: nil, err: `index (2) out of range for "{i32, i8}"`,
+		},
+		// i=3
+		{
+			typ: i32i8structTyp, index: -1,
+			want: nil, err: `index (-1) out of range for "{i32, i8}"`,
+		},
+		// i=4
+		{
+			typ: structTyp, index: 5,
+			want: f16x3ArrTyp,
+		},
+	}
+
+	for i, g := range golden {
+		got, err := g.typ.FieldAt(g.index)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		if !got.Equal(g.want) {
+			t.Errorf("i=%d: field type mismatch; expected %v, got %v", i, g.want, got)
+		}
+	}
+}
+
+func TestStructPacked(t *testing.T) {
+	fields := []types.Type{i8Typ, i32Typ}
+	// {i8, i32}
+	typ, err := types.NewStruct(fields, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// <{i8, i32}>
+	packed, err := types.NewStruct(fields, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := typ.String(), "{i8, i32}"; got != want {
+		t.Errorf("string mismatch; expected %v, got %v", want, got)
+	}
+	if got, want := packed.String(), "<{i8, i32}>"; got != want {
+		t.Errorf("string mismatch; expected %v, got %v", want, got)
+	}
+	if typ.Equal(packed) {
+		t.Errorf("expected %v to differ from %v", typ, packed)
+	}
+}
+
+func TestIdentifiedStruct(t *testing.T) {
+	// %node = type {i32, %node*}
+	node := types.NewIdentifiedStruct("node")
+	nodePtr, err := types.NewPointer(node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := node.SetBody([]types.Type{i32Typ, nodePtr}, false); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := node.String(), "%node"; got != want {
+		t.Errorf("string mismatch; expected %q, got %q", want, got)
+	}
+	if got, want := node.Def(), "{i32, %node*}"; got != want {
+		t.Errorf("definition mismatch; expected %q, got %q", want, got)
+	}
+	next, err := node.FieldAt(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !next.Equal(nodePtr) {
+		t.Errorf("field type mismatch; expected %v, got %v", nodePtr, next)
+	}
+	const want = `index (2) out of range for "%node"`
+	if _, err := node.FieldAt(2); !sameError(err, want) {
+		t.Errorf("error mismatch; expected %v, got %v", want, err)
+	}
+
+	// Identified structures differ from literal structures.
+	other := types.NewIdentifiedStruct("node")
+	if !node.Equal(node) {
+		t.Errorf("expected %v to equal itself", node)
+	}
+	if node.Equal(node.Body()) {
+		t.Errorf("expected %v to differ from %v", node, node.Body())
+	}
+
+	const wantBody = "invalid structure field type; void type only allowed for function results"
+	if err := other.SetBody([]types.Type{voidTyp}, false); !sameError(err, wantBody) {
+		t.Errorf("error mismatch; expected %v, got %v", wantBody, err)
+	}
+}
+
+func TestOpaqueStruct(t *testing.T) {
+	// %handle = type opaque
+	handle := types.NewIdentifiedStruct("handle")
+	if !handle.IsOpaque() {
+		t.Errorf("expected %v to be opaque", handle)
+	}
+	if got, want := handle.String(), "%handle"; got != want {
+		t.Errorf("string mismatch; expected %q, got %q", want, got)
+	}
+	if got, want := handle.Def(), "opaque"; got != want {
+		t.Errorf("definition mismatch; expected %q, got %q", want, got)
+	}
+	if _, ok := types.NaturalAlign(handle); ok {
+		t.Errorf("expected opaque structure %v to be unsized", handle)
+	}
+
+	// %handle = type {i32, i8*}
+	if err := handle.SetBody([]types.Type{i32Typ, i8PtrTyp}, false); err != nil {
+		t.Fatal(err)
+	}
+	if handle.IsOpaque() {
+		t.Errorf("expected %v to not be opaque", handle)
+	}
+	if got, want := handle.Def(), "{i32, i8*}"; got != want {
+		t.Errorf("definition mismatch; expected %q, got %q", want, got)
+	}
+}
+
+func TestIdentifiedStructEqual(t *testing.T) {
+	// list returns a self-referential list type of the given name and element
+	// type.
+	list := func(name string, elem types.Type) *types.IdentifiedStruct {
+		typ := types.NewIdentifiedStruct(name)
+		next, err := types.NewPointer(typ)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := typ.SetBody([]types.Type{elem, next}, false); err != nil {
+			t.Fatal(err)
+		}
+		return typ
+	}
+	// %list = type {i32, %list*}
+	l1 := list("list", i32Typ)
+	// %list2 = type {i32, %list2*}
+	l2 := list("list2", i32Typ)
+	// %list3 = type {i64, %list3*}
+	l3 := list("list3", i64Typ)
+	l1Ptr, err := types.NewPointer(l1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l2Ptr, err := types.NewPointer(l2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := []struct {
+		a, b types.Type
+		want bool
+	}{
+		{a: l1, b: l2, want: true},
+		{a: l2, b: l1, want: true},
+		{a: l1Ptr, b: l2Ptr, want: true},
+		{a: l1, b: l3, want: false},
+		{a: l1, b: types.NewIdentifiedStruct("opaque"), want: false},
+		{a: types.NewIdentifiedStruct("a"), b: types.NewIdentifiedStruct("a"), want: false},
+	}
+
+	for i, g := range golden {
+		if got := types.Equal(g.a, g.b); got != g.want {
+			t.Errorf("i=%d: expected %v, got %v", i, g.want, got)
+		}
+		if got := g.a.Equal(g.b); got != g.want {
+			t.Errorf("i=%d: expected %v, got %v", i, g.want, got)
+		}
+	}
+}
+
 func TestEqual(t *testing.T) {
 	golden := []struct {
 		want bool
@@ -1190,6 +1709,172 @@ func TestIsFloats(t *testing.T) {
 	}
 }
 
+func TestIsPointer(t *testing.T) {
+	golden := []struct {
+		want bool
+		typ  types.Type
+	}{
+		{want: false, typ: voidTyp},          // void
+		{want: false, typ: i1Typ},            // i1
+		{want: false, typ: i8Typ},            // i8
+		{want: false, typ: i32Typ},           // i32
+		{want: false, typ: f16Typ},           // half
+		{want: false, typ: f32Typ},           // float
+		{want: false, typ: f64Typ},           // double
+		{want: false, typ: f128Typ},          // fp128
+		{want: false, typ: f80_x86Typ},       // x86_fp80
+		{want: false, typ: f128_ppcTyp},      // ppc_fp128
+		{want: false, typ: mmxTyp},           // x86_mmx
+		{want: false, typ: labelTyp},         // label
+		{want: false, typ: metadataTyp},      // metadata
+		{want: false, typ: funcTyp},          // i32 (i32)
+		{want: true, typ: i8PtrTyp},          // i8*
+		{want: true, typ: f16PtrTyp},         // half*
+		{want: true, typ: mmxPtrTyp},         // x86_mmx*
+		{want: true, typ: funcPtrTyp},        // i32 (i32)*
+		{want: false, typ: i8x1VecTyp},       // <1 x i8>
+		{want: false, typ: i32x2VecTyp},      // <2 x i32>
+		{want: false, typ: f16x3VecTyp},      // <3 x half>
+		{want: false, typ: f32x4VecTyp},      // <4 x float>
+		{want: false, typ: f64x5VecTyp},      // <5 x double>
+		{want: false, typ: f128x6VecTyp},     // <6 x fp128>
+		{want: false, typ: f80_x86x7VecTyp},  // <7 x x86_fp80>
+		{want: false, typ: f128_ppcx8VecTyp}, // <8 x ppc_fp128>
+		{want: false, typ: i8Ptrx9VecTyp},    // <9 x i8*>
+		{want: false, typ: f16Ptrx10VecTyp},  // <10 x half*>
+		{want: false, typ: i8x1ArrTyp},       // [1 x i8]
+		{want: false, typ: i32x2ArrTyp},      // [2 x i32]
+		{want: false, typ: f16x3ArrTyp},      // [3 x half]
+		{want: false, typ: f32x4ArrTyp},      // [4 x float]
+		{want: false, typ: f64x5ArrTyp},      // [5 x double]
+		{want: false, typ: f128x6ArrTyp},     // [6 x fp128]
+		{want: false, typ: f80_x86x7ArrTyp},  // [7 x x86_fp80]
+		{want: false, typ: f128_ppcx8ArrTyp}, // [8 x ppc_fp128]
+		{want: false, typ: i8Ptrx9ArrTyp},    // [9 x i8*]
+		{want: false, typ: f16Ptrx10ArrTyp},  // [10 x half*]
+		{want: false, typ: structTyp},        // {i1, float, x86_mmx, i32 (i32)*, [1 x i8], <3 x half>}
+	}
+
+	for i, g := range golden {
+		got := types.IsPointer(g.typ)
+		if got != g.want {
+			t.Errorf("i=%d: expected %v, got %v for type %q", i, g.want, got, g.typ)
+		}
+	}
+}
+
+func TestIsPointers(t *testing.T) {
+	golden := []struct {
+		want bool
+		typ  types.Type
+	}{
+		{want: false, typ: voidTyp},          // void
+		{want: false, typ: i1Typ},            // i1
+		{want: false, typ: i8Typ},            // i8
+		{want: false, typ: i32Typ},           // i32
+		{want: false, typ: f16Typ},           // half
+		{want: false, typ: f32Typ},           // float
+		{want: false, typ: f64Typ},           // double
+		{want: false, typ: f128Typ},          // fp128
+		{want: false, typ: f80_x86Typ},       // x86_fp80
+		{want: false, typ: f128_ppcTyp},      // ppc_fp128
+		{want: false, typ: mmxTyp},           // x86_mmx
+		{want: false, typ: labelTyp},         // label
+		{want: false, typ: metadataTyp},      // metadata
+		{want: false, typ: funcTyp},          // i32 (i32)
+		{want: true, typ: i8PtrTyp},          // i8*
+		{want: true, typ: f16PtrTyp},         // half*
+		{want: true, typ: mmxPtrTyp},         // x86_mmx*
+		{want: true, typ: funcPtrTyp},        // i32 (i32)*
+		{want: false, typ: i8x1VecTyp},       // <1 x i8>
+		{want: false, typ: i32x2VecTyp},      // <2 x i32>
+		{want: false, typ: f16x3VecTyp},      // <3 x half>
+		{want: false, typ: f32x4VecTyp},      // <4 x float>
+		{want: false, typ: f64x5VecTyp},      // <5 x double>
+		{want: false, typ: f128x6VecTyp},     // <6 x fp128>
+		{want: false, typ: f80_x86x7VecTyp},  // <7 x x86_fp80>
+		{want: false, typ: f128_ppcx8VecTyp}, // <8 x ppc_fp128>
+		{want: true, typ: i8Ptrx9VecTyp},     // <9 x i8*>
+		{want: true, typ: f16Ptrx10VecTyp},   // <10 x half*>
+		{want: false, typ: i8x1ArrTyp},       // [1 x i8]
+		{want: false, typ: i32x2ArrTyp},      // [2 x i32]
+		{want: false, typ: f16x3ArrTyp},      // [3 x half]
+		{want: false, typ: f32x4ArrTyp},      // [4 x float]
+		{want: false, typ: f64x5ArrTyp},      // [5 x double]
+		{want: false, typ: f128x6ArrTyp},     // [6 x fp128]
+		{want: false, typ: f80_x86x7ArrTyp},  // [7 x x86_fp80]
+		{want: false, typ: f128_ppcx8ArrTyp}, // [8 x ppc_fp128]
+		{want: false, typ: i8Ptrx9ArrTyp},    // [9 x i8*]
+		{want: false, typ: f16Ptrx10ArrTyp},  // [10 x half*]
+		{want: false, typ: structTyp},        // {i1, float, x86_mmx, i32 (i32)*, [1 x i8], <3 x half>}
+	}
+
+	for i, g := range golden {
+		got := types.IsPointers(g.typ)
+		if got != g.want {
+			t.Errorf("i=%d: expected %v, got %v for type %q", i, g.want, got, g.typ)
+		}
+	}
+}
+
+func TestParseDataLayout(t *testing.T) {
+	golden := []struct {
+		layout string
+		want   types.DataLayout
+		err    string
+	}{
+		// i=0
+		{
+			layout: "e-m:e-p:64:64",
+			want:   types.DataLayout{BigEndian: false, PointerSize: 64, PointerAlign: 64},
+		},
+		// i=1
+		{
+			layout: "E-p:32:32-i64:64",
+			want:   types.DataLayout{BigEndian: true, PointerSize: 32, PointerAlign: 32},
+		},
+		// i=2
+		{
+			layout: "e-p1:16:16-p:32:64",
+			want:   types.DataLayout{BigEndian: false, PointerSize: 32, PointerAlign: 64},
+		},
+		// i=3
+		{
+			layout: "",
+			want:   types.DataLayout{BigEndian: false, PointerSize: 64, PointerAlign: 64},
+		},
+		// i=4
+		{
+			layout: "e-p:64",
+			err:    `invalid data layout specification "p:64"; expected p[n]:<size>:<abi>`,
+		},
+		// i=5
+		{
+			layout: "e-p:x:64",
+			err:    `invalid data layout specification "p:x:64"; strconv.Atoi: parsing "x"`,
+		},
+		// i=6
+		{
+			layout: "e--p:64:64",
+			err:    `invalid data layout "e--p:64:64"; empty specification`,
+		},
+	}
+
+	for i, g := range golden {
+		got, err := types.ParseDataLayout(g.layout)
+		if !sameError(err, g.err) {
+			t.Errorf("i=%d: error mismatch; expected %v, got %v", i, g.err, err)
+			continue
+		} else if err != nil {
+			// Expected error match, check next test case.
+			continue
+		}
+		if *got != g.want {
+			t.Errorf("i=%d: data layout mismatch; expected %+v, got %+v", i, g.want, *got)
+		}
+	}
+}
+
 func TestSameLength(t *testing.T) {
 	golden := []struct {
 		a, b types.Type
@@ -1218,6 +1903,40 @@ func TestSameLength(t *testing.T) {
 	}
 }
 
+func TestNaturalAlign(t *testing.T) {
+	packedTyp, err := types.NewStruct([]types.Type{i32Typ, i8Typ}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := []struct {
+		typ  types.Type
+		want int
+		ok   bool
+	}{
+		{typ: i1Typ, want: 1, ok: true},
+		{typ: i32Typ, want: 4, ok: true},
+		{typ: i64Typ, want: 8, ok: true},
+		{typ: f80_x86Typ, want: 16, ok: true},
+		{typ: mmxTyp, want: 8, ok: true},
+		{typ: i8PtrTyp, want: 8, ok: true},
+		{typ: i32x3VecTyp, want: 16, ok: true},
+		{typ: i8Ptrx9VecTyp, want: 128, ok: true},
+		{typ: f64x5ArrTyp, want: 8, ok: true},
+		{typ: i32i8structTyp, want: 4, ok: true},
+		{typ: packedTyp, want: 1, ok: true},
+		{typ: voidTyp, want: 0, ok: false},
+		{typ: labelTyp, want: 0, ok: false},
+		{typ: funcTyp, want: 0, ok: false},
+	}
+
+	for i, g := range golden {
+		got, ok := types.NaturalAlign(g.typ)
+		if got != g.want || ok != g.ok {
+			t.Errorf("i=%d: expected %d (%v), got %d (%v)", i, g.want, g.ok, got, ok)
+		}
+	}
+}
+
 // sameError returns true if err is represented by the string s, and false
 // otherwise. Some error messages contains suffixes from external functions,
 // e.g. the strconv error in:
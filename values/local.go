@@ -0,0 +1,58 @@
+package values
+
+import (
+	"fmt"
+
+	"github.com/llir/llvm/types"
+)
+
+// A Local represents a reference to a local variable, such as the result of a
+// previous instruction within the same function.
+//
+// Examples:
+//    %tmp
+//    %1
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#identifiers
+type Local struct {
+	// Local variable name.
+	Name string
+	// Local variable type.
+	typ types.Type
+}
+
+// NewLocal returns a reference to the local variable of the given name and
+// type.
+func NewLocal(name string, typ types.Type) *Local {
+	return &Local{Name: name, typ: typ}
+}
+
+// Type returns the type of the value.
+func (l *Local) Type() types.Type {
+	return l.typ
+}
+
+// Ident returns the identifier associated with the local variable, e.g.
+//
+//    %tmp
+func (l *Local) Ident() string {
+	return "%" + l.Name
+}
+
+// String returns a string representation of the local variable reference. The
+// local variable name is preceded by its type, e.g.
+//
+//    i32 %tmp
+func (l *Local) String() string {
+	return fmt.Sprintf("%v %s", l.Type(), l.Ident())
+}
+
+// Equal returns true if u is a reference to the same local variable, i.e. of the
+// same name and type, and false otherwise.
+func (l *Local) Equal(u Value) bool {
+	if u, ok := u.(*Local); ok {
+		return l.Name == u.Name && l.typ.Equal(u.typ)
+	}
+	return false
+}
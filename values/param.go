@@ -0,0 +1,53 @@
+package values
+
+import (
+	"fmt"
+
+	"github.com/llir/llvm/types"
+)
+
+// A Param represents a function parameter, which may be referenced by the
+// instructions of the function body.
+//
+// References:
+//    http://llvm.org/docs/LangRef.html#functions
+type Param struct {
+	// Parameter name.
+	Name string
+	// Parameter type.
+	typ types.Type
+}
+
+// NewParam returns a function parameter of the given name and type.
+func NewParam(name string, typ types.Type) *Param {
+	return &Param{Name: name, typ: typ}
+}
+
+// Type returns the type of the value.
+func (p *Param) Type() types.Type {
+	return p.typ
+}
+
+// Ident returns the identifier associated with the function parameter, e.g.
+//
+//    %x
+func (p *Param) Ident() string {
+	return "%" + p.Name
+}
+
+// String returns a string representation of the function parameter. The
+// parameter name is preceded by its type, e.g.
+//
+//    i32 %x
+func (p *Param) String() string {
+	return fmt.Sprintf("%v %s", p.Type(), p.Ident())
+}
+
+// Equal returns true if u is a reference to the same function parameter, i.e. of the
+// same name and type, and false otherwise.
+func (p *Param) Equal(u Value) bool {
+	if u, ok := u.(*Param); ok {
+		return p.Name == u.Name && p.typ.Equal(u.typ)
+	}
+	return false
+}
@@ -16,10 +16,32 @@ import (
 // Value is one of the following types:
 //
 //    *ir.BasicBlock
+//    *ir.BlockAddress
+//    *ir.Function
+//    *ir.Global
+//    *Local
+//    *Param
 //    ir.Instruction
 //    ir.Terminator
 type Value interface {
 	fmt.Stringer
 	// Type returns the type of the value.
 	Type() types.Type
+	// Ident returns the identifier associated with the value (e.g. %x, @f or
+	// 42), which is used when the value is an operand of another value.
+	Ident() string
+}
+
+// Equal returns true if the given values are equal, and false otherwise.
+// Constants, local variable references and function parameters are compared
+// structurally through their Equal method, while other values (e.g.
+// instructions, functions and global variables) are compared by identity.
+func Equal(a, b Value) bool {
+	type Equaler interface {
+		Equal(u Value) bool
+	}
+	if a, ok := a.(Equaler); ok {
+		return a.Equal(b)
+	}
+	return a == b
 }
@@ -0,0 +1,90 @@
+package values_test
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/types"
+	"github.com/llir/llvm/values"
+)
+
+func TestParam(t *testing.T) {
+	i8PtrTyp, err := types.NewPointer(types.I8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden := []struct {
+		name  string
+		typ   types.Type
+		ident string
+		want  string
+	}{
+		// i=0
+		{
+			name: "x", typ: types.I32,
+			ident: "%x", want: "i32 %x",
+		},
+		// i=1
+		{
+			name: "format", typ: i8PtrTyp,
+			ident: "%format", want: "i8* %format",
+		},
+		// i=2
+		{
+			name: "0", typ: types.F64,
+			ident: "%0", want: "double %0",
+		},
+	}
+
+	for i, g := range golden {
+		p := values.NewParam(g.name, g.typ)
+		if !p.Type().Equal(g.typ) {
+			t.Errorf("i=%d: type mismatch; expected %v, got %v", i, g.typ, p.Type())
+		}
+		// Reference form used by operands.
+		if got := p.Ident(); got != g.ident {
+			t.Errorf("i=%d: ident mismatch; expected %q, got %q", i, g.ident, got)
+		}
+		// Declaration form used by function signatures.
+		if got := p.String(); got != g.want {
+			t.Errorf("i=%d: string mismatch; expected %q, got %q", i, g.want, got)
+		}
+	}
+}
+
+func TestLocal(t *testing.T) {
+	l := values.NewLocal("tmp", types.I32)
+	if !l.Type().Equal(types.I32) {
+		t.Errorf("type mismatch; expected %v, got %v", types.I32, l.Type())
+	}
+	if got, want := l.Ident(), "%tmp"; got != want {
+		t.Errorf("ident mismatch; expected %q, got %q", want, got)
+	}
+	if got, want := l.String(), "i32 %tmp"; got != want {
+		t.Errorf("string mismatch; expected %q, got %q", want, got)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	golden := []struct {
+		a, b values.Value
+		want bool
+	}{
+		// i=0
+		{a: values.NewLocal("x", types.I32), b: values.NewLocal("x", types.I32), want: true},
+		// i=1
+		{a: values.NewLocal("x", types.I32), b: values.NewLocal("y", types.I32), want: false},
+		// i=2
+		{a: values.NewLocal("x", types.I32), b: values.NewLocal("x", types.I64), want: false},
+		// i=3
+		{a: values.NewParam("x", types.I32), b: values.NewParam("x", types.I32), want: true},
+		// i=4
+		{a: values.NewParam("x", types.I32), b: values.NewLocal("x", types.I32), want: false},
+	}
+
+	for i, g := range golden {
+		if got := values.Equal(g.a, g.b); got != g.want {
+			t.Errorf("i=%d: expected %v, got %v", i, g.want, got)
+		}
+	}
+}